@@ -0,0 +1,368 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/go-mosaic/runtime/log"
+)
+
+// ErrOpen вызов отклонен, так как цепь разомкнута
+var ErrOpen = errors.New("breaker: circuit is open")
+
+// errPanic результат вызова, завершившегося паникой; всегда считается отказом
+var errPanic = errors.New("breaker: call panicked")
+
+// State состояние circuit breaker
+type State int
+
+const (
+	// StateClosed вызовы выполняются, ошибки учитываются
+	StateClosed State = iota
+	// StateOpen вызовы отклоняются до истечения таймаута
+	StateOpen
+	// StateHalfOpen выполняется ограниченное число пробных вызовов
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// StateChangeFunc вызывается при смене состояния breaker с именем name
+type StateChangeFunc func(name string, from, to State)
+
+// Config конфигурация circuit breaker
+type Config struct {
+	name                string
+	consecutiveFailures int
+	errorRate           float64
+	minRequests         int
+	window              time.Duration
+	openTimeout         time.Duration
+	halfOpenRequests    int
+	isFailure           func(err error) bool
+	onStateChange       StateChangeFunc
+	logger              log.Logger
+	now                 func() time.Time
+}
+
+// Option тип для функциональных опций
+type Option func(*Config)
+
+// WithName задает имя breaker в логах и в StateChangeFunc
+func WithName(name string) Option {
+	return func(c *Config) {
+		c.name = name
+	}
+}
+
+// WithConsecutiveFailures размыкает цепь после n ошибок подряд, по умолчанию 5; 0 отключает условие
+func WithConsecutiveFailures(n int) Option {
+	return func(c *Config) {
+		c.consecutiveFailures = n
+	}
+}
+
+// WithErrorRate размыкает цепь, если доля ошибок за окно window достигла rate (0..1)
+// и в окне не меньше minRequests вызовов
+func WithErrorRate(rate float64, minRequests int, window time.Duration) Option {
+	return func(c *Config) {
+		c.errorRate = rate
+		c.minRequests = minRequests
+		c.window = window
+	}
+}
+
+// WithOpenTimeout задает время в разомкнутом состоянии до пробных вызовов, по умолчанию 30 секунд
+func WithOpenTimeout(d time.Duration) Option {
+	return func(c *Config) {
+		c.openTimeout = d
+	}
+}
+
+// WithHalfOpenRequests задает количество пробных вызовов; цепь замыкается, если все они успешны. По умолчанию 1.
+func WithHalfOpenRequests(n int) Option {
+	return func(c *Config) {
+		c.halfOpenRequests = n
+	}
+}
+
+// WithFailure задает функцию, определяющую, считается ли ошибка отказом; по умолчанию IsFailure
+func WithFailure(isFailure func(err error) bool) Option {
+	return func(c *Config) {
+		c.isFailure = isFailure
+	}
+}
+
+// WithStateChange задает функцию, вызываемую при смене состояния
+func WithStateChange(onStateChange StateChangeFunc) Option {
+	return func(c *Config) {
+		c.onStateChange = onStateChange
+	}
+}
+
+// WithLogger задает логгер для записи смены состояний
+func WithLogger(logger log.Logger) Option {
+	return func(c *Config) {
+		c.logger = logger
+	}
+}
+
+// WithClock задает источник текущего времени
+func WithClock(now func() time.Time) Option {
+	return func(c *Config) {
+		c.now = now
+	}
+}
+
+// IsFailure считает отказом любую ошибку, кроме отмены вызова через context.Canceled:
+// отмена исходит от клиента и не говорит о недоступности зависимости
+func IsFailure(err error) bool {
+	return err != nil && !errors.Is(err, context.Canceled)
+}
+
+func newConfig(opts []Option) Config {
+	config := Config{
+		consecutiveFailures: 5,
+		window:              10 * time.Second,
+		openTimeout:         30 * time.Second,
+		halfOpenRequests:    1,
+		isFailure:           IsFailure,
+		now:                 time.Now,
+	}
+
+	for _, applyOpt := range opts {
+		applyOpt(&config)
+	}
+
+	return config
+}
+
+// Breaker circuit breaker с состояниями closed, open и half-open
+type Breaker struct {
+	config Config
+
+	mu          sync.Mutex
+	state       State
+	generation  uint64
+	consecutive int
+	window      *window
+	openedAt    time.Time
+	probes      int
+	successes   int
+	changes     []stateChange
+}
+
+type stateChange struct {
+	from, to State
+}
+
+// New создает circuit breaker
+func New(opts ...Option) *Breaker {
+	return newBreaker(newConfig(opts))
+}
+
+func newBreaker(config Config) *Breaker {
+	return &Breaker{
+		config: config,
+		window: newWindow(config.window),
+	}
+}
+
+// State возвращает текущее состояние
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.unlock()
+
+	b.refresh(b.config.now())
+
+	return b.state
+}
+
+// Allow проверяет, можно ли выполнить вызов. Если можно, результат вызова передается в done,
+// иначе возвращается ErrOpen.
+func (b *Breaker) Allow() (done func(err error), err error) {
+	b.mu.Lock()
+	defer b.unlock()
+
+	b.refresh(b.config.now())
+
+	switch b.state {
+	case StateOpen:
+		return nil, ErrOpen
+	case StateHalfOpen:
+		if b.probes >= b.config.halfOpenRequests {
+			return nil, ErrOpen
+		}
+		b.probes++
+	}
+
+	generation := b.generation
+
+	return func(err error) {
+		b.done(generation, errors.Is(err, errPanic) || b.config.isFailure(err))
+	}, nil
+}
+
+// Do выполняет fn, если цепь не разомкнута
+func (b *Breaker) Do(fn func() error) error {
+	done, err := b.Allow()
+	if err != nil {
+		return err
+	}
+
+	defer donePanicking(done)
+
+	err = fn()
+	done(err)
+
+	return err
+}
+
+// donePanicking передает в done отказ, если вызов завершился паникой, и продолжает панику,
+// чтобы пробный вызов полуоткрытого состояния не занимал место до перезапуска
+func donePanicking(done func(err error)) {
+	if r := recover(); r != nil {
+		done(errPanic)
+		panic(r)
+	}
+}
+
+// done учитывает результат вызова; результаты вызовов, начатых до смены состояния, не учитываются
+func (b *Breaker) done(generation uint64, failure bool) {
+	b.mu.Lock()
+	defer b.unlock()
+
+	if generation != b.generation {
+		return
+	}
+
+	now := b.config.now()
+
+	switch b.state {
+	case StateHalfOpen:
+		if failure {
+			b.setState(StateOpen, now)
+			return
+		}
+		b.successes++
+		if b.successes >= b.config.halfOpenRequests {
+			b.setState(StateClosed, now)
+		}
+	case StateClosed:
+		b.window.add(now, failure)
+		if !failure {
+			b.consecutive = 0
+			return
+		}
+
+		b.consecutive++
+		if b.shouldTrip(now) {
+			b.setState(StateOpen, now)
+		}
+	}
+}
+
+func (b *Breaker) shouldTrip(now time.Time) bool {
+	if b.config.consecutiveFailures > 0 && b.consecutive >= b.config.consecutiveFailures {
+		return true
+	}
+
+	if b.config.errorRate > 0 {
+		total, failures := b.window.counts(now)
+		if total > 0 && total >= b.config.minRequests && float64(failures)/float64(total) >= b.config.errorRate {
+			return true
+		}
+	}
+
+	return false
+}
+
+// refresh переводит разомкнутую цепь в half-open по истечении таймаута
+func (b *Breaker) refresh(now time.Time) {
+	if b.state == StateOpen && now.Sub(b.openedAt) >= b.config.openTimeout {
+		b.setState(StateHalfOpen, now)
+	}
+}
+
+func (b *Breaker) setState(state State, now time.Time) {
+	from := b.state
+	b.state = state
+	b.generation++
+	b.consecutive = 0
+	b.probes = 0
+	b.successes = 0
+
+	switch state {
+	case StateOpen:
+		b.openedAt = now
+	case StateClosed:
+		b.window = newWindow(b.config.window)
+	}
+
+	b.changes = append(b.changes, stateChange{from: from, to: state})
+}
+
+// unlock снимает блокировку и сообщает о сменах состояния, чтобы обработчики могли обращаться к breaker
+func (b *Breaker) unlock() {
+	changes := b.changes
+	b.changes = nil
+	b.mu.Unlock()
+
+	for _, c := range changes {
+		if b.config.logger != nil {
+			b.config.logger.Warn("circuit breaker state changed", map[string]any{
+				"breaker": b.config.name,
+				"from":    c.from.String(),
+				"to":      c.to.String(),
+			})
+		}
+		if b.config.onStateChange != nil {
+			b.config.onStateChange(b.config.name, c.from, c.to)
+		}
+	}
+}
+
+// Group набор circuit breaker по ключам, например по хосту или имени метода
+type Group struct {
+	opts []Option
+
+	mu       sync.Mutex
+	breakers map[string]*Breaker
+}
+
+// NewGroup создает набор circuit breaker; опции применяются к каждому breaker, а его имя равно ключу
+func NewGroup(opts ...Option) *Group {
+	return &Group{
+		opts:     opts,
+		breakers: make(map[string]*Breaker),
+	}
+}
+
+// Get возвращает breaker для ключа key, создавая его при первом обращении
+func (g *Group) Get(key string) *Breaker {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	b, ok := g.breakers[key]
+	if !ok {
+		config := newConfig(g.opts)
+		config.name = key
+		b = newBreaker(config)
+		g.breakers[key] = b
+	}
+
+	return b
+}
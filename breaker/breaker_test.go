@@ -0,0 +1,297 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-mosaic/runtime"
+	"github.com/go-mosaic/runtime/transport/client"
+)
+
+var errFailure = errors.New("failure")
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+type recordingLogger struct {
+	messages []string
+}
+
+func (l *recordingLogger) Debug(string, map[string]any) {}
+func (l *recordingLogger) Info(string, map[string]any)  {}
+func (l *recordingLogger) Error(string, map[string]any) {}
+func (l *recordingLogger) Warn(msg string, fields map[string]any) {
+	l.messages = append(l.messages, msg+": "+fields["from"].(string)+" -> "+fields["to"].(string))
+}
+
+// step сдвигает часы и выполняет вызов с ошибкой err, если не задан advanceOnly
+type step struct {
+	advance     time.Duration
+	advanceOnly bool
+	err         error
+	wantErr     error
+	wantState   State
+}
+
+func TestBreaker(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  []Option
+		steps []step
+	}{
+		{
+			name: "consecutive failures",
+			opts: []Option{WithConsecutiveFailures(2), WithOpenTimeout(time.Second)},
+			steps: []step{
+				{err: errFailure, wantErr: errFailure, wantState: StateClosed},
+				{wantState: StateClosed},
+				{err: errFailure, wantErr: errFailure, wantState: StateClosed},
+				{err: errFailure, wantErr: errFailure, wantState: StateOpen},
+				{wantErr: ErrOpen, wantState: StateOpen},
+				{advance: time.Second, wantState: StateClosed},
+			},
+		},
+		{
+			name: "failed probe reopens",
+			opts: []Option{WithConsecutiveFailures(1), WithOpenTimeout(time.Second)},
+			steps: []step{
+				{err: errFailure, wantErr: errFailure, wantState: StateOpen},
+				{advance: time.Second, err: errFailure, wantErr: errFailure, wantState: StateOpen},
+				{advance: 500 * time.Millisecond, wantErr: ErrOpen, wantState: StateOpen},
+				{advance: 500 * time.Millisecond, wantState: StateClosed},
+			},
+		},
+		{
+			name: "several probes",
+			opts: []Option{WithConsecutiveFailures(1), WithOpenTimeout(time.Second), WithHalfOpenRequests(2)},
+			steps: []step{
+				{err: errFailure, wantErr: errFailure, wantState: StateOpen},
+				{advance: time.Second, wantState: StateHalfOpen},
+				{wantState: StateClosed},
+			},
+		},
+		{
+			name: "error rate",
+			opts: []Option{WithConsecutiveFailures(0), WithErrorRate(0.5, 4, 10*time.Second)},
+			steps: []step{
+				{err: errFailure, wantErr: errFailure, wantState: StateClosed},
+				{wantState: StateClosed},
+				{err: errFailure, wantErr: errFailure, wantState: StateClosed},
+				{wantState: StateClosed},
+				{err: errFailure, wantErr: errFailure, wantState: StateOpen},
+			},
+		},
+		{
+			name: "error rate outside window",
+			opts: []Option{WithConsecutiveFailures(0), WithErrorRate(0.5, 2, 10*time.Second)},
+			steps: []step{
+				{err: errFailure, wantErr: errFailure, wantState: StateClosed},
+				{advance: 11 * time.Second, advanceOnly: true},
+				{advance: time.Second, wantState: StateClosed},
+				{err: errFailure, wantErr: errFailure, wantState: StateOpen},
+			},
+		},
+		{
+			name: "canceled calls ignored",
+			opts: []Option{WithConsecutiveFailures(1)},
+			steps: []step{
+				{err: context.Canceled, wantErr: context.Canceled, wantState: StateClosed},
+				{err: fmt.Errorf("call: %w", context.Canceled), wantErr: context.Canceled, wantState: StateClosed},
+				{err: context.DeadlineExceeded, wantErr: context.DeadlineExceeded, wantState: StateOpen},
+			},
+		},
+		{
+			name: "custom failure",
+			opts: []Option{
+				WithConsecutiveFailures(1),
+				WithFailure(func(err error) bool { return err != nil && !errors.Is(err, errFailure) }),
+			},
+			steps: []step{
+				{err: errFailure, wantErr: errFailure, wantState: StateClosed},
+				{err: context.Canceled, wantErr: context.Canceled, wantState: StateOpen},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+			b := New(append(tt.opts, WithClock(clock.Now))...)
+
+			for i, s := range tt.steps {
+				clock.Advance(s.advance)
+				if s.advanceOnly {
+					continue
+				}
+
+				err := b.Do(func() error { return s.err })
+				if !errors.Is(err, s.wantErr) {
+					t.Fatalf("step %d: Do() error = %v, want %v", i, err, s.wantErr)
+				}
+				if got := b.State(); got != s.wantState {
+					t.Fatalf("step %d: State() = %v, want %v", i, got, s.wantState)
+				}
+			}
+		})
+	}
+}
+
+func TestBreakerStateChange(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	logger := &recordingLogger{}
+
+	var changes []string
+	b := New(
+		WithName("payments"),
+		WithConsecutiveFailures(1),
+		WithOpenTimeout(time.Second),
+		WithClock(clock.Now),
+		WithLogger(logger),
+		WithStateChange(func(name string, from, to State) {
+			changes = append(changes, name+": "+from.String()+" -> "+to.String())
+		}),
+	)
+
+	// результат вызова, начатого до размыкания цепи, не учитывается после смены состояния
+	stale, err := b.Allow()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = b.Do(func() error { return errFailure })
+	clock.Advance(time.Second)
+	stale(errFailure)
+	_ = b.Do(func() error { return nil })
+
+	want := "payments: closed -> open, payments: open -> half-open, payments: half-open -> closed"
+	if got := strings.Join(changes, ", "); got != want {
+		t.Errorf("state changes = %s, want %s", got, want)
+	}
+	if got := strings.Join(logger.messages, ", "); !strings.Contains(got, "circuit breaker state changed: half-open -> closed") {
+		t.Errorf("logged = %s", got)
+	}
+}
+
+func TestBreakerPanic(t *testing.T) {
+	tests := []struct {
+		name string
+		call func(b *Breaker, fn func() error) error
+	}{
+		{
+			name: "Do",
+			call: func(b *Breaker, fn func() error) error { return b.Do(fn) },
+		},
+		{
+			name: "Func",
+			call: func(b *Breaker, fn func() error) error {
+				_, err := Func[struct{}, struct{}](b)(func(context.Context, struct{}) (struct{}, error) {
+					return struct{}{}, fn()
+				})(context.Background(), struct{}{})
+				return err
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+			b := New(WithConsecutiveFailures(1), WithOpenTimeout(time.Second), WithClock(clock.Now))
+
+			_ = tt.call(b, func() error { return errFailure })
+			clock.Advance(time.Second)
+
+			// паника пробного вызова размыкает цепь и освобождает место пробного вызова
+			func() {
+				defer func() {
+					if recover() == nil {
+						t.Errorf("panic was not propagated")
+					}
+				}()
+				_ = tt.call(b, func() error { panic("boom") })
+			}()
+			if got := b.State(); got != StateOpen {
+				t.Fatalf("State() after panic = %v, want %v", got, StateOpen)
+			}
+
+			clock.Advance(time.Second)
+			if err := tt.call(b, func() error { return nil }); err != nil {
+				t.Fatalf("probe after panic error = %v", err)
+			}
+			if got := b.State(); got != StateClosed {
+				t.Errorf("State() = %v, want %v", got, StateClosed)
+			}
+		})
+	}
+}
+
+func TestGroupFunc(t *testing.T) {
+	group := NewGroup(WithConsecutiveFailures(1))
+
+	call := func(_ context.Context, tenant string) (string, error) {
+		if tenant == "bad" {
+			return "", errFailure
+		}
+		return "ok:" + tenant, nil
+	}
+	logged := 0
+	logging := func(next func(context.Context, string) (string, error)) func(context.Context, string) (string, error) {
+		return func(ctx context.Context, req string) (string, error) {
+			logged++
+			return next(ctx, req)
+		}
+	}
+
+	guarded := runtime.MiddlewareChain(logging, GroupFunc[string, string](group, func(_ context.Context, tenant string) string {
+		return tenant
+	}))(call)
+
+	ctx := context.Background()
+	if _, err := guarded(ctx, "bad"); !errors.Is(err, errFailure) {
+		t.Fatalf("first call error = %v", err)
+	}
+	if _, err := guarded(ctx, "bad"); !errors.Is(err, ErrOpen) {
+		t.Fatalf("second call error = %v, want ErrOpen", err)
+	}
+	if got, err := guarded(ctx, "good"); err != nil || got != "ok:good" {
+		t.Fatalf("other key = %q, %v", got, err)
+	}
+	if logged != 3 {
+		t.Errorf("logged calls = %d, want 3", logged)
+	}
+}
+
+func TestClient(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := client.New(srv.URL, client.WithMiddleware(Client(NewGroup(WithConsecutiveFailures(2)))))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range 3 {
+		_, err := c.NewRequest(http.MethodGet, "/").Do(context.Background())
+		if i < 2 && errors.Is(err, ErrOpen) || i == 2 && !errors.Is(err, ErrOpen) {
+			t.Fatalf("request %d error = %v", i, err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("server calls = %d, want 2", calls)
+	}
+}
@@ -0,0 +1,74 @@
+package breaker
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-mosaic/runtime"
+	"github.com/go-mosaic/runtime/transport/client"
+)
+
+// Func создает middleware для функций сервиса вида func(ctx, req) (resp, error)
+func Func[Req, Resp any](b *Breaker) runtime.Middleware[func(context.Context, Req) (Resp, error)] {
+	return guard[Req, Resp](func(context.Context, Req) *Breaker { return b })
+}
+
+// GroupFunc создает middleware с отдельным breaker для каждого ключа, возвращаемого key
+func GroupFunc[Req, Resp any](
+	group *Group,
+	key func(ctx context.Context, req Req) string,
+) runtime.Middleware[func(context.Context, Req) (Resp, error)] {
+	return guard[Req, Resp](func(ctx context.Context, req Req) *Breaker { return group.Get(key(ctx, req)) })
+}
+
+func guard[Req, Resp any](
+	breakerOf func(ctx context.Context, req Req) *Breaker,
+) runtime.Middleware[func(context.Context, Req) (Resp, error)] {
+	return func(next func(context.Context, Req) (Resp, error)) func(context.Context, Req) (Resp, error) {
+		return func(ctx context.Context, req Req) (Resp, error) {
+			done, err := breakerOf(ctx, req).Allow()
+			if err != nil {
+				var zero Resp
+				return zero, err
+			}
+			defer donePanicking(done)
+
+			resp, err := next(ctx, req)
+			done(err)
+
+			return resp, err
+		}
+	}
+}
+
+// Client создает client.Middleware с отдельным breaker для каждого хоста.
+// Отказом считаются ошибки выполнения запроса и ответы со статусом 5xx.
+func Client(group *Group) client.Middleware {
+	return func(next client.DoFunc) client.DoFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			done, err := group.Get(req.URL.Host).Allow()
+			if err != nil {
+				return nil, err
+			}
+			defer donePanicking(done)
+
+			resp, err := next(req)
+			if err == nil && resp.StatusCode >= http.StatusInternalServerError {
+				done(&statusError{code: resp.StatusCode})
+			} else {
+				done(err)
+			}
+
+			return resp, err
+		}
+	}
+}
+
+// statusError ответ сервера с ошибкой, учитываемый breaker как отказ
+type statusError struct {
+	code int
+}
+
+func (e *statusError) Error() string {
+	return http.StatusText(e.code)
+}
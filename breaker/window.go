@@ -0,0 +1,55 @@
+package breaker
+
+import "time"
+
+const windowBuckets = 10
+
+type bucket struct {
+	start     time.Time
+	successes int
+	failures  int
+}
+
+// window скользящее окно результатов вызовов, разбитое на интервалы
+type window struct {
+	size     time.Duration
+	interval time.Duration
+	buckets  [windowBuckets]bucket
+}
+
+func newWindow(size time.Duration) *window {
+	return &window{
+		size:     size,
+		interval: max(size/windowBuckets, 1),
+	}
+}
+
+func (w *window) add(now time.Time, failure bool) {
+	start := now.Truncate(w.interval)
+	i := start.UnixNano() / int64(w.interval) % windowBuckets
+	if i < 0 {
+		i += windowBuckets
+	}
+	b := &w.buckets[i]
+	if !b.start.Equal(start) {
+		*b = bucket{start: start}
+	}
+
+	if failure {
+		b.failures++
+	} else {
+		b.successes++
+	}
+}
+
+// counts возвращает количество вызовов и ошибок за окно
+func (w *window) counts(now time.Time) (total, failures int) {
+	for _, b := range w.buckets {
+		if !b.start.IsZero() && now.Sub(b.start) < w.size {
+			total += b.successes + b.failures
+			failures += b.failures
+		}
+	}
+
+	return total, failures
+}
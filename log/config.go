@@ -1,6 +1,11 @@
 package log
 
-import "maps"
+import (
+	"maps"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
 
 // Config конфигурация
 type Config struct {
@@ -9,6 +14,14 @@ type Config struct {
 	logErrors  bool
 	skipFields []string
 	fields     map[string]any
+	start      time.Time
+
+	metricLabels     []string
+	metricLabelLimit int
+	metricRegistry   *MetricRegistry
+
+	tracerProvider trace.TracerProvider
+	aggregate      bool
 }
 
 // Option тип для функциональных опций
@@ -53,12 +66,53 @@ func WithFields(fields map[string]any) Option {
 	}
 }
 
+// WithStartTime задает время начала операции, если span открывается после ее фактического начала
+func WithStartTime(start time.Time) Option {
+	return func(c *Config) {
+		c.start = start
+	}
+}
+
+// WithMetricLabels задает поля span, которые MetricSpan передает в LabeledMetricsCollector как метки.
+// Значение метки берется при старте span; поле, появившееся позже, становится меткой только результата.
+func WithMetricLabels(keys ...string) Option {
+	return func(c *Config) {
+		c.metricLabels = append(c.metricLabels, keys...)
+	}
+}
+
+// WithMetricLabelLimit задает число различных значений каждой метки операции, по умолчанию DefaultMetricLabelLimit.
+// Значения сверх лимита заменяются на OtherLabelValue.
+func WithMetricLabelLimit(limit int) Option {
+	return func(c *Config) {
+		c.metricLabelLimit = limit
+	}
+}
+
+// WithMetricRegistry задает реестр значений меток и выполняемых операций MetricSpan.
+// По умолчанию используется реестр сборщика, реализующего MetricRegistryProvider, иначе общий реестр пакета.
+func WithMetricRegistry(registry *MetricRegistry) Option {
+	return func(c *Config) {
+		c.metricRegistry = registry
+	}
+}
+
+// WithAggregation включает агрегацию: вложенные span не пишут собственные записи,
+// а span при завершении пишет одну запись с их длительностями в поле FieldChildren
+func WithAggregation(enabled bool) Option {
+	return func(c *Config) {
+		c.aggregate = enabled
+	}
+}
+
 // NewConfig создает новую конфигурацию с опциями
 func NewConfig(opts ...Option) Config {
 	config := Config{
 		logStart:  true,
 		logResult: true,
 		logErrors: true,
+
+		metricLabelLimit: DefaultMetricLabelLimit,
 	}
 
 	for _, applyOpt := range opts {
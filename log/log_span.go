@@ -2,11 +2,25 @@ package log
 
 import (
 	"context"
+	"fmt"
 	"maps"
+	"math/rand/v2"
 	"strconv"
+	"sync"
 	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Поля записи span
+const (
+	FieldSpanID       = "span_id"
+	FieldParentSpanID = "parent_span_id"
+	FieldChildren     = "children"
 )
 
+type spanContextKey struct{}
+
 type Span struct {
 	ctx       context.Context
 	logger    Logger
@@ -14,64 +28,186 @@ type Span struct {
 	fields    map[string]any
 	start     time.Time
 	config    Config
+	trace     trace.Span
+
+	id       string
+	parentID string
+	// aggregator span, в запись которого попадают записи вложенных span при WithAggregation
+	aggregator *Span
+
+	mu       sync.Mutex
+	children []map[string]any
 }
 
-// StartLogSpan
+// StartLogSpan начинает span операции. Если ctx содержит span, новый span становится его дочерним
+// и наследует его поля.
 func StartLogSpan(ctx context.Context, logger Logger, operation string, opts ...Option) *Span {
 	config := NewConfig(opts...)
 
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	parent := SpanFromContext(ctx)
+
 	fields := make(map[string]any)
+	if parent != nil {
+		parent.mu.Lock()
+		maps.Copy(fields, parent.fields)
+		parent.mu.Unlock()
+	}
 	maps.Copy(fields, config.fields)
 
-	return &Span{
+	start := config.start
+	if start.IsZero() {
+		start = time.Now()
+	}
+
+	s := &Span{
 		ctx:       ctx,
 		logger:    logger,
 		operation: operation,
 		fields:    fields,
-		start:     time.Now(),
+		start:     start,
 		config:    config,
 	}
+	s.startTrace()
+
+	s.id = newSpanID(s.trace)
+	s.fields[FieldSpanID] = s.id
+	if parent != nil {
+		s.parentID = parent.id
+		s.fields[FieldParentSpanID] = parent.id
+		s.aggregator = parent.aggregator
+	}
+	if s.aggregator == nil && config.aggregate {
+		s.aggregator = s
+	}
+
+	s.ctx = context.WithValue(s.ctx, spanContextKey{}, s)
+
+	return s
+}
+
+// SpanFromContext возвращает span из контекста или nil
+func SpanFromContext(ctx context.Context) *Span {
+	s, _ := ctx.Value(spanContextKey{}).(*Span)
+	return s
+}
+
+// ID возвращает идентификатор span
+func (s *Span) ID() string {
+	return s.id
+}
+
+// ParentID возвращает идентификатор родительского span или пустую строку для корневого
+func (s *Span) ParentID() string {
+	return s.parentID
+}
+
+// Context возвращает контекст, содержащий span, для передачи во вложенные операции.
+// При включенной трассировке контекст также содержит span трассировки.
+func (s *Span) Context() context.Context {
+	return s.ctx
 }
 
 // WithField добавляет поле в span
 func (s *Span) WithField(key string, value any) *Span {
+	s.mu.Lock()
 	s.fields[key] = value
+	s.mu.Unlock()
+
+	s.setTraceAttributes(map[string]any{key: value})
 	return s
 }
 
 // WithFields добавляет несколько полей в span
 func (s *Span) WithFields(fields map[string]any) *Span {
+	s.mu.Lock()
 	maps.Copy(s.fields, fields)
+	s.mu.Unlock()
+
+	s.setTraceAttributes(fields)
 	return s
 }
 
 // Finish завершает span и логирует результат
 func (s *Span) Finish(results ...any) {
 	s.finish(results...)
+	s.endTrace(nil)
 
-	s.logger.Info(s.operation+" completed", s.fields)
+	s.write(s.logger.Info, s.operation+" completed")
 }
 
 // FinishWithError завершает span с ошибкой
 func (s *Span) FinishWithError(err error, results ...any) {
 	s.finish(results...)
 
+	s.mu.Lock()
 	s.fields["error"] = err.Error()
 
-	if le, ok := err.(LoggableError); ok {
+	le, ok := err.(LoggableError)
+	if ok {
 		maps.Copy(s.fields, le.Fields())
 	}
+	s.mu.Unlock()
+
+	if ok {
+		s.setTraceAttributes(le.Fields())
+	}
+	s.endTrace(err)
 
-	s.logger.Error(s.operation+" failed", s.fields)
+	s.write(s.logger.Error, s.operation+" failed")
+}
+
+// write пишет запись span. При агрегации запись вложенного span добавляется в поле FieldChildren
+// записи span, включившего WithAggregation.
+func (s *Span) write(log func(msg string, fields map[string]any), msg string) {
+	if s.aggregator != nil && s.aggregator != s {
+		s.aggregator.addChild(s.summary())
+		return
+	}
+
+	if s.aggregator == s {
+		s.mu.Lock()
+		if len(s.children) > 0 {
+			s.fields[FieldChildren] = s.children
+		}
+		s.mu.Unlock()
+	}
+
+	log(msg, s.fields)
+}
+
+// summary возвращает запись вложенного span для поля FieldChildren
+func (s *Span) summary() map[string]any {
+	summary := map[string]any{
+		"operation":       s.operation,
+		FieldSpanID:       s.id,
+		FieldParentSpanID: s.parentID,
+		"duration":        s.fields["duration"],
+	}
+	if err, ok := s.fields["error"]; ok {
+		summary["error"] = err
+	}
+
+	return summary
+}
+
+func (s *Span) addChild(summary map[string]any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.children = append(s.children, summary)
 }
 
 func (s *Span) finish(results ...any) {
-	if s.ctx != nil {
-		contextFields := extractFieldsFromContext(s.ctx)
-		for k, v := range contextFields {
-			if _, exists := s.fields[k]; !exists {
-				s.fields[k] = v
-			}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	contextFields := extractFieldsFromContext(s.ctx)
+	for k, v := range contextFields {
+		if _, exists := s.fields[k]; !exists {
+			s.fields[k] = v
 		}
 	}
 
@@ -101,3 +237,12 @@ func (s *Span) addResultsToFields(results []any) {
 		s.fields[fieldName] = res
 	}
 }
+
+// newSpanID возвращает идентификатор span трассировки или случайный идентификатор того же формата
+func newSpanID(span trace.Span) string {
+	if span != nil && span.SpanContext().HasSpanID() {
+		return span.SpanContext().SpanID().String()
+	}
+
+	return fmt.Sprintf("%016x", rand.Uint64()) //nolint:gosec
+}
@@ -0,0 +1,140 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type entry struct {
+	msg    string
+	fields map[string]any
+}
+
+type recordingLogger struct {
+	mu      sync.Mutex
+	entries []entry
+}
+
+func (l *recordingLogger) Debug(msg string, fields map[string]any) { l.record(msg, fields) }
+func (l *recordingLogger) Info(msg string, fields map[string]any)  { l.record(msg, fields) }
+func (l *recordingLogger) Warn(msg string, fields map[string]any)  { l.record(msg, fields) }
+func (l *recordingLogger) Error(msg string, fields map[string]any) { l.record(msg, fields) }
+
+func (l *recordingLogger) record(msg string, fields map[string]any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.entries = append(l.entries, entry{msg: msg, fields: fields})
+}
+
+func TestNestedSpans(t *testing.T) {
+	logger := &recordingLogger{}
+
+	root := StartLogSpan(context.Background(), logger, "ProcessOrder", WithFields(map[string]any{"tenant": "acme"}))
+	root.WithField("order_id", 42)
+
+	child := StartLogSpan(root.Context(), logger, "LoadOrder", WithFields(map[string]any{"tenant": "override"}))
+	grandchild := StartLogSpan(child.Context(), logger, "QueryDB")
+
+	if SpanFromContext(child.Context()) != child {
+		t.Error("SpanFromContext() did not return the span")
+	}
+	if SpanFromContext(context.Background()) != nil {
+		t.Error("SpanFromContext() of empty context is not nil")
+	}
+	if root.ParentID() != "" || child.ParentID() != root.ID() || grandchild.ParentID() != child.ID() {
+		t.Errorf("parent IDs = %q, %q, %q", root.ParentID(), child.ParentID(), grandchild.ParentID())
+	}
+	if len(root.ID()) != 16 || root.ID() == child.ID() {
+		t.Errorf("IDs = %q, %q", root.ID(), child.ID())
+	}
+
+	grandchild.Finish()
+	child.Finish()
+	root.Finish()
+
+	if len(logger.entries) != 3 {
+		t.Fatalf("entries = %d, want 3", len(logger.entries))
+	}
+
+	got := logger.entries[0].fields
+	want := map[string]any{
+		FieldSpanID:       grandchild.ID(),
+		FieldParentSpanID: child.ID(),
+		"tenant":          "override",
+		"order_id":        42,
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("grandchild field %s = %v, want %v", key, got[key], value)
+		}
+	}
+	if _, ok := logger.entries[2].fields[FieldParentSpanID]; ok {
+		t.Error("root entry has parent_span_id")
+	}
+}
+
+func TestAggregation(t *testing.T) {
+	logger := &recordingLogger{}
+
+	root := StartLogSpan(context.Background(), logger, "ProcessOrder", WithAggregation(true))
+
+	var wg sync.WaitGroup
+	for _, operation := range []string{"LoadOrder", "LoadCustomer"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			span := StartMetricSpan(root.Context(), logger, operation, nil)
+			StartLogSpan(span.Context(), logger, "QueryDB").Finish()
+			span.Finish()
+		}()
+	}
+	wg.Wait()
+
+	StartLogSpan(root.Context(), logger, "ChargeCard").FinishWithError(errors.New("card declined"))
+	root.Finish()
+
+	if len(logger.entries) != 1 {
+		t.Fatalf("entries = %d, want 1", len(logger.entries))
+	}
+	if logger.entries[0].msg != "ProcessOrder completed" {
+		t.Errorf("msg = %q", logger.entries[0].msg)
+	}
+
+	children, _ := logger.entries[0].fields[FieldChildren].([]map[string]any)
+	if len(children) != 5 {
+		t.Fatalf("children = %v, want 5", children)
+	}
+
+	operations := map[string]int{}
+	for _, child := range children {
+		operations[child["operation"].(string)]++
+		if child["duration"] == nil || child[FieldSpanID] == nil || child[FieldParentSpanID] == nil {
+			t.Errorf("child = %v", child)
+		}
+	}
+	if operations["QueryDB"] != 2 || operations["LoadOrder"] != 1 || operations["LoadCustomer"] != 1 {
+		t.Errorf("operations = %v", operations)
+	}
+
+	last := children[4]
+	if last["operation"] != "ChargeCard" || last["error"] != "card declined" || last[FieldParentSpanID] != root.ID() {
+		t.Errorf("failed child = %v", last)
+	}
+}
+
+func TestSpanIDFromTrace(t *testing.T) {
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(tracetest.NewInMemoryExporter()))
+
+	span := StartLogSpan(context.Background(), noopLogger{}, "op", WithTracerProvider(tp))
+	defer span.Finish()
+
+	if want := span.trace.SpanContext().SpanID().String(); span.ID() != want {
+		t.Errorf("ID() = %q, want trace span ID %q", span.ID(), want)
+	}
+}
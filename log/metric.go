@@ -1,6 +1,9 @@
 package log
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 // MetricsCollector интерфейс для сбора метрик
 type MetricsCollector interface {
@@ -13,3 +16,42 @@ type MetricsCollector interface {
 	// RecordCall записывает вызов операции
 	RecordCall(operation string)
 }
+
+// GaugeCollector опциональный интерфейс MetricsCollector для записи текущих значений
+type GaugeCollector interface {
+	// RecordGauge записывает текущее значение показателя
+	RecordGauge(name string, value float64)
+}
+
+// ContextMetricsCollector опциональный интерфейс MetricsCollector, добавляющего к измерениям данные контекста.
+// MetricSpan записывает вызов и результат операции через сборщик, возвращенный для контекста span.
+type ContextMetricsCollector interface {
+	MetricsCollectorFor(ctx context.Context) MetricsCollector
+}
+
+// LabeledMetricsCollector опциональный интерфейс MetricsCollector для записи метрик с метками.
+// MetricSpan передает в него поля span, заданные WithMetricLabels, а при ошибке и метку ErrorClassLabel.
+type LabeledMetricsCollector interface {
+	MetricsCollector
+
+	// RecordCallWithLabels записывает вызов операции с метками
+	RecordCallWithLabels(operation string, labels map[string]string)
+
+	// RecordSuccessWithLabels записывает успешный вызов операции с метками
+	RecordSuccessWithLabels(operation string, duration time.Duration, labels map[string]string)
+
+	// RecordErrorWithLabels записывает ошибку операции с метками
+	RecordErrorWithLabels(operation string, duration time.Duration, labels map[string]string)
+}
+
+const (
+	// ErrorClassLabel метка класса ошибки: LoggableError.Level() или ErrorClassUnknown
+	ErrorClassLabel = "error_class"
+	// ErrorClassUnknown класс ошибки, не реализующей LoggableError
+	ErrorClassUnknown = "unknown"
+	// OtherLabelValue значение, которым заменяются значения метки сверх лимита WithMetricLabelLimit
+	OtherLabelValue = "_other"
+)
+
+// InflightSuffix суффикс показателя GaugeCollector с количеством начатых, но не завершенных операций
+const InflightSuffix = ".inflight"
@@ -0,0 +1,94 @@
+package log
+
+import (
+	"sync"
+)
+
+// DefaultMetricLabelLimit число различных значений каждой метки операции по умолчанию
+const DefaultMetricLabelLimit = 100
+
+type metricLabelKey struct {
+	operation string
+	label     string
+}
+
+// MetricRegistry состояние MetricSpan одного сборщика метрик: различные значения меток операций
+// для ограничения кардинальности и количество выполняемых операций. Безопасен для конкурентного использования.
+type MetricRegistry struct {
+	mu       sync.Mutex
+	seen     map[metricLabelKey]map[string]struct{}
+	inflight map[string]int64
+}
+
+// NewMetricRegistry создает пустой реестр
+func NewMetricRegistry() *MetricRegistry {
+	return &MetricRegistry{
+		seen:     make(map[metricLabelKey]map[string]struct{}),
+		inflight: make(map[string]int64),
+	}
+}
+
+// MetricRegistryProvider опциональный интерфейс MetricsCollector, хранящего собственный MetricRegistry
+type MetricRegistryProvider interface {
+	MetricRegistry() *MetricRegistry
+}
+
+// defaultMetricRegistry реестр сборщиков, не реализующих MetricRegistryProvider
+var defaultMetricRegistry = NewMetricRegistry()
+
+// metricRegistry возвращает реестр, заданный WithMetricRegistry, реестр сборщика или общий реестр пакета
+func metricRegistry(config Config, collector MetricsCollector) *MetricRegistry {
+	if config.metricRegistry != nil {
+		return config.metricRegistry
+	}
+	if p, ok := collector.(MetricRegistryProvider); ok {
+		if r := p.MetricRegistry(); r != nil {
+			return r
+		}
+	}
+
+	return defaultMetricRegistry
+}
+
+// guardLabelValue возвращает value, если значение метки уже встречалось или лимит не исчерпан, иначе OtherLabelValue.
+// Лимит limit <= 0 снимает ограничение.
+func (r *MetricRegistry) guardLabelValue(operation, label, value string, limit int) string {
+	if limit <= 0 {
+		return value
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := metricLabelKey{operation: operation, label: label}
+	values, ok := r.seen[key]
+	if !ok {
+		values = make(map[string]struct{})
+		r.seen[key] = values
+	}
+
+	if _, ok := values[value]; ok {
+		return value
+	}
+	if len(values) >= limit {
+		return OtherLabelValue
+	}
+	values[value] = struct{}{}
+
+	return value
+}
+
+// addInflight изменяет количество выполняемых операций operation на delta и возвращает новое значение
+func (r *MetricRegistry) addInflight(operation string, delta int64) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := r.inflight[operation] + delta
+	if n == 0 {
+		delete(r.inflight, operation)
+	} else {
+		r.inflight[operation] = n
+	}
+
+	return n
+}
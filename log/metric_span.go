@@ -2,15 +2,28 @@ package log
 
 import (
 	"context"
+	"fmt"
+	"maps"
 	"time"
 )
 
-// MetricSpan расширяет базовый Span для сбора метрик
+// MetricSpan расширяет базовый Span для сбора метрик.
+// Если сборщик реализует LabeledMetricsCollector, метрики записываются с метками из полей WithMetricLabels,
+// если GaugeCollector — в показатель operation+InflightSuffix записывается количество выполняемых операций.
+// Метки вызова определяются при старте и повторяются в метках результата, чтобы их ряды совпадали;
+// к результату добавляются только метки полей, которых не было при старте, например кода ответа.
+// Значения меток и выполняемые операции учитываются в MetricRegistry сборщика.
 type MetricSpan struct {
 	*Span
 	collector MetricsCollector
+	// gauges сборщик показателя выполняемых операций; в отличие от collector не привязан к контексту span,
+	// потому что количество считается по операции целиком
+	gauges    GaugeCollector
+	registry  *MetricRegistry
 	operation string
 	start     time.Time
+	// metricLabels метки вызова; nil, если сборщик не реализует LabeledMetricsCollector
+	metricLabels map[string]string
 }
 
 // StartMetricSpan начинает новый span с метриками
@@ -21,34 +34,133 @@ func StartMetricSpan(
 	collector MetricsCollector,
 	opts ...Option,
 ) *MetricSpan {
-	if collector != nil {
-		collector.RecordCall(operation)
-	}
+	span := StartLogSpan(ctx, logger, operation, opts...)
 
-	return &MetricSpan{
-		Span:      StartLogSpan(ctx, logger, operation, opts...),
+	s := &MetricSpan{
+		Span:      span,
 		collector: collector,
 		operation: operation,
-		start:     time.Now(),
+		start:     span.start,
+	}
+
+	if collector == nil {
+		return s
+	}
+	s.registry = metricRegistry(span.config, collector)
+	s.gauges, _ = collector.(GaugeCollector)
+	if cc, ok := collector.(ContextMetricsCollector); ok {
+		collector = cc.MetricsCollectorFor(span.Context())
+		s.collector = collector
+	}
+
+	if labeled, ok := collector.(LabeledMetricsCollector); ok {
+		// При старте известны только поля WithFields и контекста
+		fields := make(map[string]any)
+		if ctx != nil {
+			maps.Copy(fields, extractFieldsFromContext(ctx))
+		}
+		maps.Copy(fields, span.fields)
+
+		s.metricLabels = s.labels(fields)
+		labeled.RecordCallWithLabels(operation, maps.Clone(s.metricLabels))
+	} else {
+		collector.RecordCall(operation)
+	}
+
+	if s.gauges != nil {
+		s.gauges.RecordGauge(operation+InflightSuffix, float64(s.registry.addInflight(operation, 1)))
 	}
+
+	return s
 }
 
 // Finish завершает span и записывает метрики успешного выполнения
 func (s *MetricSpan) Finish(results ...any) {
 	duration := time.Since(s.start)
-	if s.collector != nil {
+
+	s.Span.Finish(results...)
+
+	if s.collector == nil {
+		return
+	}
+
+	if labeled, ok := s.collector.(LabeledMetricsCollector); ok {
+		labeled.RecordSuccessWithLabels(s.operation, duration, s.resultLabels())
+	} else {
 		s.collector.RecordSuccess(s.operation, duration)
 	}
 
-	s.Span.Finish(results...)
+	s.finishInflight()
 }
 
 // FinishWithError завершает span и записывает метрики ошибки
 func (s *MetricSpan) FinishWithError(err error, results ...any) {
 	duration := time.Since(s.start)
-	if s.collector != nil {
+
+	s.Span.FinishWithError(err, results...)
+
+	if s.collector == nil {
+		return
+	}
+
+	if labeled, ok := s.collector.(LabeledMetricsCollector); ok {
+		labels := s.resultLabels()
+		labels[ErrorClassLabel] = s.registry.guardLabelValue(s.operation, ErrorClassLabel, errorClass(err), s.config.metricLabelLimit)
+
+		labeled.RecordErrorWithLabels(s.operation, duration, labels)
+	} else {
 		s.collector.RecordError(s.operation, duration)
 	}
 
-	s.Span.FinishWithError(err, results...)
+	s.finishInflight()
+}
+
+func (s *MetricSpan) finishInflight() {
+	if s.gauges != nil {
+		s.gauges.RecordGauge(s.operation+InflightSuffix, float64(s.registry.addInflight(s.operation, -1)))
+	}
+}
+
+// labels возвращает метки из полей WithMetricLabels; отсутствующие поля пропускаются
+func (s *MetricSpan) labels(fields map[string]any) map[string]string {
+	labels := make(map[string]string, len(s.config.metricLabels)+1)
+	for _, key := range s.config.metricLabels {
+		value, ok := fields[key]
+		if !ok {
+			continue
+		}
+
+		labels[key] = s.registry.guardLabelValue(s.operation, key, fmt.Sprint(value), s.config.metricLabelLimit)
+	}
+
+	return labels
+}
+
+// resultLabels возвращает метки вызова, дополненные метками полей, добавленных после старта
+func (s *MetricSpan) resultLabels() map[string]string {
+	s.mu.Lock()
+	added := make(map[string]any)
+	for _, key := range s.config.metricLabels {
+		if _, ok := s.metricLabels[key]; ok {
+			continue
+		}
+		if value, ok := s.fields[key]; ok {
+			added[key] = value
+		}
+	}
+	s.mu.Unlock()
+
+	labels := s.labels(added)
+	maps.Copy(labels, s.metricLabels)
+
+	return labels
+}
+
+// errorClass возвращает класс ошибки для метки ErrorClassLabel
+func errorClass(err error) string {
+	if le, ok := err.(LoggableError); ok && le.Level() != "" {
+		return le.Level()
+	}
+
+	return ErrorClassUnknown
 }
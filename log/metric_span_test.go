@@ -0,0 +1,130 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"sync"
+	"testing"
+	"time"
+)
+
+// labelCollector запоминает метки вызовов и последние значения показателей
+type labelCollector struct {
+	registry *MetricRegistry
+
+	mu      sync.Mutex
+	calls   []map[string]string
+	results []map[string]string
+	gauges  map[string]float64
+}
+
+func newLabelCollector() *labelCollector {
+	return &labelCollector{registry: NewMetricRegistry(), gauges: make(map[string]float64)}
+}
+
+func (c *labelCollector) MetricRegistry() *MetricRegistry { return c.registry }
+
+func (c *labelCollector) RecordCall(string)                   {}
+func (c *labelCollector) RecordSuccess(string, time.Duration) {}
+func (c *labelCollector) RecordError(string, time.Duration)   {}
+
+func (c *labelCollector) RecordCallWithLabels(_ string, labels map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls = append(c.calls, labels)
+}
+
+func (c *labelCollector) RecordSuccessWithLabels(_ string, _ time.Duration, labels map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.results = append(c.results, labels)
+}
+
+func (c *labelCollector) RecordErrorWithLabels(_ string, _ time.Duration, labels map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.results = append(c.results, labels)
+}
+
+func (c *labelCollector) RecordGauge(name string, value float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gauges[name] = value
+}
+
+func TestMetricRegistryPerCollector(t *testing.T) {
+	shared := NewMetricRegistry()
+
+	tests := []struct {
+		name         string
+		opts         []Option
+		wantTenant   string
+		wantInflight float64
+	}{
+		{name: "collector registry", wantTenant: "b", wantInflight: 1},
+		{name: "option registry", opts: []Option{WithMetricRegistry(shared)}, wantTenant: OtherLabelValue, wantInflight: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first, second := newLabelCollector(), newLabelCollector()
+			start := func(collector *labelCollector, tenant string) *MetricSpan {
+				return StartMetricSpan(context.Background(), noopLogger{}, "charge", collector, append([]Option{
+					WithFields(map[string]any{"tenant": tenant}),
+					WithMetricLabels("tenant"),
+					WithMetricLabelLimit(1),
+				}, tt.opts...)...)
+			}
+
+			a := start(first, "a")
+			b := start(second, "b")
+			defer a.Finish()
+			defer b.Finish()
+
+			if got := second.calls[0]["tenant"]; got != tt.wantTenant {
+				t.Errorf("tenant label = %q, want %q", got, tt.wantTenant)
+			}
+			if got := second.gauges["charge"+InflightSuffix]; got != tt.wantInflight {
+				t.Errorf("in-flight = %v, want %v", got, tt.wantInflight)
+			}
+		})
+	}
+}
+
+func TestMetricSpanResultLabels(t *testing.T) {
+	tests := []struct {
+		name   string
+		finish func(*MetricSpan)
+		want   map[string]string
+	}{
+		{
+			name:   "success",
+			finish: func(s *MetricSpan) { s.Finish() },
+			want:   map[string]string{"tenant": "a", "region": "eu"},
+		},
+		{
+			name:   "error",
+			finish: func(s *MetricSpan) { s.FinishWithError(errors.New("boom")) },
+			want:   map[string]string{"tenant": "a", "region": "eu", ErrorClassLabel: ErrorClassUnknown},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collector := newLabelCollector()
+			span := StartMetricSpan(context.Background(), noopLogger{}, "charge", collector,
+				WithFields(map[string]any{"tenant": "a"}),
+				WithMetricLabels("tenant", "region"),
+			)
+			// измененное после старта поле не меняет метку вызова, новое поле добавляется к результату
+			span.WithFields(map[string]any{"tenant": "b", "region": "eu"})
+			tt.finish(span)
+
+			if !maps.Equal(collector.calls[0], map[string]string{"tenant": "a"}) {
+				t.Errorf("call labels = %v, want tenant=a", collector.calls[0])
+			}
+			if !maps.Equal(collector.results[0], tt.want) {
+				t.Errorf("result labels = %v, want %v", collector.results[0], tt.want)
+			}
+		})
+	}
+}
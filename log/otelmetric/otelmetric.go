@@ -0,0 +1,250 @@
+package otelmetric
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+
+	"github.com/go-mosaic/runtime/log"
+)
+
+// ScopeName имя инструментации, под которым создаются инструменты
+const ScopeName = "github.com/go-mosaic/runtime/log/otelmetric"
+
+// Имена инструментов
+const (
+	CallsName    = "operation.calls"
+	ErrorsName   = "operation.errors"
+	DurationName = "operation.duration"
+)
+
+// DefaultBuckets границы интервалов гистограммы длительности в секундах по умолчанию
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+var (
+	_ log.LabeledMetricsCollector = (*Collector)(nil)
+	_ log.GaugeCollector          = (*Collector)(nil)
+	_ log.MetricRegistryProvider  = (*Collector)(nil)
+	_ log.ContextMetricsCollector = (*Collector)(nil)
+)
+
+// OperationKey атрибут с именем операции
+const OperationKey = attribute.Key("operation")
+
+// Config конфигурация сборщика
+type Config struct {
+	meterProvider metric.MeterProvider
+	buckets       []float64
+	extractors    []log.ContextExtractor
+}
+
+// Option тип для функциональных опций
+type Option func(*Config)
+
+// WithMeterProvider задает MeterProvider, по умолчанию otel.GetMeterProvider()
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(c *Config) {
+		c.meterProvider = mp
+	}
+}
+
+// WithBuckets задает границы интервалов гистограммы длительности в секундах
+func WithBuckets(buckets ...float64) Option {
+	return func(c *Config) {
+		c.buckets = buckets
+	}
+}
+
+// WithContextExtractor добавляет функцию извлечения атрибутов из контекста log.MetricSpan
+// или контекста, переданного в Collector.WithContext
+func WithContextExtractor(extractor log.ContextExtractor) Option {
+	return func(c *Config) {
+		c.extractors = append(c.extractors, extractor)
+	}
+}
+
+// instruments инструменты, общие для всех копий Collector
+type instruments struct {
+	meter    metric.Meter
+	calls    metric.Int64Counter
+	errors   metric.Int64Counter
+	duration metric.Float64Histogram
+	registry *log.MetricRegistry
+
+	mu     sync.Mutex
+	gauges map[string]metric.Float64Gauge
+}
+
+// Collector реализация log.LabeledMetricsCollector и log.GaugeCollector поверх OpenTelemetry Meter.
+// Операция передается атрибутом OperationKey, ошибки отмечаются атрибутом error.type
+// со значением метки log.ErrorClassLabel или _OTHER.
+type Collector struct {
+	instruments *instruments
+	extractors  []log.ContextExtractor
+
+	ctx   context.Context
+	attrs attribute.Set
+}
+
+// New создает сборщик метрик
+func New(opts ...Option) (*Collector, error) {
+	config := Config{
+		meterProvider: otel.GetMeterProvider(),
+		buckets:       DefaultBuckets,
+	}
+
+	for _, applyOpt := range opts {
+		applyOpt(&config)
+	}
+
+	meter := config.meterProvider.Meter(ScopeName)
+
+	calls, err := meter.Int64Counter(CallsName,
+		metric.WithDescription("Number of operation calls."),
+		metric.WithUnit("{call}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	errs, err := meter.Int64Counter(ErrorsName,
+		metric.WithDescription("Number of failed operations."),
+		metric.WithUnit("{error}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	duration, err := meter.Float64Histogram(DurationName,
+		metric.WithDescription("Duration of finished operations."),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(config.buckets...),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Collector{
+		instruments: &instruments{
+			meter:    meter,
+			calls:    calls,
+			errors:   errs,
+			duration: duration,
+			registry: log.NewMetricRegistry(),
+			gauges:   make(map[string]metric.Float64Gauge),
+		},
+		extractors: config.extractors,
+		ctx:        context.Background(),
+	}, nil
+}
+
+// WithContext возвращает копию сборщика, которая добавляет к измерениям атрибуты,
+// извлеченные из ctx функциями WithContextExtractor, и передает ctx в инструменты
+func (c *Collector) WithContext(ctx context.Context) *Collector {
+	var attrs []attribute.KeyValue
+	for _, extractor := range c.extractors {
+		for key, value := range extractor(ctx) {
+			attrs = append(attrs, log.Attribute(key, value))
+		}
+	}
+
+	return &Collector{
+		instruments: c.instruments,
+		extractors:  c.extractors,
+		ctx:         ctx,
+		attrs:       attribute.NewSet(append(c.attrs.ToSlice(), attrs...)...),
+	}
+}
+
+// MetricsCollectorFor возвращает копию сборщика для контекста log.MetricSpan, см. WithContext
+func (c *Collector) MetricsCollectorFor(ctx context.Context) log.MetricsCollector {
+	return c.WithContext(ctx)
+}
+
+// MetricRegistry возвращает реестр значений меток и выполняемых операций MetricSpan, общий для всех копий сборщика
+func (c *Collector) MetricRegistry() *log.MetricRegistry {
+	return c.instruments.registry
+}
+
+// RecordCall записывает вызов операции
+func (c *Collector) RecordCall(operation string) {
+	c.RecordCallWithLabels(operation, nil)
+}
+
+// RecordSuccess записывает успешное выполнение операции
+func (c *Collector) RecordSuccess(operation string, duration time.Duration) {
+	c.RecordSuccessWithLabels(operation, duration, nil)
+}
+
+// RecordError записывает ошибку операции
+func (c *Collector) RecordError(operation string, duration time.Duration) {
+	c.RecordErrorWithLabels(operation, duration, nil)
+}
+
+// RecordCallWithLabels записывает вызов операции с метками
+func (c *Collector) RecordCallWithLabels(operation string, labels map[string]string) {
+	c.instruments.calls.Add(c.ctx, 1, metric.WithAttributeSet(c.attributes(operation, labels)))
+}
+
+// RecordSuccessWithLabels записывает успешное выполнение операции с метками
+func (c *Collector) RecordSuccessWithLabels(operation string, duration time.Duration, labels map[string]string) {
+	c.instruments.duration.Record(c.ctx, duration.Seconds(), metric.WithAttributeSet(c.attributes(operation, labels)))
+}
+
+// RecordErrorWithLabels записывает ошибку операции с метками
+func (c *Collector) RecordErrorWithLabels(operation string, duration time.Duration, labels map[string]string) {
+	errorType := semconv.ErrorTypeOther
+	if class, ok := labels[log.ErrorClassLabel]; ok && class != log.ErrorClassUnknown {
+		errorType = semconv.ErrorTypeKey.String(class)
+	}
+
+	attrs := metric.WithAttributeSet(c.attributes(operation, labels, errorType))
+
+	c.instruments.errors.Add(c.ctx, 1, attrs)
+	c.instruments.duration.Record(c.ctx, duration.Seconds(), attrs)
+}
+
+// RecordGauge записывает текущее значение показателя; инструмент с именем name создается при первом вызове
+func (c *Collector) RecordGauge(name string, value float64) {
+	gauge, err := c.instruments.gauge(name)
+	if err != nil {
+		otel.Handle(err)
+		return
+	}
+
+	gauge.Record(c.ctx, value, metric.WithAttributeSet(c.attrs))
+}
+
+func (i *instruments) gauge(name string) (metric.Float64Gauge, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if gauge, ok := i.gauges[name]; ok {
+		return gauge, nil
+	}
+
+	gauge, err := i.meter.Float64Gauge(name)
+	if err != nil {
+		return nil, err
+	}
+	i.gauges[name] = gauge
+
+	return gauge, nil
+}
+
+// attributes объединяет атрибуты контекста, метки и атрибуты измерения
+func (c *Collector) attributes(operation string, labels map[string]string, attrs ...attribute.KeyValue) attribute.Set {
+	kvs := append(c.attrs.ToSlice(), OperationKey.String(operation))
+	for key, value := range labels {
+		if key != log.ErrorClassLabel {
+			kvs = append(kvs, attribute.String(key, value))
+		}
+	}
+
+	return attribute.NewSet(append(kvs, attrs...)...)
+}
@@ -0,0 +1,219 @@
+package otelmetric
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata/metricdatatest"
+
+	"github.com/go-mosaic/runtime/log"
+)
+
+type tenantKey struct{}
+
+func tenant(ctx context.Context) map[string]any {
+	if v, ok := ctx.Value(tenantKey{}).(string); ok {
+		return map[string]any{"tenant": v}
+	}
+
+	return nil
+}
+
+type noopLogger struct{}
+
+func (noopLogger) Debug(string, map[string]any) {}
+func (noopLogger) Info(string, map[string]any)  {}
+func (noopLogger) Warn(string, map[string]any)  {}
+func (noopLogger) Error(string, map[string]any) {}
+
+func collect(t *testing.T, reader *sdkmetric.ManualReader) map[string]metricdata.Metrics {
+	t.Helper()
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+
+	metrics := make(map[string]metricdata.Metrics)
+	for _, sm := range rm.ScopeMetrics {
+		if sm.Scope.Name != ScopeName {
+			t.Errorf("scope = %q, want %q", sm.Scope.Name, ScopeName)
+		}
+		for _, m := range sm.Metrics {
+			metrics[m.Name] = m
+		}
+	}
+
+	return metrics
+}
+
+func TestCollector(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	c, err := New(
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+		WithBuckets(0.1, 1),
+		WithContextExtractor(tenant),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	tc := c.WithContext(ctx)
+
+	log.StartMetricSpan(ctx, noopLogger{}, "CreateOrder", tc).Finish()
+	log.StartMetricSpan(ctx, noopLogger{}, "CreateOrder", tc).FinishWithError(errors.New("conflict"))
+	c.RecordCall("ListOrders")
+	c.RecordSuccess("ListOrders", 2*time.Second)
+	tc.RecordGauge("orders.pending", 3)
+
+	create := attribute.NewSet(attribute.String("operation", "CreateOrder"), attribute.String("tenant", "acme"))
+	createErr := attribute.NewSet(
+		attribute.String("operation", "CreateOrder"),
+		attribute.String("tenant", "acme"),
+		attribute.String("error.type", "_OTHER"),
+	)
+	list := attribute.NewSet(attribute.String("operation", "ListOrders"))
+
+	metrics := collect(t, reader)
+
+	metricdatatest.AssertEqual(t, metricdata.Metrics{
+		Name:        CallsName,
+		Description: "Number of operation calls.",
+		Unit:        "{call}",
+		Data: metricdata.Sum[int64]{
+			Temporality: metricdata.CumulativeTemporality,
+			IsMonotonic: true,
+			DataPoints: []metricdata.DataPoint[int64]{
+				{Attributes: create, Value: 2},
+				{Attributes: list, Value: 1},
+			},
+		},
+	}, metrics[CallsName], metricdatatest.IgnoreTimestamp())
+
+	metricdatatest.AssertEqual(t, metricdata.Metrics{
+		Name:        ErrorsName,
+		Description: "Number of failed operations.",
+		Unit:        "{error}",
+		Data: metricdata.Sum[int64]{
+			Temporality: metricdata.CumulativeTemporality,
+			IsMonotonic: true,
+			DataPoints:  []metricdata.DataPoint[int64]{{Attributes: createErr, Value: 1}},
+		},
+	}, metrics[ErrorsName], metricdatatest.IgnoreTimestamp())
+
+	duration, ok := metrics[DurationName].Data.(metricdata.Histogram[float64])
+	if !ok {
+		t.Fatalf("%s = %T, want histogram", DurationName, metrics[DurationName].Data)
+	}
+	if metrics[DurationName].Unit != "s" {
+		t.Errorf("unit = %q, want s", metrics[DurationName].Unit)
+	}
+
+	counts := map[attribute.Distinct]uint64{}
+	for _, dp := range duration.DataPoints {
+		counts[dp.Attributes.Equivalent()] = dp.Count
+		if len(dp.Bounds) != 2 {
+			t.Errorf("bounds = %v, want [0.1 1]", dp.Bounds)
+		}
+	}
+	want := map[attribute.Distinct]uint64{
+		create.Equivalent():    1,
+		createErr.Equivalent(): 1,
+		list.Equivalent():      1,
+	}
+	if len(counts) != len(want) {
+		t.Errorf("duration data points = %d, want %d", len(counts), len(want))
+	}
+	for set, n := range want {
+		if counts[set] != n {
+			t.Errorf("duration count for %v = %d, want %d", set, counts[set], n)
+		}
+	}
+
+	metricdatatest.AssertEqual(t, metricdata.Metrics{
+		Name: "orders.pending",
+		Data: metricdata.Gauge[float64]{
+			DataPoints: []metricdata.DataPoint[float64]{
+				{Attributes: attribute.NewSet(attribute.String("tenant", "acme")), Value: 3},
+			},
+		},
+	}, metrics["orders.pending"], metricdatatest.IgnoreTimestamp())
+}
+
+type classifiedError struct{}
+
+func (classifiedError) Error() string          { return "not found" }
+func (classifiedError) Level() string          { return "warn" }
+func (classifiedError) Fields() map[string]any { return nil }
+
+func TestErrorType(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	c, err := New(WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	log.StartMetricSpan(context.Background(), noopLogger{}, "GetOrder", c,
+		log.WithFields(map[string]any{"region": "eu"}),
+		log.WithMetricLabels("region"),
+	).FinishWithError(classifiedError{})
+
+	metricdatatest.AssertEqual(t, metricdata.Metrics{
+		Name:        ErrorsName,
+		Description: "Number of failed operations.",
+		Unit:        "{error}",
+		Data: metricdata.Sum[int64]{
+			Temporality: metricdata.CumulativeTemporality,
+			IsMonotonic: true,
+			DataPoints: []metricdata.DataPoint[int64]{{
+				Attributes: attribute.NewSet(
+					attribute.String("operation", "GetOrder"),
+					attribute.String("region", "eu"),
+					attribute.String("error.type", "warn"),
+				),
+				Value: 1,
+			}},
+		},
+	}, collect(t, reader)[ErrorsName], metricdatatest.IgnoreTimestamp())
+}
+
+func TestMetricSpanContext(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	c, err := New(WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))), WithContextExtractor(tenant))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	log.StartMetricSpan(ctx, noopLogger{}, "CreateOrder", c).Finish()
+
+	metrics := collect(t, reader)
+
+	metricdatatest.AssertEqual(t, metricdata.Metrics{
+		Name:        CallsName,
+		Description: "Number of operation calls.",
+		Unit:        "{call}",
+		Data: metricdata.Sum[int64]{
+			Temporality: metricdata.CumulativeTemporality,
+			IsMonotonic: true,
+			DataPoints: []metricdata.DataPoint[int64]{{
+				Attributes: attribute.NewSet(OperationKey.String("CreateOrder"), attribute.String("tenant", "acme")),
+				Value:      1,
+			}},
+		},
+	}, metrics[CallsName], metricdatatest.IgnoreTimestamp())
+
+	// количество выполняемых операций считается по операции целиком, без атрибутов контекста
+	metricdatatest.AssertEqual(t, metricdata.Metrics{
+		Name: "CreateOrder" + log.InflightSuffix,
+		Data: metricdata.Gauge[float64]{
+			DataPoints: []metricdata.DataPoint[float64]{{Attributes: attribute.NewSet(), Value: 0}},
+		},
+	}, metrics["CreateOrder"+log.InflightSuffix], metricdatatest.IgnoreTimestamp())
+}
@@ -0,0 +1,384 @@
+package prometheus
+
+import (
+	"bytes"
+	"io"
+	"maps"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-mosaic/runtime/log"
+	"github.com/go-mosaic/runtime/transport"
+)
+
+// ContentType тип ответа в текстовом формате Prometheus
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultBuckets границы интервалов гистограммы длительности в секундах по умолчанию
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+var (
+	_ log.LabeledMetricsCollector = (*Collector)(nil)
+	_ log.GaugeCollector          = (*Collector)(nil)
+	_ log.MetricRegistryProvider  = (*Collector)(nil)
+)
+
+// Config конфигурация сборщика
+type Config struct {
+	namespace string
+	buckets   []float64
+}
+
+// Option тип для функциональных опций
+type Option func(*Config)
+
+// WithNamespace задает префикс имен метрик, например "orders" для orders_operation_calls_total
+func WithNamespace(namespace string) Option {
+	return func(c *Config) {
+		c.namespace = namespace
+	}
+}
+
+// WithBuckets задает границы интервалов гистограммы длительности в секундах
+func WithBuckets(buckets ...float64) Option {
+	return func(c *Config) {
+		c.buckets = slices.Sorted(slices.Values(buckets))
+	}
+}
+
+// histogram гистограмма длительности операции
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+func (h *histogram) observe(buckets []float64, seconds float64) {
+	if h.counts == nil {
+		h.counts = make([]uint64, len(buckets))
+	}
+	for i, b := range buckets {
+		if seconds <= b {
+			h.counts[i]++
+		}
+	}
+	h.sum += seconds
+	h.count++
+}
+
+// operation счетчики операции с одним набором меток
+type operation struct {
+	labels []string
+
+	calls     uint64
+	successes histogram
+	errors    histogram
+}
+
+// Имена семейств метрик операций
+const (
+	callsMetric    = "operation_calls_total"
+	resultsMetric  = "operation_results_total"
+	durationMetric = "operation_duration_seconds"
+	inflightMetric = "operation_inflight"
+)
+
+// reservedLabels метки, которые сборщик добавляет сам; одноименные метки операций получают префикс "label_"
+var reservedLabels = []string{"operation", "result", "le"}
+
+// gauge текущее значение показателя и его исходное имя
+type gauge struct {
+	name  string
+	value float64
+}
+
+// Collector реализация log.MetricsCollector и log.GaugeCollector, хранящая счетчики и гистограммы
+// в памяти и отдающая их в текстовом формате Prometheus. Безопасен для конкурентного использования.
+// Показатели с суффиксом log.InflightSuffix отдаются одним семейством operation_inflight с меткой operation.
+type Collector struct {
+	config   Config
+	registry *log.MetricRegistry
+
+	mu       sync.Mutex
+	series   map[string]*operation
+	gauges   map[string]gauge
+	inflight map[string]float64
+}
+
+// New создает сборщик метрик
+func New(opts ...Option) *Collector {
+	config := Config{buckets: DefaultBuckets}
+
+	for _, applyOpt := range opts {
+		applyOpt(&config)
+	}
+
+	return &Collector{
+		config:   config,
+		registry: log.NewMetricRegistry(),
+		series:   make(map[string]*operation),
+		gauges:   make(map[string]gauge),
+		inflight: make(map[string]float64),
+	}
+}
+
+// MetricRegistry возвращает реестр значений меток и выполняемых операций MetricSpan этого сборщика
+func (c *Collector) MetricRegistry() *log.MetricRegistry {
+	return c.registry
+}
+
+// operation возвращает счетчики операции name с метками labels
+func (c *Collector) operation(name string, labels map[string]string) *operation {
+	pairs := []string{"operation", name}
+	for _, key := range slices.Sorted(maps.Keys(labels)) {
+		pairs = append(pairs, labelName(key), labels[key])
+	}
+
+	id := strings.Join(pairs, "\xff")
+	op, ok := c.series[id]
+	if !ok {
+		op = &operation{labels: slices.Clip(pairs)}
+		c.series[id] = op
+	}
+
+	return op
+}
+
+// RecordCall записывает вызов операции
+func (c *Collector) RecordCall(operation string) {
+	c.RecordCallWithLabels(operation, nil)
+}
+
+// RecordSuccess записывает успешное выполнение операции
+func (c *Collector) RecordSuccess(operation string, duration time.Duration) {
+	c.RecordSuccessWithLabels(operation, duration, nil)
+}
+
+// RecordError записывает ошибку операции
+func (c *Collector) RecordError(operation string, duration time.Duration) {
+	c.RecordErrorWithLabels(operation, duration, nil)
+}
+
+// RecordCallWithLabels записывает вызов операции с метками
+func (c *Collector) RecordCallWithLabels(operation string, labels map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.operation(operation, labels).calls++
+}
+
+// RecordSuccessWithLabels записывает успешное выполнение операции с метками
+func (c *Collector) RecordSuccessWithLabels(operation string, duration time.Duration, labels map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.operation(operation, labels).successes.observe(c.config.buckets, duration.Seconds())
+}
+
+// RecordErrorWithLabels записывает ошибку операции с метками
+func (c *Collector) RecordErrorWithLabels(operation string, duration time.Duration, labels map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.operation(operation, labels).errors.observe(c.config.buckets, duration.Seconds())
+}
+
+// RecordGauge записывает текущее значение показателя.
+// Показатели, имена которых совпадают после замены недопустимых символов, отдаются одной метрикой.
+func (c *Collector) RecordGauge(name string, value float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if operation, ok := strings.CutSuffix(name, log.InflightSuffix); ok {
+		c.inflight[operation] = value
+		return
+	}
+
+	metric := sanitize(name)
+	if slices.Contains([]string{callsMetric, resultsMetric, durationMetric, inflightMetric}, metric) {
+		metric = "gauge_" + metric
+	}
+	c.gauges[metric] = gauge{name: name, value: value}
+}
+
+// WriteTo записывает метрики в текстовом формате Prometheus
+func (c *Collector) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	c.write(&buf)
+
+	return buf.WriteTo(w)
+}
+
+func (c *Collector) write(w *bytes.Buffer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ids := slices.Sorted(maps.Keys(c.series))
+
+	calls := c.name(callsMetric)
+	writeHeader(w, calls, "Number of operation calls.", "counter")
+	for _, id := range ids {
+		op := c.series[id]
+		writeSample(w, calls, labels(op.labels...), float64(op.calls))
+	}
+
+	results := c.name(resultsMetric)
+	writeHeader(w, results, "Number of finished operations by result.", "counter")
+	for _, id := range ids {
+		op := c.series[id]
+		writeSample(w, results, labels(append(op.labels, "result", "success")...), float64(op.successes.count))
+		writeSample(w, results, labels(append(op.labels, "result", "error")...), float64(op.errors.count))
+	}
+
+	duration := c.name(durationMetric)
+	writeHeader(w, duration, "Operation duration in seconds.", "histogram")
+	for _, id := range ids {
+		op := c.series[id]
+		c.writeHistogram(w, duration, append(op.labels, "result", "success"), &op.successes)
+		c.writeHistogram(w, duration, append(op.labels, "result", "error"), &op.errors)
+	}
+
+	inflight := c.name(inflightMetric)
+	writeHeader(w, inflight, "Number of operations in progress.", "gauge")
+	for _, operation := range slices.Sorted(maps.Keys(c.inflight)) {
+		writeSample(w, inflight, labels("operation", operation), c.inflight[operation])
+	}
+
+	for _, metric := range slices.Sorted(maps.Keys(c.gauges)) {
+		g := c.gauges[metric]
+		writeHeader(w, c.name(metric), "Gauge "+g.name+".", "gauge")
+		writeSample(w, c.name(metric), "", g.value)
+	}
+}
+
+func (c *Collector) writeHistogram(w *bytes.Buffer, metric string, pairs []string, h *histogram) {
+	if h.count == 0 {
+		return
+	}
+
+	pairs = slices.Clip(pairs)
+	for i, b := range c.config.buckets {
+		writeSample(w, metric+"_bucket", labels(append(pairs, "le", formatFloat(b))...), float64(h.counts[i]))
+	}
+	writeSample(w, metric+"_bucket", labels(append(pairs, "le", "+Inf")...), float64(h.count))
+	writeSample(w, metric+"_sum", labels(pairs...), h.sum)
+	writeSample(w, metric+"_count", labels(pairs...), float64(h.count))
+}
+
+func (c *Collector) name(metric string) string {
+	if c.config.namespace == "" {
+		return metric
+	}
+
+	return c.config.namespace + "_" + metric
+}
+
+// Handler возвращает handler, отдающий метрики в текстовом формате Prometheus
+func (c *Collector) Handler() transport.Handler {
+	return func(_ transport.Request, resp transport.Response) error {
+		var buf bytes.Buffer
+		c.write(&buf)
+
+		resp.SetHeader("Content-Type", ContentType)
+		resp.SetBody(buf.Bytes(), http.StatusOK)
+
+		return nil
+	}
+}
+
+// Routes регистрирует эндпоинт /metrics
+func (c *Collector) Routes(t transport.Transport, opts ...transport.RouteOption) {
+	t.AddRouteWith(http.MethodGet, "/metrics", c.Handler(), opts...)
+}
+
+func writeHeader(w *bytes.Buffer, metric, help, kind string) {
+	w.WriteString("# HELP " + metric + " " + help + "\n")
+	w.WriteString("# TYPE " + metric + " " + kind + "\n")
+}
+
+func writeSample(w *bytes.Buffer, metric, labels string, value float64) {
+	w.WriteString(metric)
+	w.WriteString(labels)
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(value))
+	w.WriteByte('\n')
+}
+
+// labels форматирует пары имя-значение меток
+func labels(pairs ...string) string {
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i := 0; i < len(pairs); i += 2 {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(pairs[i])
+		sb.WriteString(`="`)
+		sb.WriteString(escapeLabel(pairs[i+1]))
+		sb.WriteByte('"')
+	}
+	sb.WriteByte('}')
+
+	return sb.String()
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
+
+// labelName возвращает имя метки Prometheus для ключа key;
+// имена, занятые метками сборщика или зарезервированные префиксом "__", получают префикс "label_"
+func labelName(key string) string {
+	name := sanitizeLabel(key)
+	if slices.Contains(reservedLabels, name) || strings.HasPrefix(name, "__") {
+		return "label_" + name
+	}
+
+	return name
+}
+
+// sanitize заменяет символы, недопустимые в имени метрики, на "_"
+func sanitize(name string) string {
+	return replaceInvalid(name, true)
+}
+
+// sanitizeLabel заменяет символы, недопустимые в имени метки, на "_"; в отличие от имени метрики, ':' недопустимо.
+// Пустой ключ становится "_".
+func sanitizeLabel(key string) string {
+	if key == "" {
+		return "_"
+	}
+
+	return replaceInvalid(key, false)
+}
+
+func replaceInvalid(name string, allowColon bool) string {
+	b := []byte(name)
+	for i, ch := range b {
+		valid := ch == '_' || allowColon && ch == ':' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' ||
+			i > 0 && ch >= '0' && ch <= '9'
+		if !valid {
+			b[i] = '_'
+		}
+	}
+
+	return string(b)
+}
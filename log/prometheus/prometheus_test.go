@@ -0,0 +1,270 @@
+package prometheus
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-mosaic/runtime/log"
+	transporthttp "github.com/go-mosaic/runtime/transport/http"
+)
+
+func TestWriteTo(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   []Option
+		record func(c *Collector)
+		want   string
+	}{
+		{
+			name: "empty",
+			want: `# HELP operation_calls_total Number of operation calls.
+# TYPE operation_calls_total counter
+# HELP operation_results_total Number of finished operations by result.
+# TYPE operation_results_total counter
+# HELP operation_duration_seconds Operation duration in seconds.
+# TYPE operation_duration_seconds histogram
+# HELP operation_inflight Number of operations in progress.
+# TYPE operation_inflight gauge
+`,
+		},
+		{
+			name: "counters and histograms",
+			opts: []Option{WithNamespace("orders"), WithBuckets(1, 0.1)},
+			record: func(c *Collector) {
+				c.RecordCall("create")
+				c.RecordCall("create")
+				c.RecordSuccess("create", 50*time.Millisecond)
+				c.RecordError("create", 2*time.Second)
+				c.RecordGauge("ratelimit.inflight", 3)
+			},
+			want: `# HELP orders_operation_calls_total Number of operation calls.
+# TYPE orders_operation_calls_total counter
+orders_operation_calls_total{operation="create"} 2
+# HELP orders_operation_results_total Number of finished operations by result.
+# TYPE orders_operation_results_total counter
+orders_operation_results_total{operation="create",result="success"} 1
+orders_operation_results_total{operation="create",result="error"} 1
+# HELP orders_operation_duration_seconds Operation duration in seconds.
+# TYPE orders_operation_duration_seconds histogram
+orders_operation_duration_seconds_bucket{operation="create",result="success",le="0.1"} 1
+orders_operation_duration_seconds_bucket{operation="create",result="success",le="1"} 1
+orders_operation_duration_seconds_bucket{operation="create",result="success",le="+Inf"} 1
+orders_operation_duration_seconds_sum{operation="create",result="success"} 0.05
+orders_operation_duration_seconds_count{operation="create",result="success"} 1
+orders_operation_duration_seconds_bucket{operation="create",result="error",le="0.1"} 0
+orders_operation_duration_seconds_bucket{operation="create",result="error",le="1"} 0
+orders_operation_duration_seconds_bucket{operation="create",result="error",le="+Inf"} 1
+orders_operation_duration_seconds_sum{operation="create",result="error"} 2
+orders_operation_duration_seconds_count{operation="create",result="error"} 1
+# HELP orders_operation_inflight Number of operations in progress.
+# TYPE orders_operation_inflight gauge
+orders_operation_inflight{operation="ratelimit"} 3
+`,
+		},
+		{
+			name: "escaped labels",
+			record: func(c *Collector) {
+				c.RecordCall("GET \"/users\"\n")
+			},
+			want: `# HELP operation_calls_total Number of operation calls.
+# TYPE operation_calls_total counter
+operation_calls_total{operation="GET \"/users\"\n"} 1
+# HELP operation_results_total Number of finished operations by result.
+# TYPE operation_results_total counter
+operation_results_total{operation="GET \"/users\"\n",result="success"} 0
+operation_results_total{operation="GET \"/users\"\n",result="error"} 0
+# HELP operation_duration_seconds Operation duration in seconds.
+# TYPE operation_duration_seconds histogram
+# HELP operation_inflight Number of operations in progress.
+# TYPE operation_inflight gauge
+`,
+		},
+		{
+			name: "reserved labels",
+			record: func(c *Collector) {
+				c.RecordCallWithLabels("get", map[string]string{"operation": "a", "result": "b", "le": "c", "__name__": "d"})
+			},
+			want: `# HELP operation_calls_total Number of operation calls.
+# TYPE operation_calls_total counter
+operation_calls_total{operation="get",label___name__="d",label_le="c",label_operation="a",label_result="b"} 1
+# HELP operation_results_total Number of finished operations by result.
+# TYPE operation_results_total counter
+operation_results_total{operation="get",label___name__="d",label_le="c",label_operation="a",label_result="b",result="success"} 0
+operation_results_total{operation="get",label___name__="d",label_le="c",label_operation="a",label_result="b",result="error"} 0
+# HELP operation_duration_seconds Operation duration in seconds.
+# TYPE operation_duration_seconds histogram
+# HELP operation_inflight Number of operations in progress.
+# TYPE operation_inflight gauge
+`,
+		},
+		{
+			name: "invalid label names",
+			record: func(c *Collector) {
+				c.RecordCallWithLabels("get", map[string]string{"ns:key": "a", "": "b", "1st": "c"})
+			},
+			want: `# HELP operation_calls_total Number of operation calls.
+# TYPE operation_calls_total counter
+operation_calls_total{operation="get",_="b",_st="c",ns_key="a"} 1
+# HELP operation_results_total Number of finished operations by result.
+# TYPE operation_results_total counter
+operation_results_total{operation="get",_="b",_st="c",ns_key="a",result="success"} 0
+operation_results_total{operation="get",_="b",_st="c",ns_key="a",result="error"} 0
+# HELP operation_duration_seconds Operation duration in seconds.
+# TYPE operation_duration_seconds histogram
+# HELP operation_inflight Number of operations in progress.
+# TYPE operation_inflight gauge
+`,
+		},
+		{
+			name: "gauges",
+			record: func(c *Collector) {
+				c.RecordGauge("GET /a.b"+log.InflightSuffix, 1)
+				c.RecordGauge("GET /a_b"+log.InflightSuffix, 2)
+				c.RecordGauge("pool.size", 3)
+				c.RecordGauge("pool_size", 4)
+				c.RecordGauge("operation_inflight", 5)
+			},
+			want: `# HELP operation_calls_total Number of operation calls.
+# TYPE operation_calls_total counter
+# HELP operation_results_total Number of finished operations by result.
+# TYPE operation_results_total counter
+# HELP operation_duration_seconds Operation duration in seconds.
+# TYPE operation_duration_seconds histogram
+# HELP operation_inflight Number of operations in progress.
+# TYPE operation_inflight gauge
+operation_inflight{operation="GET /a.b"} 1
+operation_inflight{operation="GET /a_b"} 2
+# HELP gauge_operation_inflight Gauge operation_inflight.
+# TYPE gauge_operation_inflight gauge
+gauge_operation_inflight 5
+# HELP pool_size Gauge pool_size.
+# TYPE pool_size gauge
+pool_size 4
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(tt.opts...)
+			if tt.record != nil {
+				tt.record(c)
+			}
+
+			var sb strings.Builder
+			if _, err := c.WriteTo(&sb); err != nil {
+				t.Fatal(err)
+			}
+			if got := sb.String(); got != tt.want {
+				t.Errorf("WriteTo() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConcurrentRecord(t *testing.T) {
+	c := New()
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				span := log.StartMetricSpan(context.Background(), noopLogger{}, "op", c)
+				span.Finish()
+				_, _ = c.WriteTo(io.Discard)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var sb strings.Builder
+	_, _ = c.WriteTo(&sb)
+	if !strings.Contains(sb.String(), `operation_calls_total{operation="op"} 800`) ||
+		!strings.Contains(sb.String(), `operation_duration_seconds_count{operation="op",result="success"} 800`) {
+		t.Errorf("WriteTo() =\n%s", sb.String())
+	}
+}
+
+type classifiedError struct{ level string }
+
+func (e classifiedError) Error() string          { return "card declined" }
+func (e classifiedError) Level() string          { return e.level }
+func (e classifiedError) Fields() map[string]any { return nil }
+
+func TestMetricSpanLabels(t *testing.T) {
+	c := New(WithBuckets(1))
+
+	start := func(tenant string) *log.MetricSpan {
+		return log.StartMetricSpan(context.Background(), noopLogger{}, "charge", c,
+			log.WithFields(map[string]any{"tenant": tenant}),
+			log.WithMetricLabels("tenant", "http.status_code"),
+			log.WithMetricLabelLimit(2),
+		)
+	}
+
+	open := start("a")
+	for _, tenant := range []string{"b", "c"} {
+		span := start(tenant)
+		span.WithField("http.status_code", 200)
+		span.Finish()
+	}
+
+	var sb strings.Builder
+	_, _ = c.WriteTo(&sb)
+	if !strings.Contains(sb.String(), `operation_inflight{operation="charge"} 1`) {
+		t.Errorf("WriteTo() =\n%s\nwant charge in-flight 1", sb.String())
+	}
+
+	open.FinishWithError(classifiedError{level: "warn"})
+	start("d").FinishWithError(errors.New("timeout"))
+
+	sb.Reset()
+	_, _ = c.WriteTo(&sb)
+	for _, want := range []string{
+		`operation_calls_total{operation="charge",tenant="a"} 1`,
+		`operation_calls_total{operation="charge",tenant="b"} 1`,
+		`operation_calls_total{operation="charge",tenant="_other"} 2`,
+		`operation_results_total{operation="charge",http_status_code="200",tenant="b",result="success"} 1`,
+		`operation_results_total{operation="charge",http_status_code="200",tenant="_other",result="success"} 1`,
+		`operation_results_total{operation="charge",error_class="warn",tenant="a",result="error"} 1`,
+		`operation_results_total{operation="charge",error_class="unknown",tenant="_other",result="error"} 1`,
+		`operation_inflight{operation="charge"} 0`,
+	} {
+		if !strings.Contains(sb.String(), want) {
+			t.Errorf("WriteTo() =\n%s\nwant %s", sb.String(), want)
+		}
+	}
+}
+
+type noopLogger struct{}
+
+func (noopLogger) Debug(string, map[string]any) {}
+func (noopLogger) Info(string, map[string]any)  {}
+func (noopLogger) Warn(string, map[string]any)  {}
+func (noopLogger) Error(string, map[string]any) {}
+
+func TestRoutes(t *testing.T) {
+	c := New()
+	c.RecordCall("ping")
+
+	tr := transporthttp.NewHTTPTransport()
+	c.Routes(tr)
+
+	w := httptest.NewRecorder()
+	tr.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != ContentType {
+		t.Errorf("status = %d, Content-Type = %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(w.Body.String(), `operation_calls_total{operation="ping"} 1`) {
+		t.Errorf("body = %s", w.Body.String())
+	}
+}
@@ -0,0 +1,83 @@
+package log
+
+import (
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName имя инструментации, под которым создаются span трассировки
+const ScopeName = "github.com/go-mosaic/runtime/log"
+
+// WithTracerProvider включает создание span трассировки для каждого Span.
+// Span трассировки становится дочерним для span трассировки из ctx и доступен вложенным операциям через Span.Context().
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *Config) {
+		c.tracerProvider = tp
+	}
+}
+
+// startTrace начинает span трассировки, если он включен WithTracerProvider
+func (s *Span) startTrace() {
+	if s.config.tracerProvider == nil {
+		return
+	}
+
+	s.ctx, s.trace = s.config.tracerProvider.Tracer(ScopeName).Start(s.ctx, s.operation,
+		trace.WithTimestamp(s.start),
+		trace.WithAttributes(attributes(s.fields)...),
+	)
+}
+
+// setTraceAttributes копирует поля в атрибуты span трассировки
+func (s *Span) setTraceAttributes(fields map[string]any) {
+	if s.trace != nil {
+		s.trace.SetAttributes(attributes(fields)...)
+	}
+}
+
+// endTrace завершает span трассировки, при ошибке устанавливая статус Error
+func (s *Span) endTrace(err error) {
+	if s.trace == nil {
+		return
+	}
+
+	if err != nil {
+		s.trace.RecordError(err)
+		s.trace.SetStatus(codes.Error, err.Error())
+	}
+
+	s.trace.End()
+}
+
+// attributes преобразует поля в атрибуты span трассировки
+func attributes(fields map[string]any) []attribute.KeyValue {
+	attrs := make([]attribute.KeyValue, 0, len(fields))
+	for key, value := range fields {
+		attrs = append(attrs, Attribute(key, value))
+	}
+
+	return attrs
+}
+
+// Attribute преобразует поле в атрибут OpenTelemetry; значения прочих типов форматируются через fmt.Sprint
+func Attribute(key string, value any) attribute.KeyValue {
+	switch v := value.(type) {
+	case string:
+		return attribute.String(key, v)
+	case bool:
+		return attribute.Bool(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case int64:
+		return attribute.Int64(key, v)
+	case float64:
+		return attribute.Float64(key, v)
+	case fmt.Stringer:
+		return attribute.Stringer(key, v)
+	default:
+		return attribute.String(key, fmt.Sprint(v))
+	}
+}
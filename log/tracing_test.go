@@ -0,0 +1,129 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type noopLogger struct{}
+
+func (noopLogger) Debug(string, map[string]any) {}
+func (noopLogger) Info(string, map[string]any)  {}
+func (noopLogger) Warn(string, map[string]any)  {}
+func (noopLogger) Error(string, map[string]any) {}
+
+type notFoundError struct{}
+
+func (notFoundError) Error() string          { return "order not found" }
+func (notFoundError) Level() string          { return "warn" }
+func (notFoundError) Fields() map[string]any { return map[string]any{"order_id": 42} }
+
+func TestTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	root, rootSpan := tp.Tracer("test").Start(context.Background(), "root")
+
+	parent := StartLogSpan(root, noopLogger{}, "ProcessOrder",
+		WithTracerProvider(tp), WithFields(map[string]any{"tenant": "acme"}))
+	parent.WithField("items", 3)
+
+	child := StartMetricSpan(parent.Context(), noopLogger{}, "LoadOrder", nil, WithTracerProvider(tp))
+	child.FinishWithError(notFoundError{})
+
+	parent.Finish()
+	rootSpan.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 3 {
+		t.Fatalf("spans = %d, want 3", len(spans))
+	}
+	childStub, parentStub := spans[0], spans[1]
+
+	if parentStub.Name != "ProcessOrder" || parentStub.Parent.SpanID() != rootSpan.SpanContext().SpanID() {
+		t.Errorf("parent = %s with parent %s, want ProcessOrder with parent %s",
+			parentStub.Name, parentStub.Parent.SpanID(), rootSpan.SpanContext().SpanID())
+	}
+	if childStub.Name != "LoadOrder" || childStub.Parent.SpanID() != parentStub.SpanContext.SpanID() {
+		t.Errorf("child = %s with parent %s, want LoadOrder with parent %s",
+			childStub.Name, childStub.Parent.SpanID(), parentStub.SpanContext.SpanID())
+	}
+	if got := trace.SpanFromContext(parent.Context()).SpanContext(); got.SpanID() != parentStub.SpanContext.SpanID() {
+		t.Errorf("Context() span = %s, want %s", got.SpanID(), parentStub.SpanContext.SpanID())
+	}
+
+	assertAttributes(t, parentStub.Attributes, attribute.String("tenant", "acme"), attribute.Int("items", 3))
+	assertAttributes(t, childStub.Attributes, attribute.Int("order_id", 42))
+
+	if parentStub.Status.Code != codes.Unset {
+		t.Errorf("parent status = %v, want Unset", parentStub.Status.Code)
+	}
+	if childStub.Status.Code != codes.Error || childStub.Status.Description != "order not found" {
+		t.Errorf("child status = %v %q, want Error", childStub.Status.Code, childStub.Status.Description)
+	}
+	if len(childStub.Events) != 1 || childStub.Events[0].Name != "exception" {
+		t.Errorf("child events = %v, want exception", childStub.Events)
+	}
+}
+
+func TestTracingStartTime(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	start := time.Now().Add(-time.Second)
+	StartLogSpan(nil, noopLogger{}, "op", WithTracerProvider(tp), WithStartTime(start)).
+		FinishWithError(errors.New("failed"))
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 || !spans[0].StartTime.Equal(start) {
+		t.Fatalf("spans = %v, want one span started at %v", spans, start)
+	}
+}
+
+func TestWithoutTracing(t *testing.T) {
+	ctx := context.Background()
+	span := StartLogSpan(ctx, noopLogger{}, "op")
+	if trace.SpanFromContext(span.Context()).SpanContext().IsValid() {
+		t.Error("Context() carries a trace span without tracing")
+	}
+	span.Finish()
+}
+
+func TestAttribute(t *testing.T) {
+	tests := []struct {
+		value any
+		want  attribute.Value
+	}{
+		{value: "a", want: attribute.StringValue("a")},
+		{value: true, want: attribute.BoolValue(true)},
+		{value: 42, want: attribute.IntValue(42)},
+		{value: int64(7), want: attribute.Int64Value(7)},
+		{value: 1.5, want: attribute.Float64Value(1.5)},
+		{value: time.Second, want: attribute.StringValue("1s")},
+		{value: []int{1}, want: attribute.StringValue("[1]")},
+	}
+	for _, tt := range tests {
+		if got := Attribute("k", tt.value).Value; got != tt.want {
+			t.Errorf("Attribute(%v) = %v, want %v", tt.value, got.Emit(), tt.want.Emit())
+		}
+	}
+}
+
+func assertAttributes(t *testing.T, got []attribute.KeyValue, want ...attribute.KeyValue) {
+	t.Helper()
+
+	set := attribute.NewSet(got...)
+	for _, kv := range want {
+		if v, ok := set.Value(kv.Key); !ok || v != kv.Value {
+			t.Errorf("attribute %s = %v, want %v", kv.Key, v.Emit(), kv.Value.Emit())
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package accesslog
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"path"
+
+	"github.com/go-mosaic/runtime/log"
+	"github.com/go-mosaic/runtime/transport"
+)
+
+// Поля записи журнала доступа
+const (
+	FieldMethod       = "http.method"
+	FieldRoute        = "http.route"
+	FieldPath         = "http.path"
+	FieldStatusCode   = "http.status_code"
+	FieldResponseSize = "http.response_size"
+	FieldUserAgent    = "http.user_agent"
+	FieldRemoteIP     = "http.remote_ip"
+)
+
+// unmatchedRoute шаблон в имени span запроса без маршрута
+const unmatchedRoute = "<unmatched>"
+
+// Config конфигурация middleware
+type Config struct {
+	collector  log.MetricsCollector
+	exclude    []string
+	sampleRate float64
+	spanOpts   []log.Option
+}
+
+// Option тип для функциональных опций
+type Option func(*Config)
+
+// WithMetricsCollector задает сборщик метрик для span запроса
+func WithMetricsCollector(collector log.MetricsCollector) Option {
+	return func(c *Config) {
+		c.collector = collector
+	}
+}
+
+// WithExcludePaths задает пути, запросы к которым не логируются; метрики по ним записываются.
+// Поддерживаются шаблоны path.Match, например "/internal/*".
+func WithExcludePaths(patterns ...string) Option {
+	return func(c *Config) {
+		c.exclude = append(c.exclude, patterns...)
+	}
+}
+
+// WithSampleRate задает долю логируемых успешных запросов от 0 до 1.
+// Запросы, завершившиеся ошибкой или статусом 5xx, логируются всегда. Метрики записываются для всех запросов.
+func WithSampleRate(rate float64) Option {
+	return func(c *Config) {
+		c.sampleRate = rate
+	}
+}
+
+// WithSpanOptions задает опции span запроса
+func WithSpanOptions(opts ...log.Option) Option {
+	return func(c *Config) {
+		c.spanOpts = append(c.spanOpts, opts...)
+	}
+}
+
+// Middleware записывает журнал доступа: для каждого запроса открывается log.MetricSpan,
+// названный по методу и шаблону маршрута. Исключение путей и сэмплирование отключают только запись в журнал.
+func Middleware(logger log.Logger, opts ...Option) transport.Middleware {
+	config := Config{sampleRate: 1}
+
+	for _, applyOpt := range opts {
+		applyOpt(&config)
+	}
+
+	return func(next transport.Handler) transport.Handler {
+		return func(req transport.Request, resp transport.Response) error {
+			// запрос не попадает в журнал, если путь исключен или успешный запрос не прошел сэмплирование;
+			// успешность известна только по завершении, поэтому решение о сэмплировании принимается в Finish
+			spanLogger := &sampledLogger{Logger: logger}
+			if excluded(config.exclude, req.Path()) {
+				spanLogger.Logger = discardLogger{}
+			}
+
+			// middleware маршрута оборачивает обработчик при регистрации, поэтому шаблон известен до next
+			route := transport.PatternOf(req)
+			span := log.StartMetricSpan(req.Context(), spanLogger, operationName(req.Method(), route),
+				config.collector, config.spanOpts...)
+			span.WithFields(map[string]any{
+				FieldMethod:    req.Method(),
+				FieldRoute:     route,
+				FieldPath:      req.Path(),
+				FieldUserAgent: req.Header("User-Agent"),
+				FieldRemoteIP:  transport.RemoteIP(req),
+			})
+
+			rec := transport.NewResponseRecorder(resp)
+			err := next(req.WithContext(span.Context()), rec)
+
+			statusCode := rec.ResultStatusCode(err)
+			failed := err != nil || statusCode >= http.StatusInternalServerError
+			spanLogger.skip = !failed && config.sampleRate < 1 && rand.Float64() >= config.sampleRate //nolint:gosec
+			span.WithFields(map[string]any{
+				FieldStatusCode:   statusCode,
+				FieldResponseSize: rec.Size(),
+			})
+
+			switch {
+			case err != nil:
+				span.FinishWithError(err)
+			case failed:
+				span.FinishWithError(fmt.Errorf("%d %s", statusCode, http.StatusText(statusCode)))
+			default:
+				span.Finish()
+			}
+
+			return err
+		}
+	}
+}
+
+// operationName возвращает имя span по методу и шаблону маршрута.
+// Запросы без шаблона объединяются в одну операцию, чтобы путь не попадал в имя метрики.
+func operationName(method, route string) string {
+	if route == "" {
+		route = unmatchedRoute
+	}
+
+	return method + " " + route
+}
+
+func excluded(patterns []string, requestPath string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, requestPath); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sampledLogger пропускает запись span запроса, не прошедшего сэмплирование
+type sampledLogger struct {
+	log.Logger
+	skip bool
+}
+
+func (l *sampledLogger) Debug(msg string, fields map[string]any) {
+	if !l.skip {
+		l.Logger.Debug(msg, fields)
+	}
+}
+
+func (l *sampledLogger) Info(msg string, fields map[string]any) {
+	if !l.skip {
+		l.Logger.Info(msg, fields)
+	}
+}
+
+func (l *sampledLogger) Warn(msg string, fields map[string]any) {
+	if !l.skip {
+		l.Logger.Warn(msg, fields)
+	}
+}
+
+func (l *sampledLogger) Error(msg string, fields map[string]any) {
+	if !l.skip {
+		l.Logger.Error(msg, fields)
+	}
+}
+
+// discardLogger логгер span запросов, которые не попадают в журнал, но учитываются в метриках
+type discardLogger struct{}
+
+func (discardLogger) Debug(string, map[string]any) {}
+func (discardLogger) Info(string, map[string]any)  {}
+func (discardLogger) Warn(string, map[string]any)  {}
+func (discardLogger) Error(string, map[string]any) {}
@@ -0,0 +1,242 @@
+package accesslog
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-mosaic/runtime/log"
+	"github.com/go-mosaic/runtime/transport"
+	"github.com/go-mosaic/runtime/transport/internal/transporttest"
+)
+
+type entry struct {
+	level  string
+	msg    string
+	fields map[string]any
+}
+
+type captureLogger struct {
+	mu      sync.Mutex
+	entries []entry
+}
+
+func (l *captureLogger) add(level, msg string, fields map[string]any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, entry{level: level, msg: msg, fields: fields})
+}
+
+func (l *captureLogger) Debug(msg string, fields map[string]any) { l.add("debug", msg, fields) }
+func (l *captureLogger) Info(msg string, fields map[string]any)  { l.add("info", msg, fields) }
+func (l *captureLogger) Warn(msg string, fields map[string]any)  { l.add("warn", msg, fields) }
+func (l *captureLogger) Error(msg string, fields map[string]any) { l.add("error", msg, fields) }
+
+func TestMiddleware(t *testing.T) {
+	patterns := map[string]string{
+		"http":       "/users/{id}",
+		"chi":        "/users/{id}",
+		"echo":       "/users/:id",
+		"fiber":      "/users/:id",
+		"gin":        "/users/:id",
+		"fasthttp":   "/users/{id}",
+		"gorilla":    "/users/{id}",
+		"httprouter": "/users/:id",
+	}
+
+	handler := func(req transport.Request, resp transport.Response) error {
+		switch req.PathValue("id") {
+		case "fail":
+			return errors.New("boom")
+		case "unavailable":
+			resp.SetBody(nil, http.StatusServiceUnavailable)
+			return nil
+		}
+		resp.SetBody([]byte("hello"), http.StatusOK)
+		return nil
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		opts       []Option
+		wantLevel  string
+		wantStatus int
+		wantSize   int
+	}{
+		{name: "success", path: "/users/1", wantLevel: "info", wantStatus: http.StatusOK, wantSize: 5},
+		{name: "returned error", path: "/users/fail", wantLevel: "error", wantStatus: http.StatusInternalServerError},
+		{name: "5xx status", path: "/users/unavailable", wantLevel: "error", wantStatus: http.StatusServiceUnavailable},
+		{name: "excluded", path: "/users/1", opts: []Option{WithExcludePaths("/users/*")}},
+		{name: "not sampled", path: "/users/1", opts: []Option{WithSampleRate(0)}},
+		{name: "errors always sampled", path: "/users/fail", opts: []Option{WithSampleRate(0)}, wantLevel: "error", wantStatus: http.StatusInternalServerError},
+	}
+	for _, adapter := range transporttest.Adapters() {
+		for _, tt := range tests {
+			t.Run(adapter.Name+"/"+tt.name, func(t *testing.T) {
+				logger := &captureLogger{}
+				srv := adapter.New()
+				srv.Transport.AddRoute(http.MethodGet, patterns[adapter.Name], handler, Middleware(logger, tt.opts...))
+
+				r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+				r.Header.Set("User-Agent", "test-agent")
+				resp := srv.Do(t, r)
+				resp.Body.Close()
+
+				if tt.wantLevel == "" {
+					if len(logger.entries) != 0 {
+						t.Fatalf("entries = %v, want none", logger.entries)
+					}
+					return
+				}
+				if len(logger.entries) != 1 {
+					t.Fatalf("entries = %v, want one", logger.entries)
+				}
+
+				e := logger.entries[0]
+				wantMsg := "GET " + patterns[adapter.Name]
+				if e.level != tt.wantLevel || !strings.HasPrefix(e.msg, wantMsg) {
+					t.Errorf("entry = %s %q, want %s %q", e.level, e.msg, tt.wantLevel, wantMsg)
+				}
+				if e.fields[FieldStatusCode] != tt.wantStatus {
+					t.Errorf("status field = %v, want %v", e.fields[FieldStatusCode], tt.wantStatus)
+				}
+				if tt.wantSize != 0 && e.fields[FieldResponseSize] != tt.wantSize {
+					t.Errorf("size field = %v, want %v", e.fields[FieldResponseSize], tt.wantSize)
+				}
+				if e.fields[FieldUserAgent] != "test-agent" || e.fields[FieldRoute] != patterns[adapter.Name] {
+					t.Errorf("fields = %v", e.fields)
+				}
+				if _, ok := e.fields["duration"]; !ok {
+					t.Errorf("duration field is missing")
+				}
+			})
+		}
+	}
+}
+
+type countingCollector struct {
+	mu        sync.Mutex
+	calls     int
+	successes int
+	errors    int
+}
+
+func (c *countingCollector) RecordCall(string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls++
+}
+
+func (c *countingCollector) RecordSuccess(string, time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.successes++
+}
+
+func (c *countingCollector) RecordError(string, time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.errors++
+}
+
+func TestMiddlewareMetrics(t *testing.T) {
+	handler := func(req transport.Request, resp transport.Response) error {
+		if req.PathValue("id") == "fail" {
+			return errors.New("boom")
+		}
+		resp.SetBody(nil, http.StatusOK)
+		return nil
+	}
+
+	tests := []struct {
+		name          string
+		path          string
+		opts          []Option
+		wantSuccesses int
+		wantErrors    int
+		wantEntries   int
+	}{
+		{name: "logged", path: "/users/1", wantSuccesses: 1, wantEntries: 1},
+		{name: "excluded", path: "/users/1", opts: []Option{WithExcludePaths("/users/*")}, wantSuccesses: 1},
+		{name: "excluded error", path: "/users/fail", opts: []Option{WithExcludePaths("/users/*")}, wantErrors: 1},
+		{name: "not sampled", path: "/users/1", opts: []Option{WithSampleRate(0)}, wantSuccesses: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := &captureLogger{}
+			collector := &countingCollector{}
+			srv := transporttest.Adapters()[0].New()
+			srv.Transport.AddRoute(http.MethodGet, "/users/{id}", handler,
+				Middleware(logger, append(tt.opts, WithMetricsCollector(collector))...))
+
+			resp := srv.Do(t, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			resp.Body.Close()
+
+			if collector.calls != 1 || collector.successes != tt.wantSuccesses || collector.errors != tt.wantErrors {
+				t.Errorf("calls, successes, errors = %v, %v, %v, want 1, %v, %v",
+					collector.calls, collector.successes, collector.errors, tt.wantSuccesses, tt.wantErrors)
+			}
+			if len(logger.entries) != tt.wantEntries {
+				t.Errorf("entries = %v, want %v", logger.entries, tt.wantEntries)
+			}
+		})
+	}
+}
+
+func TestMiddlewareSpanContext(t *testing.T) {
+	for _, adapter := range transporttest.Adapters() {
+		t.Run(adapter.Name, func(t *testing.T) {
+			logger := &captureLogger{}
+			var handlerSpanID, nestedParentID string
+			srv := adapter.New()
+			srv.Transport.AddRoute(http.MethodGet, "/nested", func(req transport.Request, resp transport.Response) error {
+				if span := log.SpanFromContext(req.Context()); span != nil {
+					handlerSpanID = span.ID()
+				}
+				nested := log.StartLogSpan(req.Context(), logger, "nested")
+				nestedParentID = nested.ParentID()
+				nested.Finish()
+
+				resp.SetBody(nil, http.StatusOK)
+				return nil
+			}, Middleware(logger))
+
+			resp := srv.Do(t, httptest.NewRequest(http.MethodGet, "/nested", nil))
+			resp.Body.Close()
+
+			if len(logger.entries) != 2 {
+				t.Fatalf("entries = %v, want nested and request", logger.entries)
+			}
+			requestSpanID := logger.entries[1].fields[log.FieldSpanID]
+			if handlerSpanID == "" || handlerSpanID != requestSpanID || nestedParentID != requestSpanID {
+				t.Errorf("handler span = %q, nested parent = %q, want request span %v", handlerSpanID, nestedParentID, requestSpanID)
+			}
+			if logger.entries[1].fields[FieldStatusCode] != http.StatusOK {
+				t.Errorf("status field = %v, want %v", logger.entries[1].fields[FieldStatusCode], http.StatusOK)
+			}
+		})
+	}
+}
+
+func TestOperationName(t *testing.T) {
+	tests := []struct {
+		name  string
+		route string
+		want  string
+	}{
+		{name: "route", route: "/users/{id}", want: "GET /users/{id}"},
+		{name: "unmatched", want: "GET <unmatched>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := operationName(http.MethodGet, tt.route); got != tt.want {
+				t.Errorf("operationName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
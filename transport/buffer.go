@@ -0,0 +1,164 @@
+package transport
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"sync"
+)
+
+// ErrResponseDiscarded возвращается при записи в отброшенный ResponseBuffer
+var ErrResponseDiscarded = errors.New("transport: response discarded")
+
+// WriteResponseProvider интерфейс для ответов, предоставляющих функцию записи данных
+type WriteResponseProvider interface {
+	WriteResponseFunc() WriteResponse
+}
+
+// WriteResponseOf возвращает функцию записи данных, используемую ответом,
+// или DefaultWriteResponse, если ответ ее не предоставляет
+func WriteResponseOf(resp Response) WriteResponse {
+	if p, ok := resp.(WriteResponseProvider); ok {
+		if writeResponse := p.WriteResponseFunc(); writeResponse != nil {
+			return writeResponse
+		}
+	}
+
+	return DefaultWriteResponse
+}
+
+// ResponseBuffer буферизирует ответ в памяти до явного вызова FlushTo.
+// Безопасен для конкурентного использования.
+type ResponseBuffer struct {
+	mu            sync.Mutex
+	header        http.Header
+	statusCode    int
+	body          bytes.Buffer
+	written       bool
+	discarded     bool
+	writeResponse WriteResponse
+}
+
+// NewResponseBuffer создает буфер ответа, записывающий данные с помощью writeResponse
+func NewResponseBuffer(writeResponse WriteResponse) *ResponseBuffer {
+	if writeResponse == nil {
+		writeResponse = DefaultWriteResponse
+	}
+
+	return &ResponseBuffer{
+		header:        make(http.Header),
+		writeResponse: writeResponse,
+	}
+}
+
+func (b *ResponseBuffer) SetStatusCode(code int) {
+	b.WriteHeader(code)
+}
+
+func (b *ResponseBuffer) SetHeader(key, value string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.discarded {
+		b.header.Set(key, value)
+	}
+}
+
+func (b *ResponseBuffer) AddHeader(key, value string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.discarded {
+		b.header.Add(key, value)
+	}
+}
+
+func (b *ResponseBuffer) SetBody(body []byte, statusCode int) int {
+	b.WriteHeader(statusCode)
+	n, _ := b.Write(body)
+
+	return n
+}
+
+func (b *ResponseBuffer) WriteHeader(statusCode int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.discarded || b.statusCode != 0 {
+		return
+	}
+	b.statusCode = statusCode
+	b.written = true
+}
+
+func (b *ResponseBuffer) Write(body []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.discarded {
+		return 0, ErrResponseDiscarded
+	}
+	b.written = true
+
+	return b.body.Write(body)
+}
+
+func (b *ResponseBuffer) WriteData(req Request, data any) {
+	b.writeResponse(req, b, data)
+}
+
+func (b *ResponseBuffer) WriteResponseFunc() WriteResponse {
+	return b.writeResponse
+}
+
+// Header возвращает копию накопленных заголовков
+func (b *ResponseBuffer) Header() http.Header {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.header.Clone()
+}
+
+// StatusCode возвращает записанный статус код, по умолчанию 200
+func (b *ResponseBuffer) StatusCode() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.statusCode == 0 {
+		return http.StatusOK
+	}
+
+	return b.statusCode
+}
+
+// Bytes возвращает копию накопленного тела ответа
+func (b *ResponseBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return bytes.Clone(b.body.Bytes())
+}
+
+// Written сообщает, был ли записан статус код или тело ответа
+func (b *ResponseBuffer) Written() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.written
+}
+
+// Discard отбрасывает накопленный ответ, последующие записи игнорируются
+func (b *ResponseBuffer) Discard() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.discarded = true
+	b.header = make(http.Header)
+	b.body.Reset()
+}
+
+// FlushTo записывает накопленный ответ в resp
+func (b *ResponseBuffer) FlushTo(resp Response) {
+	setHeaders(resp, b.Header())
+	resp.SetBody(b.Bytes(), b.StatusCode())
+}
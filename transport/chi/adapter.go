@@ -2,141 +2,47 @@ package chi
 
 import (
 	"context"
-	"io"
 	"net/http"
-	"net/url"
+	"slices"
 
 	"github.com/go-chi/chi/v5"
 
-	"github.com/go-mosaic/runtime/transport"
+	transporthttp "github.com/go-mosaic/runtime/transport/http"
 )
 
-// ChiRequest адаптер для net/http.Request
-type ChiRequest struct {
-	req      *http.Request
-	readData transport.ReadData
-}
-
-func (r *ChiRequest) WithContext(ctx context.Context) transport.Request {
-	return &ChiRequest{req: r.req.WithContext(ctx), readData: r.readData}
-}
-
-func (r *ChiRequest) Context() context.Context {
-	return r.req.Context()
-}
-
-func (r *ChiRequest) Method() string {
-	return r.req.Method
-}
-
-func (r *ChiRequest) Path() string {
-	return r.req.URL.Path
-}
-
-func (r *ChiRequest) Body() io.ReadCloser {
-	return r.req.Body
-}
-
-func (r *ChiRequest) Header(key string) string {
-	return r.req.Header.Get(key)
-}
-
-func (r *ChiRequest) Queries() url.Values {
-	return r.req.URL.Query()
-}
-
-func (r *ChiRequest) PathValue(name string) string {
-	return chi.URLParam(r.req, name)
-}
-
-func (r *ChiRequest) MultipartForm(maxMemory int64) (transport.Form, error) {
-	err := r.req.ParseMultipartForm(maxMemory)
-	return transport.MultipartFormWrap(r.req.MultipartForm), err
-}
-
-func (r *ChiRequest) URLEncodedForm() (url.Values, error) {
-	if err := r.req.ParseForm(); err != nil {
-		return nil, err
-	}
-
-	return r.req.Form, nil
-}
-
-func (r *ChiRequest) ReadData(data any) error {
-	return r.readData(r, data)
-}
-
-func (r *ChiRequest) SetCookie(c transport.Cookie) error {
-	r.req.AddCookie(&http.Cookie{
-		Name:        c.Name,
-		Value:       c.Value,
-		Path:        c.Path,
-		Domain:      c.Domain,
-		Expires:     c.Expires,
-		MaxAge:      c.MaxAge,
-		Secure:      c.Secure,
-		HttpOnly:    c.HttpOnly,
-		SameSite:    http.SameSite(c.SameSite),
-		Partitioned: c.Partitioned,
-	})
-
-	return nil
-}
-
-func (r *ChiRequest) Cookie(name string) (string, error) {
-	c, err := r.req.Cookie(name)
-	if err != nil {
-		return "", err
-	}
-
-	return c.Value, nil
-}
+// ChiRequest адаптер для net/http.Request с параметрами пути chi
+type ChiRequest = transporthttp.HTTPRequest
 
 // ChiResponse адаптер для net/http.ResponseWriter
-type ChiResponse struct {
-	w             http.ResponseWriter
-	writeResponse transport.WriteResponse
-}
+type ChiResponse = transporthttp.HTTPResponse
 
-func (r *ChiResponse) SetStatusCode(code int) {
-	r.w.WriteHeader(code)
-}
-
-func (r *ChiResponse) SetHeader(key, value string) {
-	r.w.Header().Set(key, value)
-}
-
-func (r *ChiResponse) WriteData(req transport.Request, data any) {
-	r.writeResponse(req, r, data)
-}
+// ChiAdapter адаптер для chi
+type ChiAdapter = transporthttp.HTTPAdapter
 
-func (r *ChiResponse) Write(body []byte) (int, error) {
-	return r.w.Write(body)
+// PathValue возвращает параметр пути, сохраненный chi
+func PathValue(r *http.Request, name string) string {
+	return chi.URLParam(r, name)
 }
 
-func (r *ChiResponse) SetBody(body []byte, statusCode int) int {
-	r.WriteHeader(statusCode)
-	n, _ := r.Write(body)
-
-	return n
+// Pattern возвращает шаблон маршрута chi
+func Pattern(r *http.Request) string {
+	return chi.RouteContext(r.Context()).RoutePattern()
 }
 
-func (r *ChiResponse) WriteHeader(statusCode int) {
-	r.w.WriteHeader(statusCode)
-}
+// Detach возвращает запрос с копией chi.Context: chi возвращает контекст маршрутизации в пул после обработки запроса
+func Detach(r *http.Request) *http.Request {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil {
+		return r
+	}
 
-// ChiAdapter адаптер для net/http
-type ChiAdapter struct {
-	writeResponse transport.WriteResponse
-	readData      transport.ReadData
-}
+	detached := chi.NewRouteContext()
+	detached.Routes = rctx.Routes
+	detached.RoutePath = rctx.RoutePath
+	detached.RouteMethod = rctx.RouteMethod
+	detached.URLParams.Keys = slices.Clone(rctx.URLParams.Keys)
+	detached.URLParams.Values = slices.Clone(rctx.URLParams.Values)
+	detached.RoutePatterns = slices.Clone(rctx.RoutePatterns)
 
-func (a *ChiAdapter) AdaptHandler(handler transport.Handler) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		req := &ChiRequest{req: r, readData: a.readData}
-		resp := &ChiResponse{w: w, writeResponse: a.writeResponse}
-		if err := handler(req, resp); err != nil {
-			a.writeResponse(req, resp, err)
-		}
-	}
+	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, detached))
 }
@@ -1,63 +1,160 @@
 package chi
 
 import (
+	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 
 	"github.com/go-mosaic/runtime/transport"
+	transporthttp "github.com/go-mosaic/runtime/transport/http"
 )
 
 // ChiToMiddleware преобразует middleware chi в transport.Middleware
 func ChiToMiddleware(chiMiddleware func(http.Handler) http.Handler) transport.Middleware {
-	return func(next transport.Handler) transport.Handler {
-		return func(req transport.Request, resp transport.Response) error {
-			chiHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				_ = next(req, resp)
-			})
-
-			wrappedHandler := chiMiddleware(chiHandler)
-			wrappedHandler.ServeHTTP(resp.(*ChiResponse).w, req.(*ChiRequest).req)
+	return transporthttp.HTTPToMiddleware(chiMiddleware)
+}
 
-			return nil
-		}
-	}
+// patternSyntax синтаксис шаблонов chi: catch-all доступен только как "*"
+var patternSyntax = transport.PatternSyntax{
+	Param:    func(name string) string { return "{" + name + "}" },
+	CatchAll: func(string) (string, string) { return "*", "*" },
 }
 
 type ChiTransport struct {
-	router chi.Router
-	adaper *ChiAdapter
+	router      chi.Router
+	middlewares *transport.MiddlewareStack
+	adaper      *ChiAdapter
+	routes      *transport.RouteTable
+	prefix      string
+	subrouters  map[string]chi.Router
+	dispatchers map[string]*transport.Dispatcher
 }
 
-func NewChiTransport(router chi.Router) *ChiTransport {
-	return &ChiTransport{
-		router: router,
-		adaper: &ChiAdapter{
-			writeResponse: transport.DefaultWriteResponse,
-			readData:      transport.DefaultReadData,
-		},
+func NewChiTransport(router chi.Router, opts ...transport.Option) *ChiTransport {
+	t := &ChiTransport{
+		router:      router,
+		middlewares: transport.NewMiddlewareStack(nil),
+		routes:      transport.NewRouteTable(),
+		subrouters:  make(map[string]chi.Router),
+		dispatchers: make(map[string]*transport.Dispatcher),
+		adaper:      transporthttp.NewHTTPAdapter(PathValue, Pattern, opts...),
 	}
+	t.adaper.DetachRequest(Detach)
+	router.NotFound(t.adaper.AdaptHandler(t.middlewares.Handler(t.adaper.ServeNotFound)))
+
+	return t
 }
 
+// AddRoute регистрирует маршрут с middleware
 func (t *ChiTransport) AddRoute(method, path string, handler transport.Handler, middlewares ...transport.Middleware) {
-	wrappedHandler := handler
-	for _, mw := range middlewares {
-		wrappedHandler = mw(wrappedHandler)
+	t.AddRouteWith(method, path, handler, transport.MiddlewareOptions(middlewares...)...)
+}
+
+// AddRouteWith регистрирует маршрут с опциями: именем, тегами, метаданными и middleware
+func (t *ChiTransport) AddRouteWith(method, path string, handler transport.Handler, opts ...transport.RouteOption) {
+	// ограничения проверяются по полному шаблону, а в роутер группы регистрируется относительный путь
+	pattern, full := transport.MustNativePattern(transport.JoinPattern(t.prefix, path), patternSyntax)
+	_, native := transport.MustNativePattern(path, patternSyntax)
+	route := transport.NewRoute(method, path, opts...)
+	if err := t.routes.Add(route); err != nil {
+		panic(err)
+	}
+
+	h := t.middlewares.Handler(pattern.Bind(route.Handler(handler), native.Aliases, route.NotFound(t.adaper.ServeNotFound)))
+	if err := t.dispatcher(full, native.Paths).Handle(method, h); err != nil {
+		panic(fmt.Errorf("%w: %s", err, pattern))
 	}
-	t.router.MethodFunc(method, path, t.adaper.AdaptHandler(wrappedHandler))
 }
 
-func (t *ChiTransport) Use(middlewares ...transport.Middleware) {
-	for _, mw := range middlewares {
-		t.router.Use(func(next http.Handler) http.Handler {
-			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				wrappedHandler := mw(func(req transport.Request, resp transport.Response) error {
-					next.ServeHTTP(w, r)
-					return nil
-				})
-
-				_ = wrappedHandler(&ChiRequest{req: r}, &ChiResponse{w: w})
-			})
-		})
+// dispatcher возвращает Dispatcher шаблона, регистрируя его в роутере для всех методов при первом обращении
+func (t *ChiTransport) dispatcher(full transport.NativePattern, paths []string) *transport.Dispatcher {
+	key := strings.Join(full.Paths, " ")
+	if d, ok := t.dispatchers[key]; ok {
+		return d
+	}
+
+	d := transport.NewDispatcher(t.middlewares)
+	t.dispatchers[key] = d
+
+	h := t.adaper.AdaptHandler(d.Serve)
+	for _, p := range paths {
+		t.router.HandleFunc(p, h)
+	}
+
+	return d
+}
+
+// NotFound задает handler для запросов, не совпавших ни с одним маршрутом.
+// Ответ проходит через глобальные middleware.
+func (t *ChiTransport) NotFound(handler transport.Handler) {
+	t.adaper.NotFound(handler)
+}
+
+// Routes возвращает таблицу зарегистрированных маршрутов
+func (t *ChiTransport) Routes() *transport.RouteTable {
+	return t.routes
+}
+
+// Group возвращает группу маршрутов под префиксом prefix на основе chi.Router.Route.
+// Группы с одинаковым префиксом используют общий подроутер, но собственные middleware.
+func (t *ChiTransport) Group(prefix string, middlewares ...transport.Middleware) transport.Transport {
+	_, native := transport.MustNativePattern(prefix, patternSyntax)
+
+	sub, ok := t.subrouters[native.Paths[0]]
+	if !ok {
+		sub = t.router.Route(native.Paths[0], func(chi.Router) {})
+		t.subrouters[native.Paths[0]] = sub
+	}
+
+	group := &ChiTransport{
+		router:      sub,
+		middlewares: transport.NewMiddlewareStack(t.middlewares, middlewares...),
+		adaper:      t.adaper,
+		routes:      transport.NewRouteTable(),
+		prefix:      transport.JoinPattern(t.prefix, prefix),
+		subrouters:  make(map[string]chi.Router),
+		dispatchers: t.dispatchers,
+	}
+	if err := t.routes.Mount(prefix, group.routes); err != nil {
+		panic(err)
+	}
+
+	return group
+}
+
+// Mount подключает транспорт sub под префиксом prefix с помощью chi.Router.Mount
+func (t *ChiTransport) Mount(prefix string, sub transport.Transport) {
+	if err := t.routes.Mount(prefix, sub.Routes()); err != nil {
+		panic(err)
+	}
+
+	_, native := transport.MustNativePattern(prefix, patternSyntax)
+
+	var h http.Handler
+	if s, ok := sub.(*ChiTransport); ok {
+		h = s.router
+	} else {
+		var err error
+		if h, err = transport.HandlerOf(sub); err != nil {
+			panic(err)
+		}
+
+		// chi не меняет путь запроса при монтировании, поэтому префикс удаляется явно
+		h = transport.StripPrefix(transport.JoinPattern(t.prefix, prefix), h)
 	}
+
+	// middleware родителя применяются и к маршрутам подключенного транспорта
+	t.router.Mount(native.Paths[0], t.adaper.AdaptHandler(t.middlewares.Handler(transporthttp.MountHandler(h))))
+}
+
+// ServeHTTP реализует http.Handler
+func (t *ChiTransport) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	t.router.ServeHTTP(w, r)
+}
+
+// Use добавляет глобальные middleware; они применяются и к маршрутам, зарегистрированным ранее
+func (t *ChiTransport) Use(middlewares ...transport.Middleware) {
+	t.middlewares.Use(middlewares...)
 }
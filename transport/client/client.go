@@ -0,0 +1,154 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-mosaic/runtime"
+	"github.com/go-mosaic/runtime/transport"
+)
+
+// ErrUnsupportedContentType тип тела ответа не поддерживается ни одним кодеком клиента
+var ErrUnsupportedContentType = errors.New("client: unsupported content type")
+
+// DoFunc выполняет HTTP запрос
+type DoFunc func(req *http.Request) (*http.Response, error)
+
+// Middleware клиентская middleware, например для авторизации, логирования или повторов
+type Middleware = runtime.Middleware[DoFunc]
+
+// Config конфигурация клиента
+type Config struct {
+	httpClient  *http.Client
+	codecs      []transport.Codec
+	middlewares []Middleware
+	headers     http.Header
+}
+
+// Option тип для функциональных опций
+type Option func(*Config)
+
+// WithHTTPClient задает http.Client, выполняющий запросы; по умолчанию http.DefaultClient
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Config) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithCodecs задает кодеки тела запроса и ответа; первый кодек используется по умолчанию.
+// По умолчанию используется transport.JSONCodec.
+func WithCodecs(codecs ...transport.Codec) Option {
+	return func(c *Config) {
+		c.codecs = codecs
+	}
+}
+
+// WithMiddleware добавляет middleware; первая middleware выполняется первой
+func WithMiddleware(middlewares ...Middleware) Option {
+	return func(c *Config) {
+		c.middlewares = append(c.middlewares, middlewares...)
+	}
+}
+
+// WithHeader добавляет заголовок ко всем запросам клиента
+func WithHeader(key, value string) Option {
+	return func(c *Config) {
+		c.headers.Add(key, value)
+	}
+}
+
+// Client выполняет запросы к HTTP API по шаблонам путей transport
+type Client struct {
+	baseURL *url.URL
+	do      DoFunc
+	codecs  []transport.Codec
+	headers http.Header
+}
+
+// New создает клиент для API с базовым адресом baseURL
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("client: invalid base url: %w", err)
+	}
+
+	config := Config{
+		httpClient: http.DefaultClient,
+		codecs:     []transport.Codec{transport.JSONCodec{}},
+		headers:    make(http.Header),
+	}
+
+	for _, applyOpt := range opts {
+		applyOpt(&config)
+	}
+
+	do := DoFunc(config.httpClient.Do)
+	if len(config.middlewares) > 0 {
+		do = runtime.MiddlewareChain(config.middlewares[0], config.middlewares[1:]...)(do)
+	}
+
+	return &Client{
+		baseURL: u,
+		do:      do,
+		codecs:  config.codecs,
+		headers: config.headers,
+	}, nil
+}
+
+// Do выполняет подготовленный запрос через цепочку middleware без декодирования ответа
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	return c.do(req)
+}
+
+// NewRequest создает запрос с методом method к пути по шаблону pattern, например "/users/{id}"
+func (c *Client) NewRequest(method, pattern string) *Request {
+	return &Request{
+		client:  c,
+		method:  method,
+		pattern: pattern,
+		params:  make(map[string]string),
+		query:   make(url.Values),
+		header:  make(http.Header),
+		targets: make(map[int]any),
+	}
+}
+
+// codecByType возвращает кодек для MIME типа; для типов со структурным суффиксом,
+// например application/problem+json, подходит кодек базового типа application/json
+func (c *Client) codecByType(ctype string) (transport.Codec, error) {
+	mediaType, _, err := mime.ParseMediaType(ctype)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedContentType, ctype)
+	}
+
+	for _, codec := range c.codecs {
+		if codec.ContentType() == mediaType {
+			return codec, nil
+		}
+	}
+
+	if i := strings.LastIndexByte(mediaType, '+'); i >= 0 {
+		base := mediaType[:strings.IndexByte(mediaType, '/')+1] + mediaType[i+1:]
+		for _, codec := range c.codecs {
+			if codec.ContentType() == base {
+				return codec, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedContentType, mediaType)
+}
+
+// accept возвращает значение заголовка Accept по типам кодеков клиента
+func (c *Client) accept() string {
+	ctypes := make([]string, len(c.codecs))
+	for i, codec := range c.codecs {
+		ctypes[i] = codec.ContentType()
+	}
+
+	return strings.Join(ctypes, ", ")
+}
@@ -0,0 +1,241 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-mosaic/runtime"
+	"github.com/go-mosaic/runtime/transport"
+	transporthttp "github.com/go-mosaic/runtime/transport/http"
+)
+
+type user struct {
+	ID     string `json:"id" xml:"id"`
+	Name   string `json:"name,omitempty" xml:"name,omitempty"`
+	IDs    string `json:"ids,omitempty" xml:"ids,omitempty"`
+	Token  string `json:"token,omitempty" xml:"token,omitempty"`
+	Cookie string `json:"cookie,omitempty" xml:"cookie,omitempty"`
+}
+
+type created struct {
+	user
+}
+
+func (created) StatusCode() int {
+	return http.StatusCreated
+}
+
+type conflict struct {
+	Reason string `json:"reason"`
+}
+
+func (conflict) StatusCode() int {
+	return http.StatusConflict
+}
+
+func newServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	tr := transporthttp.NewHTTPTransport(transport.WithCodecs(transport.JSONCodec{}, transport.XMLCodec{}))
+	tr.AddRoute(http.MethodGet, "/users/{id}", func(req transport.Request, resp transport.Response) error {
+		cookie, _ := req.Cookie("session")
+		resp.WriteData(req, user{
+			ID:     req.PathValue("id"),
+			IDs:    req.Queries().Get("ids"),
+			Token:  req.Header("Authorization"),
+			Cookie: cookie,
+		})
+
+		return nil
+	})
+	tr.AddRoute(http.MethodPost, "/users", func(req transport.Request, resp transport.Response) error {
+		var u user
+		if err := req.ReadData(&u); err != nil {
+			return err
+		}
+		if u.Name == "taken" {
+			resp.WriteData(req, conflict{Reason: "name is taken"})
+			return nil
+		}
+		resp.WriteData(req, created{u})
+
+		return nil
+	})
+	tr.AddRoute(http.MethodDelete, "/users/{id}", func(transport.Request, transport.Response) error {
+		return transport.NewProblem(http.StatusForbidden, "read only")
+	})
+
+	srv := httptest.NewServer(tr)
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestRequest(t *testing.T) {
+	srv := newServer(t)
+
+	auth := func(next DoFunc) DoFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			req.Header.Set("Authorization", "Bearer secret")
+			return next(req)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		opts       []Option
+		request    func(c *Client) *Request
+		wantStatus int
+		want       user
+		wantErr    error
+	}{
+		{
+			name: "path, query, header and cookie",
+			opts: []Option{WithMiddleware(auth)},
+			request: func(c *Client) *Request {
+				return c.NewRequest(http.MethodGet, "/users/{id}").
+					PathParam("id", "a b").
+					Query("ids", runtime.JoinInt([]int{1, 2, 3}, ",", 10)).
+					Cookie("session", "s1")
+			},
+			wantStatus: http.StatusOK,
+			want:       user{ID: "a b", IDs: "1,2,3", Token: "Bearer secret", Cookie: "s1"},
+		},
+		{
+			name: "json body",
+			request: func(c *Client) *Request {
+				return c.NewRequest(http.MethodPost, "/users").Body(user{ID: "1", Name: "bob"})
+			},
+			wantStatus: http.StatusCreated,
+			want:       user{ID: "1", Name: "bob"},
+		},
+		{
+			name: "xml body and response",
+			opts: []Option{WithCodecs(transport.XMLCodec{}, transport.JSONCodec{})},
+			request: func(c *Client) *Request {
+				return c.NewRequest(http.MethodPost, "/users").Body(user{ID: "2", Name: "alice"})
+			},
+			wantStatus: http.StatusCreated,
+			want:       user{ID: "2", Name: "alice"},
+		},
+		{
+			name: "problem details",
+			request: func(c *Client) *Request {
+				return c.NewRequest(http.MethodDelete, "/users/{id}").PathParam("id", "1")
+			},
+			wantStatus: http.StatusForbidden,
+			wantErr:    &transport.Problem{Status: http.StatusForbidden, Detail: "read only"},
+		},
+		{
+			name: "not found",
+			request: func(c *Client) *Request {
+				return c.NewRequest(http.MethodGet, "/missing")
+			},
+			wantStatus: http.StatusNotFound,
+			wantErr:    &transport.Problem{Status: http.StatusNotFound},
+		},
+		{
+			name: "missing path param",
+			request: func(c *Client) *Request {
+				return c.NewRequest(http.MethodGet, "/users/{id}")
+			},
+			wantErr: errors.New("missing parameter"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := New(srv.URL, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got user
+			resp, err := tt.request(c).Into(&got).Do(context.Background())
+			if tt.wantErr != nil {
+				var problem, wantProblem *transport.Problem
+				switch {
+				case err == nil:
+					t.Fatalf("Do() error = nil, want %v", tt.wantErr)
+				case errors.As(tt.wantErr, &wantProblem):
+					if !errors.As(err, &problem) || problem.Status != wantProblem.Status {
+						t.Fatalf("Do() error = %#v, want problem with status %d", err, wantProblem.Status)
+					}
+					if wantProblem.Detail != "" && problem.Detail != wantProblem.Detail {
+						t.Errorf("Do() problem detail = %q, want %q", problem.Detail, wantProblem.Detail)
+					}
+				case !strings.Contains(err.Error(), tt.wantErr.Error()):
+					t.Fatalf("Do() error = %v, want %v", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("Do() error = %v", err)
+			}
+
+			if tt.wantStatus != 0 && resp.StatusCode != tt.wantStatus {
+				t.Errorf("Do() status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if got != tt.want {
+				t.Errorf("Do() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestOn(t *testing.T) {
+	srv := newServer(t)
+
+	c, err := New(srv.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		ok  user
+		bad conflict
+	)
+	resp, err := c.NewRequest(http.MethodPost, "/users").
+		Body(user{ID: "3", Name: "taken"}).
+		Into(&ok).
+		On(http.StatusConflict, &bad).
+		Do(context.Background())
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if resp.StatusCode != http.StatusConflict || bad.Reason != "name is taken" || ok != (user{}) {
+		t.Errorf("Do() status = %d, conflict = %+v, user = %+v", resp.StatusCode, bad, ok)
+	}
+
+	got, err := Decode[user](context.Background(), c.NewRequest(http.MethodGet, "/users/{id}").PathParam("id", "7"))
+	if err != nil || got.ID != "7" {
+		t.Errorf("Decode() = %+v, %v", got, err)
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	srv := newServer(t)
+
+	var calls []string
+	trace := func(name string) Middleware {
+		return func(next DoFunc) DoFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, name)
+				return next(req)
+			}
+		}
+	}
+
+	c, err := New(srv.URL, WithMiddleware(trace("first"), trace("second")), WithMiddleware(trace("third")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.NewRequest(http.MethodGet, "/users/{id}").PathParam("id", "1").Do(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := strings.Join(calls, ","); got != "first,second,third" {
+		t.Errorf("middleware order = %s", got)
+	}
+}
@@ -0,0 +1,236 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-mosaic/runtime/transport"
+)
+
+// Request построитель запроса клиента
+type Request struct {
+	client      *Client
+	method      string
+	pattern     string
+	params      map[string]string
+	query       url.Values
+	header      http.Header
+	cookies     []*http.Cookie
+	body        any
+	contentType string
+	result      any
+	targets     map[int]any
+}
+
+// PathParam задает значение параметра пути шаблона
+func (r *Request) PathParam(name, value string) *Request {
+	r.params[name] = value
+
+	return r
+}
+
+// Query добавляет значения параметра строки запроса.
+// Списки и словари передаются одним значением, собранным через runtime.JoinInt, runtime.JoinKeyValString и т.п.
+func (r *Request) Query(name string, values ...string) *Request {
+	for _, v := range values {
+		r.query.Add(name, v)
+	}
+
+	return r
+}
+
+// Header добавляет заголовок запроса
+func (r *Request) Header(key, value string) *Request {
+	r.header.Add(key, value)
+
+	return r
+}
+
+// Cookie добавляет cookie запроса
+func (r *Request) Cookie(name, value string) *Request {
+	r.cookies = append(r.cookies, &http.Cookie{Name: name, Value: value})
+
+	return r
+}
+
+// Body задает тело запроса. Значение кодируется кодеком, выбранным по ContentType,
+// а io.Reader передается без изменений.
+func (r *Request) Body(body any) *Request {
+	r.body = body
+
+	return r
+}
+
+// ContentType задает тип тела запроса; по умолчанию используется тип первого кодека клиента
+func (r *Request) ContentType(ctype string) *Request {
+	r.contentType = ctype
+
+	return r
+}
+
+// Into задает значение, в которое декодируется тело успешного (2xx) ответа
+func (r *Request) Into(result any) *Request {
+	r.result = result
+
+	return r
+}
+
+// On задает значение, в которое декодируется тело ответа со статус кодом status.
+// Ответ с ошибкой, для статуса которого задано значение, не возвращается как ошибка.
+func (r *Request) On(status int, target any) *Request {
+	r.targets[status] = target
+
+	return r
+}
+
+// Build создает http.Request
+func (r *Request) Build(ctx context.Context) (*http.Request, error) {
+	path, err := transport.BuildPath(r.pattern, r.params)
+	if err != nil {
+		return nil, err
+	}
+
+	u := *r.client.baseURL
+	rawPath := strings.TrimSuffix(u.EscapedPath(), "/") + path
+	if u.Path, err = url.PathUnescape(rawPath); err != nil {
+		return nil, err
+	}
+	u.RawPath = rawPath
+
+	query := u.Query()
+	for name, values := range r.query {
+		query[name] = append(query[name], values...)
+	}
+	u.RawQuery = query.Encode()
+
+	body, ctype, err := r.encodeBody()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, r.method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, values := range r.client.headers {
+		req.Header[key] = append([]string(nil), values...)
+	}
+	for key, values := range r.header {
+		req.Header[key] = append(req.Header[key], values...)
+	}
+	if ctype != "" && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", ctype)
+	}
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", r.client.accept())
+	}
+	for _, c := range r.cookies {
+		req.AddCookie(c)
+	}
+
+	return req, nil
+}
+
+// encodeBody кодирует тело запроса и возвращает его вместе с Content-Type
+func (r *Request) encodeBody() (io.Reader, string, error) {
+	switch body := r.body.(type) {
+	case nil:
+		return nil, "", nil
+	case io.Reader:
+		return body, r.contentType, nil
+	}
+
+	codec := r.client.codecs[0]
+	if r.contentType != "" {
+		var err error
+		if codec, err = r.client.codecByType(r.contentType); err != nil {
+			return nil, "", err
+		}
+	}
+
+	data, err := codec.Marshal(r.body)
+	if err != nil {
+		return nil, "", fmt.Errorf("client: encode body: %w", err)
+	}
+
+	return bytes.NewReader(data), codec.ContentType(), nil
+}
+
+// Do выполняет запрос и декодирует ответ по статус коду: в значение, заданное через On,
+// в значение Into для успешного ответа, а ответ с ошибкой без заданного значения возвращается
+// как *transport.Problem. Тело ответа прочитано и закрыто.
+func (r *Request) Do(ctx context.Context) (*http.Response, error) {
+	req, err := r.Build(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp, err
+	}
+
+	target, ok := r.targets[resp.StatusCode]
+	switch {
+	case ok:
+		return resp, r.decode(resp, body, target)
+	case resp.StatusCode >= http.StatusBadRequest:
+		return resp, r.problem(resp, body)
+	case resp.StatusCode >= 200 && resp.StatusCode < 300 && r.result != nil:
+		return resp, r.decode(resp, body, r.result)
+	default:
+		return resp, nil
+	}
+}
+
+// decode декодирует тело ответа кодеком, выбранным по Content-Type ответа
+func (r *Request) decode(resp *http.Response, body []byte, target any) error {
+	if len(body) == 0 || target == nil {
+		return nil
+	}
+
+	codec := r.client.codecs[0]
+	if ctype := resp.Header.Get("Content-Type"); ctype != "" {
+		var err error
+		if codec, err = r.client.codecByType(ctype); err != nil {
+			return err
+		}
+	}
+
+	if err := codec.Unmarshal(body, target); err != nil {
+		return fmt.Errorf("client: decode response: %w", err)
+	}
+
+	return nil
+}
+
+// problem создает ошибку ответа: problem details из тела или Problem со статус кодом и текстом тела
+func (r *Request) problem(resp *http.Response, body []byte) error {
+	problem := &transport.Problem{}
+	if err := r.decode(resp, body, problem); err != nil || (problem.Title == "" && problem.Detail == "") {
+		problem = transport.NewProblem(resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	problem.Status = resp.StatusCode
+
+	return problem
+}
+
+// Decode выполняет запрос и возвращает тело успешного ответа, декодированное в значение типа T
+func Decode[T any](ctx context.Context, r *Request) (T, error) {
+	var result T
+	_, err := r.Into(&result).Do(ctx)
+
+	return result, err
+}
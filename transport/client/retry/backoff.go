@@ -0,0 +1,32 @@
+package retry
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff возвращает задержку перед повтором номер attempt, начиная с 1
+type Backoff func(attempt int) time.Duration
+
+// ConstantBackoff задержка d перед каждым повтором
+func ConstantBackoff(d time.Duration) Backoff {
+	return func(int) time.Duration {
+		return d
+	}
+}
+
+// ExponentialBackoff экспоненциальная задержка base*2^(attempt-1), ограниченная maxDelay,
+// со случайным разбросом на всю величину задержки (full jitter)
+func ExponentialBackoff(base, maxDelay time.Duration) Backoff {
+	return func(attempt int) time.Duration {
+		d := min(base, maxDelay)
+		for i := 1; i < attempt && d < maxDelay; i++ {
+			d = min(d, maxDelay/2) * 2
+		}
+		if d <= 0 {
+			return 0
+		}
+
+		return time.Duration(rand.Int64N(int64(d) + 1))
+	}
+}
@@ -0,0 +1,43 @@
+package retry
+
+import "sync"
+
+// Budget ограничивает долю повторов, чтобы повторы не усиливали нагрузку на недоступный сервис.
+// Каждая неудачная попытка списывает токен, каждая успешная возвращает ratio токена;
+// повторы разрешены, пока токенов больше половины максимума (как retry throttling в gRPC).
+type Budget struct {
+	mu        sync.Mutex
+	tokens    float64
+	maxTokens float64
+	ratio     float64
+}
+
+// NewBudget создает бюджет повторов с maxTokens токенами, ratio задает долю токена, возвращаемую успешной попыткой
+func NewBudget(maxTokens int, ratio float64) *Budget {
+	return &Budget{
+		tokens:    float64(maxTokens),
+		maxTokens: float64(maxTokens),
+		ratio:     ratio,
+	}
+}
+
+func (b *Budget) onSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = min(b.tokens+b.ratio, b.maxTokens)
+}
+
+func (b *Budget) onFailure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = max(b.tokens-1, 0)
+}
+
+func (b *Budget) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.tokens > b.maxTokens/2
+}
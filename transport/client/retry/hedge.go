@@ -0,0 +1,157 @@
+package retry
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/go-mosaic/runtime/transport/client"
+)
+
+const (
+	latencyWindowSize = 128
+	// minLatencySamples количество ответов, после которого перцентиль задержки считается достоверным
+	minLatencySamples = 10
+)
+
+type hedging struct {
+	percentile float64
+	maxHedges  int
+	latencies  *latencyWindow
+}
+
+// latencyWindow хранит задержки последних ответов для расчета перцентиля
+type latencyWindow struct {
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int
+}
+
+func newLatencyWindow(size int) *latencyWindow {
+	return &latencyWindow{samples: make([]time.Duration, 0, size)}
+}
+
+func (w *latencyWindow) add(d time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.samples) < cap(w.samples) {
+		w.samples = append(w.samples, d)
+		return
+	}
+	w.samples[w.next] = d
+	w.next = (w.next + 1) % len(w.samples)
+}
+
+// percentile возвращает перцентиль p (0..1) задержек или false, если ответов недостаточно
+func (w *latencyWindow) percentile(p float64) (time.Duration, bool) {
+	w.mu.Lock()
+	samples := slices.Clone(w.samples)
+	w.mu.Unlock()
+
+	if len(samples) < minLatencySamples {
+		return 0, false
+	}
+
+	slices.Sort(samples)
+	i := int(p * float64(len(samples)-1))
+
+	return samples[min(max(i, 0), len(samples)-1)], true
+}
+
+type hedgeResult struct {
+	resp  *http.Response
+	err   error
+	index int
+}
+
+// hedge выполняет запрос и, если ответ задерживается дольше перцентиля задержек, отправляет дополнительные запросы.
+// Возвращается первый ответ, не требующий повтора; остальные запросы отменяются.
+func (c *Config) hedge(next client.DoFunc, req *http.Request) (*http.Response, error) {
+	delay, ok := c.hedging.latencies.percentile(c.hedging.percentile)
+	if !ok || c.hedging.maxHedges <= 0 {
+		return c.attempt(next, req)
+	}
+
+	results := make(chan hedgeResult, c.hedging.maxHedges+1)
+	cancels := make([]context.CancelFunc, 0, c.hedging.maxHedges+1)
+	launch := func(r *http.Request) {
+		ctx, cancel := context.WithCancel(r.Context())
+		index := len(cancels)
+		cancels = append(cancels, cancel)
+		go func() {
+			resp, err := c.attempt(next, r.WithContext(ctx))
+			results <- hedgeResult{resp: resp, err: err, index: index}
+		}()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	launch(req)
+	pending := 1
+
+	last := hedgeResult{index: -1}
+	for pending > 0 {
+		select {
+		case <-timer.C:
+			if r, err := rewind(req); err == nil {
+				pending++
+				if c.collector != nil {
+					c.collector.RecordCall(c.operation + ".hedge")
+				}
+				launch(r)
+			}
+			if len(cancels) <= c.hedging.maxHedges {
+				timer.Reset(delay)
+			}
+		case res := <-results:
+			pending--
+			if last.index >= 0 {
+				discard(last.resp)
+				cancels[last.index]()
+			}
+			last = res
+
+			if !c.condition(res.resp, res.err) {
+				// запросы, которые еще выполняются, отменяются, а их ответы закрываются
+				for i, cancel := range cancels {
+					if i != res.index {
+						cancel()
+					}
+				}
+				go func(n int) {
+					for range n {
+						discard((<-results).resp)
+					}
+				}(pending)
+				pending = 0
+			}
+		}
+	}
+
+	if last.resp != nil {
+		// контекст попытки отменяется после закрытия тела ответа
+		last.resp.Body = &cancelBody{ReadCloser: last.resp.Body, cancel: cancels[last.index]}
+	} else {
+		cancels[last.index]()
+	}
+
+	return last.resp, last.err
+}
+
+// cancelBody отменяет контекст попытки при закрытии тела ответа
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+
+	return err
+}
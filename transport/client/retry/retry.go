@@ -0,0 +1,295 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/go-mosaic/runtime/log"
+	"github.com/go-mosaic/runtime/transport"
+	"github.com/go-mosaic/runtime/transport/client"
+)
+
+// Condition решает, нужно ли повторить попытку с результатом resp, err
+type Condition func(resp *http.Response, err error) bool
+
+// DefaultCondition повторяет попытку при сетевой ошибке, кроме отмены контекста,
+// и при статусах 429, 502, 503 и 504
+func DefaultCondition(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// Config конфигурация middleware
+type Config struct {
+	maxAttempts   int
+	backoff       Backoff
+	condition     Condition
+	methods       []string
+	maxRetryAfter time.Duration
+	budget        *Budget
+	hedging       *hedging
+	collector     log.MetricsCollector
+	operation     string
+}
+
+// Option тип для функциональных опций
+type Option func(*Config)
+
+// WithMaxAttempts задает максимальное количество попыток, включая первую; по умолчанию 3
+func WithMaxAttempts(n int) Option {
+	return func(c *Config) {
+		c.maxAttempts = n
+	}
+}
+
+// WithBackoff задает задержку между попытками, по умолчанию ExponentialBackoff(100ms, 5s)
+func WithBackoff(backoff Backoff) Option {
+	return func(c *Config) {
+		c.backoff = backoff
+	}
+}
+
+// WithCondition задает условие повтора, по умолчанию DefaultCondition
+func WithCondition(condition Condition) Option {
+	return func(c *Config) {
+		c.condition = condition
+	}
+}
+
+// WithMethods задает идемпотентные методы, запросы которых повторяются.
+// По умолчанию GET, HEAD, OPTIONS, TRACE, PUT и DELETE; запросы остальных методов
+// повторяются только с заголовком Idempotency-Key.
+func WithMethods(methods ...string) Option {
+	return func(c *Config) {
+		c.methods = methods
+	}
+}
+
+// WithMaxRetryAfter задает максимальную задержку из заголовка Retry-After, по умолчанию 1 минута.
+// Если сервер просит подождать дольше, попытка не повторяется.
+func WithMaxRetryAfter(d time.Duration) Option {
+	return func(c *Config) {
+		c.maxRetryAfter = d
+	}
+}
+
+// WithBudget задает бюджет повторов; бюджет разделяется всеми запросами middleware
+func WithBudget(budget *Budget) Option {
+	return func(c *Config) {
+		c.budget = budget
+	}
+}
+
+// WithHedging включает hedging: если ответ не получен за время, равное перцентилю percentile (0..1)
+// задержек предыдущих ответов, отправляется до maxHedges дополнительных запросов и используется первый
+// успешный ответ. Применяется только к запросам, которые можно повторить.
+func WithHedging(percentile float64, maxHedges int) Option {
+	return func(c *Config) {
+		c.hedging = &hedging{
+			percentile: percentile,
+			maxHedges:  maxHedges,
+			latencies:  newLatencyWindow(latencyWindowSize),
+		}
+	}
+}
+
+// WithMetricsCollector задает сборщик метрик попыток.
+// Каждая попытка учитывается как вызов операции, повторы и hedge запросы дополнительно
+// учитываются как вызовы операций с суффиксами ".retry" и ".hedge".
+func WithMetricsCollector(collector log.MetricsCollector) Option {
+	return func(c *Config) {
+		c.collector = collector
+	}
+}
+
+// WithOperation задает имя операции в метриках
+func WithOperation(operation string) Option {
+	return func(c *Config) {
+		c.operation = operation
+	}
+}
+
+// Middleware создает client.Middleware, повторяющий неудачные запросы
+func Middleware(opts ...Option) client.Middleware {
+	config := Config{
+		maxAttempts: 3,
+		backoff:     ExponentialBackoff(100*time.Millisecond, 5*time.Second),
+		condition:   DefaultCondition,
+		methods: []string{
+			http.MethodGet, http.MethodHead, http.MethodOptions,
+			http.MethodTrace, http.MethodPut, http.MethodDelete,
+		},
+		maxRetryAfter: time.Minute,
+		operation:     "client.retry",
+	}
+
+	for _, applyOpt := range opts {
+		applyOpt(&config)
+	}
+
+	return func(next client.DoFunc) client.DoFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			if !config.retryable(req) {
+				return config.attempt(next, req)
+			}
+
+			for attempt := 1; ; attempt++ {
+				resp, err := config.do(next, req)
+				if !config.condition(resp, err) {
+					if config.budget != nil {
+						config.budget.onSuccess()
+					}
+					return resp, err
+				}
+				if config.budget != nil {
+					config.budget.onFailure()
+				}
+
+				if attempt >= config.maxAttempts || (config.budget != nil && !config.budget.allow()) {
+					return resp, err
+				}
+
+				delay := config.backoff(attempt)
+				if retryAfter, ok := parseRetryAfter(resp); ok {
+					if retryAfter > config.maxRetryAfter {
+						return resp, err
+					}
+					delay = max(delay, retryAfter)
+				}
+
+				retryReq, rewindErr := rewind(req)
+				if rewindErr != nil {
+					return resp, err
+				}
+				discard(resp)
+
+				if err := sleep(req.Context(), delay); err != nil {
+					return nil, err
+				}
+				if config.collector != nil {
+					config.collector.RecordCall(config.operation + ".retry")
+				}
+				req = retryReq
+			}
+		}
+	}
+}
+
+// retryable проверяет, можно ли повторить запрос: метод идемпотентен или задан Idempotency-Key,
+// а тело запроса можно прочитать повторно
+func (c *Config) retryable(req *http.Request) bool {
+	if !slices.Contains(c.methods, req.Method) && req.Header.Get(transport.HeaderIdempotencyKey) == "" {
+		return false
+	}
+
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// do выполняет попытку, с hedging если он включен
+func (c *Config) do(next client.DoFunc, req *http.Request) (*http.Response, error) {
+	if c.hedging != nil {
+		return c.hedge(next, req)
+	}
+
+	return c.attempt(next, req)
+}
+
+// attempt выполняет одну попытку и записывает ее метрики
+func (c *Config) attempt(next client.DoFunc, req *http.Request) (*http.Response, error) {
+	if c.collector != nil {
+		c.collector.RecordCall(c.operation)
+	}
+
+	start := time.Now()
+	resp, err := next(req)
+	duration := time.Since(start)
+
+	if err == nil && c.hedging != nil {
+		c.hedging.latencies.add(duration)
+	}
+
+	if c.collector != nil {
+		if err != nil || c.condition(resp, err) {
+			c.collector.RecordError(c.operation, duration)
+		} else {
+			c.collector.RecordSuccess(c.operation, duration)
+		}
+	}
+
+	return resp, err
+}
+
+// rewind создает копию запроса с телом, прочитанным заново через GetBody
+func rewind(req *http.Request) (*http.Request, error) {
+	r := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		r.Body = body
+	}
+
+	return r, nil
+}
+
+// discard дочитывает и закрывает тело ответа, чтобы соединение можно было переиспользовать
+func discard(resp *http.Response) {
+	if resp == nil {
+		return
+	}
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	_ = resp.Body.Close()
+}
+
+// parseRetryAfter разбирает заголовок Retry-After в секундах или в формате HTTP даты
+func parseRetryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(value); err == nil {
+		return max(time.Until(t), 0), true
+	}
+
+	return 0, false
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
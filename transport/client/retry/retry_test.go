@@ -0,0 +1,254 @@
+package retry
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-mosaic/runtime/transport"
+	"github.com/go-mosaic/runtime/transport/client"
+)
+
+type countingCollector struct {
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (c *countingCollector) RecordSuccess(string, time.Duration) {}
+func (c *countingCollector) RecordError(string, time.Duration)   {}
+func (c *countingCollector) RecordCall(operation string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.calls == nil {
+		c.calls = make(map[string]int)
+	}
+	c.calls[operation]++
+}
+
+// flakyServer отвечает статусом status на первые failures запросов, а затем 200 с телом запроса
+func flakyServer(t *testing.T, failures int32, status int, header http.Header) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if calls.Add(1) <= failures {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(status)
+			return
+		}
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, &calls
+}
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		failures   int32
+		status     int
+		header     http.Header
+		method     string
+		reqHeader  map[string]string
+		opts       []Option
+		wantCalls  int32
+		wantStatus int
+	}{
+		{
+			name: "retries until success", failures: 2, status: http.StatusServiceUnavailable,
+			method: http.MethodGet, wantCalls: 3, wantStatus: http.StatusOK,
+		},
+		{
+			name: "gives up after max attempts", failures: 5, status: http.StatusBadGateway,
+			method: http.MethodGet, wantCalls: 3, wantStatus: http.StatusBadGateway,
+		},
+		{
+			name: "client error is not retried", failures: 1, status: http.StatusBadRequest,
+			method: http.MethodGet, wantCalls: 1, wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "post is not retried", failures: 1, status: http.StatusServiceUnavailable,
+			method: http.MethodPost, wantCalls: 1, wantStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name: "post with idempotency key is retried", failures: 1, status: http.StatusServiceUnavailable,
+			method: http.MethodPost, reqHeader: map[string]string{transport.HeaderIdempotencyKey: "k1"},
+			wantCalls: 2, wantStatus: http.StatusOK,
+		},
+		{
+			name: "retry after above limit", failures: 1, status: http.StatusTooManyRequests,
+			header: http.Header{"Retry-After": {"120"}},
+			method: http.MethodGet, wantCalls: 1, wantStatus: http.StatusTooManyRequests,
+		},
+		{
+			name: "retry after within limit", failures: 1, status: http.StatusTooManyRequests,
+			header: http.Header{"Retry-After": {"0"}},
+			method: http.MethodGet, wantCalls: 2, wantStatus: http.StatusOK,
+		},
+		{
+			name: "exhausted budget", failures: 5, status: http.StatusServiceUnavailable,
+			method: http.MethodGet, opts: []Option{WithBudget(NewBudget(2, 0.1))},
+			wantCalls: 1, wantStatus: http.StatusServiceUnavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, calls := flakyServer(t, tt.failures, tt.status, tt.header)
+
+			opts := append([]Option{WithBackoff(ConstantBackoff(time.Millisecond))}, tt.opts...)
+			c, err := client.New(srv.URL, client.WithMiddleware(Middleware(opts...)))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := c.NewRequest(tt.method, "/").Body(strings.NewReader("payload"))
+			for k, v := range tt.reqHeader {
+				req.Header(k, v)
+			}
+			httpReq, err := req.Build(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := c.Do(httpReq)
+			if err != nil {
+				t.Fatalf("Do() error = %v", err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			_ = resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("Do() status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("server calls = %d, want %d", got, tt.wantCalls)
+			}
+			if resp.StatusCode == http.StatusOK && string(body) != "payload" {
+				t.Errorf("Do() body = %q, want replayed payload", body)
+			}
+		})
+	}
+}
+
+func TestHedging(t *testing.T) {
+	var slow atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if slow.CompareAndSwap(true, false) {
+			<-r.Context().Done()
+			return
+		}
+		_, _ = io.WriteString(w, "ok")
+	}))
+	t.Cleanup(srv.Close)
+
+	collector := &countingCollector{}
+	c, err := client.New(srv.URL, client.WithMiddleware(Middleware(
+		WithHedging(0.9, 1),
+		WithMetricsCollector(collector),
+	)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	get := func() (string, error) {
+		resp, err := c.NewRequest(http.MethodGet, "/").Build(context.Background())
+		if err != nil {
+			return "", err
+		}
+		r, err := c.Do(resp)
+		if err != nil {
+			return "", err
+		}
+		defer r.Body.Close()
+		body, err := io.ReadAll(r.Body)
+
+		return string(body), err
+	}
+
+	for range minLatencySamples {
+		if _, err := get(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	slow.Store(true)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if body, err := get(); err != nil || body != "ok" {
+			t.Errorf("hedged request = %q, %v", body, err)
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("hedged request did not complete")
+	}
+
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+	if got := collector.calls["client.retry.hedge"]; got != 1 {
+		t.Errorf("hedge calls = %d, want 1", got)
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(10*time.Millisecond, 50*time.Millisecond)
+
+	tests := []struct {
+		attempt int
+		limit   time.Duration
+	}{
+		{attempt: 1, limit: 10 * time.Millisecond},
+		{attempt: 2, limit: 20 * time.Millisecond},
+		{attempt: 3, limit: 40 * time.Millisecond},
+		{attempt: 4, limit: 50 * time.Millisecond},
+		{attempt: 100, limit: 50 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		for range 100 {
+			if d := backoff(tt.attempt); d < 0 || d > tt.limit {
+				t.Fatalf("backoff(%d) = %v, want within [0, %v]", tt.attempt, d, tt.limit)
+			}
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{name: "seconds", value: "3", want: 3 * time.Second, wantOK: true},
+		{name: "past date", value: "Wed, 21 Oct 2015 07:28:00 GMT", want: 0, wantOK: true},
+		{name: "empty", value: ""},
+		{name: "invalid", value: "soon"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{Header: http.Header{}}
+			if tt.value != "" {
+				resp.Header.Set("Retry-After", tt.value)
+			}
+
+			got, ok := parseRetryAfter(resp)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("parseRetryAfter() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
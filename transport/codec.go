@@ -0,0 +1,161 @@
+package transport
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/a-h/templ"
+	"github.com/aohorodnyk/mimeheader"
+)
+
+// Codec кодирует и декодирует тело запроса и ответа одного MIME типа
+type Codec interface {
+	ContentType() string
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// JSONCodec кодек application/json на основе encoding/json
+type JSONCodec struct{}
+
+func (JSONCodec) ContentType() string {
+	return "application/json"
+}
+
+func (JSONCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (JSONCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+// XMLCodec кодек application/xml на основе encoding/xml
+type XMLCodec struct{}
+
+func (XMLCodec) ContentType() string {
+	return "application/xml"
+}
+
+func (XMLCodec) Marshal(v any) ([]byte, error) {
+	return xml.Marshal(v)
+}
+
+func (XMLCodec) Unmarshal(data []byte, v any) error {
+	return xml.Unmarshal(data, v)
+}
+
+// NewCodecWriteResponse создает WriteResponse, кодирующий данные кодеком, выбранным по заголовку Accept.
+// Без заголовка Accept используется первый кодек. Если ни один кодек не подходит,
+// а также для ByteReader и templ.Component ответ записывается DefaultWriteResponse.
+func NewCodecWriteResponse(codecs ...Codec) WriteResponse {
+	if len(codecs) == 0 {
+		return DefaultWriteResponse
+	}
+
+	ctypes := make([]string, len(codecs))
+	byType := make(map[string]Codec, len(codecs))
+	for i, c := range codecs {
+		ctypes[i] = c.ContentType()
+		byType[c.ContentType()] = c
+	}
+
+	return func(req Request, resp Response, data any) {
+		switch data.(type) {
+		case ByteReader, templ.Component:
+			DefaultWriteResponse(req, resp, data)
+			return
+		}
+
+		accept := req.Header("Accept")
+		_, mimeType, matched := mimeheader.ParseAcceptHeader(accept).Negotiate(ctypes, ctypes[0])
+		if !matched && accept != "" {
+			DefaultWriteResponse(req, resp, data)
+			return
+		}
+
+		if data == nil || data == struct{}{} {
+			handleNonDataResponse(resp)
+			return
+		}
+
+		if headerer, ok := data.(Headerer); ok {
+			setHeaders(resp, headerer.Headers())
+		}
+
+		body, err := byType[mimeType].Marshal(data)
+		if err != nil {
+			writeFailure(resp, err)
+			return
+		}
+		resp.SetHeader("Content-Type", mimeType)
+		resp.SetBody(body, determineStatusCode(data))
+	}
+}
+
+// DefaultMaxBodySize максимальный размер тела запроса, декодируемого NewCodecReadData
+const DefaultMaxBodySize = 4 << 20
+
+// NewCodecReadData создает ReadData, декодирующий тело кодеком, выбранным по заголовку Content-Type.
+// Без заголовка Content-Type используется первый кодек; для неподдерживаемого типа возвращается Problem 415.
+// Размер тела ограничен DefaultMaxBodySize.
+func NewCodecReadData(codecs ...Codec) ReadData {
+	return NewLimitedCodecReadData(DefaultMaxBodySize, codecs...)
+}
+
+// NewLimitedCodecReadData создает ReadData как NewCodecReadData с ограничением размера тела maxBodySize.
+// Для тела большего размера возвращается Problem 413; maxBodySize <= 0 снимает ограничение.
+func NewLimitedCodecReadData(maxBodySize int64, codecs ...Codec) ReadData {
+	if len(codecs) == 0 {
+		return DefaultReadData
+	}
+
+	return func(req Request, data any) error {
+		codec := codecs[0]
+
+		if ctype := req.Header("Content-Type"); ctype != "" {
+			mediaType, _, err := mime.ParseMediaType(ctype)
+			if err != nil {
+				return NewProblem(http.StatusUnsupportedMediaType, err.Error())
+			}
+
+			codec = nil
+			for _, c := range codecs {
+				if c.ContentType() == mediaType {
+					codec = c
+					break
+				}
+			}
+			if codec == nil {
+				return NewProblem(http.StatusUnsupportedMediaType, "unsupported content type "+mediaType)
+			}
+		}
+
+		body, err := readBody(req.Body(), maxBodySize)
+		if err != nil {
+			return err
+		}
+
+		return codec.Unmarshal(body, data)
+	}
+}
+
+// readBody читает тело не длиннее maxBodySize байт
+func readBody(body io.Reader, maxBodySize int64) ([]byte, error) {
+	if maxBodySize <= 0 {
+		return io.ReadAll(body)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, maxBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxBodySize {
+		return nil, NewProblem(http.StatusRequestEntityTooLarge, "request body is too large")
+	}
+
+	return data, nil
+}
@@ -0,0 +1,274 @@
+package concurrency
+
+import (
+	"math"
+	"time"
+)
+
+// Sample результат выполнения одного запроса
+type Sample struct {
+	RTT      time.Duration
+	Inflight int
+	Dropped  bool
+}
+
+// Algorithm алгоритм вычисления лимита одновременных запросов.
+// Методы вызываются Limiter под блокировкой, реализации могут не заботиться о синхронизации.
+type Algorithm interface {
+	Limit() int
+	Update(sample Sample)
+}
+
+// FixedLimit постоянный лимит
+type FixedLimit int
+
+func (l FixedLimit) Limit() int {
+	return int(l)
+}
+
+func (l FixedLimit) Update(Sample) {}
+
+const (
+	defaultBackoff   = 0.9
+	defaultTolerance = 1.5
+	defaultSmoothing = 0.2
+	minGradient      = 0.5
+	shortWindow      = 10
+	longWindow       = 600
+	driftRatio       = 2
+	driftDecay       = 0.95
+	vegasAlpha       = 3
+	vegasBeta        = 6
+)
+
+// AlgorithmOption функциональная опция алгоритма
+type AlgorithmOption func(*algorithmConfig)
+
+type algorithmConfig struct {
+	backoff   float64
+	timeout   time.Duration
+	tolerance float64
+	smoothing float64
+}
+
+// WithBackoff задает коэффициент уменьшения лимита при потерях для AIMD, по умолчанию 0.9
+func WithBackoff(ratio float64) AlgorithmOption {
+	return func(c *algorithmConfig) {
+		c.backoff = ratio
+	}
+}
+
+// WithTimeout задает время ответа, после которого запрос считается потерянным для AIMD
+func WithTimeout(d time.Duration) AlgorithmOption {
+	return func(c *algorithmConfig) {
+		c.timeout = d
+	}
+}
+
+// WithTolerance задает допустимый рост времени ответа относительно базового для Gradient, по умолчанию 1.5
+func WithTolerance(tolerance float64) AlgorithmOption {
+	return func(c *algorithmConfig) {
+		c.tolerance = tolerance
+	}
+}
+
+// WithSmoothing задает коэффициент сглаживания изменений лимита для Gradient (по умолчанию 0.2) и Vegas (по умолчанию 1)
+func WithSmoothing(smoothing float64) AlgorithmOption {
+	return func(c *algorithmConfig) {
+		c.smoothing = smoothing
+	}
+}
+
+func newAlgorithmConfig(config algorithmConfig, opts []AlgorithmOption) algorithmConfig {
+	for _, applyOpt := range opts {
+		applyOpt(&config)
+	}
+
+	return config
+}
+
+// AIMD увеличивает лимит на единицу при успешных запросах и уменьшает мультипликативно при потерях
+type AIMD struct {
+	limit    float64
+	minLimit int
+	maxLimit int
+	backoff  float64
+	timeout  time.Duration
+}
+
+// NewAIMD создает алгоритм AIMD с начальным лимитом initial в границах [minLimit, maxLimit]
+func NewAIMD(initial, minLimit, maxLimit int, opts ...AlgorithmOption) *AIMD {
+	minLimit, maxLimit = limitBounds(minLimit, maxLimit)
+	config := newAlgorithmConfig(algorithmConfig{backoff: defaultBackoff}, opts)
+
+	return &AIMD{
+		limit:    clamp(float64(initial), minLimit, maxLimit),
+		minLimit: minLimit,
+		maxLimit: maxLimit,
+		backoff:  config.backoff,
+		timeout:  config.timeout,
+	}
+}
+
+func (a *AIMD) Limit() int {
+	return int(a.limit)
+}
+
+func (a *AIMD) Update(s Sample) {
+	switch {
+	case s.Dropped || (a.timeout > 0 && s.RTT > a.timeout):
+		a.limit = clamp(a.limit*a.backoff, a.minLimit, a.maxLimit)
+	case float64(s.Inflight*2) >= a.limit:
+		a.limit = clamp(a.limit+1, a.minLimit, a.maxLimit)
+	}
+}
+
+// Gradient подстраивает лимит по отношению долгосрочного и краткосрочного времени ответа
+type Gradient struct {
+	limit     float64
+	minLimit  int
+	maxLimit  int
+	tolerance float64
+	smoothing float64
+	shortRTT  ewma
+	longRTT   ewma
+}
+
+// NewGradient создает градиентный алгоритм с начальным лимитом initial в границах [minLimit, maxLimit]
+func NewGradient(initial, minLimit, maxLimit int, opts ...AlgorithmOption) *Gradient {
+	minLimit, maxLimit = limitBounds(minLimit, maxLimit)
+	config := newAlgorithmConfig(algorithmConfig{tolerance: defaultTolerance, smoothing: defaultSmoothing}, opts)
+
+	return &Gradient{
+		limit:     clamp(float64(initial), minLimit, maxLimit),
+		minLimit:  minLimit,
+		maxLimit:  maxLimit,
+		tolerance: config.tolerance,
+		smoothing: config.smoothing,
+		shortRTT:  newEWMA(shortWindow),
+		longRTT:   newEWMA(longWindow),
+	}
+}
+
+func (g *Gradient) Limit() int {
+	return int(g.limit)
+}
+
+func (g *Gradient) Update(s Sample) {
+	if s.Dropped {
+		g.limit = clamp(g.limit*defaultBackoff, g.minLimit, g.maxLimit)
+		return
+	}
+
+	if s.RTT <= 0 {
+		return
+	}
+
+	rtt := float64(s.RTT)
+	short := g.shortRTT.add(rtt)
+	long := g.longRTT.add(rtt)
+
+	// Долгосрочное значение быстро догоняет краткосрочное после устойчивой деградации
+	if long/short > driftRatio {
+		g.longRTT.value *= driftDecay
+	}
+
+	// Приложение не нагружено, нет смысла менять лимит
+	if float64(s.Inflight) < g.limit/2 {
+		return
+	}
+
+	gradient := math.Max(minGradient, math.Min(1, g.tolerance*long/short))
+	newLimit := g.limit*gradient + math.Sqrt(g.limit)
+	g.limit = clamp(g.limit*(1-g.smoothing)+newLimit*g.smoothing, g.minLimit, g.maxLimit)
+}
+
+// Vegas оценивает размер очереди по разнице между минимальным и текущим временем ответа
+type Vegas struct {
+	limit     float64
+	minLimit  int
+	maxLimit  int
+	smoothing float64
+	rttNoLoad time.Duration
+}
+
+// NewVegas создает алгоритм Vegas с начальным лимитом initial в границах [minLimit, maxLimit]
+func NewVegas(initial, minLimit, maxLimit int, opts ...AlgorithmOption) *Vegas {
+	minLimit, maxLimit = limitBounds(minLimit, maxLimit)
+	config := newAlgorithmConfig(algorithmConfig{smoothing: 1}, opts)
+
+	return &Vegas{
+		limit:     clamp(float64(initial), minLimit, maxLimit),
+		minLimit:  minLimit,
+		maxLimit:  maxLimit,
+		smoothing: config.smoothing,
+	}
+}
+
+func (v *Vegas) Limit() int {
+	return int(v.limit)
+}
+
+func (v *Vegas) Update(s Sample) {
+	if s.RTT <= 0 {
+		return
+	}
+
+	if v.rttNoLoad == 0 || s.RTT < v.rttNoLoad {
+		v.rttNoLoad = s.RTT
+		return
+	}
+
+	logLimit := math.Max(1, math.Log10(v.limit))
+	alpha := vegasAlpha * logLimit
+	beta := vegasBeta * logLimit
+
+	var newLimit float64
+	queue := math.Ceil(v.limit * (1 - float64(v.rttNoLoad)/float64(s.RTT)))
+
+	switch {
+	case s.Dropped:
+		newLimit = v.limit - beta
+	case queue <= logLimit:
+		newLimit = v.limit + beta
+	case queue < alpha:
+		newLimit = v.limit + logLimit
+	case queue > beta:
+		newLimit = v.limit - logLimit
+	default:
+		return
+	}
+
+	v.limit = clamp(v.limit*(1-v.smoothing)+newLimit*v.smoothing, v.minLimit, v.maxLimit)
+}
+
+type ewma struct {
+	alpha float64
+	value float64
+}
+
+func newEWMA(window int) ewma {
+	return ewma{alpha: 2 / float64(window+1)}
+}
+
+func (e *ewma) add(v float64) float64 {
+	if e.value == 0 {
+		e.value = v
+	} else {
+		e.value = e.value*(1-e.alpha) + v*e.alpha
+	}
+
+	return e.value
+}
+
+// limitBounds ограничивает минимальный лимит единицей, иначе при нулевом лимите запросы ждут бесконечно,
+// а максимальный — минимальным
+func limitBounds(minLimit, maxLimit int) (int, int) {
+	minLimit = max(minLimit, 1)
+
+	return minLimit, max(maxLimit, minLimit)
+}
+
+func clamp(v float64, minLimit, maxLimit int) float64 {
+	return math.Max(float64(minLimit), math.Min(float64(maxLimit), v))
+}
@@ -0,0 +1,138 @@
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-mosaic/runtime/log"
+	"github.com/go-mosaic/runtime/transport"
+)
+
+// PriorityFunc определяет класс приоритета запроса
+type PriorityFunc func(req transport.Request) Priority
+
+// PriorityByHeader определяет приоритет по значению заголовка, неизвестные значения получают PriorityNormal
+func PriorityByHeader(name string, classes map[string]Priority) PriorityFunc {
+	return func(req transport.Request) Priority {
+		if p, ok := classes[req.Header(name)]; ok {
+			return p
+		}
+
+		return PriorityNormal
+	}
+}
+
+// Config конфигурация middleware
+type Config struct {
+	priorityFunc PriorityFunc
+	retryAfter   time.Duration
+	collector    log.MetricsCollector
+	operation    string
+}
+
+// Option тип для функциональных опций
+type Option func(*Config)
+
+// WithPriorityFunc задает функцию определения приоритета запроса
+func WithPriorityFunc(priorityFunc PriorityFunc) Option {
+	return func(c *Config) {
+		c.priorityFunc = priorityFunc
+	}
+}
+
+// WithRetryAfter задает значение заголовка Retry-After при отказе, по умолчанию 1 секунда
+func WithRetryAfter(d time.Duration) Option {
+	return func(c *Config) {
+		c.retryAfter = d
+	}
+}
+
+// WithMetricsCollector задает сборщик метрик.
+// Если сборщик реализует log.GaugeCollector, в него записываются текущий лимит и количество выполняемых запросов.
+func WithMetricsCollector(collector log.MetricsCollector) Option {
+	return func(c *Config) {
+		c.collector = collector
+	}
+}
+
+// WithOperation задает имя операции в метриках
+func WithOperation(operation string) Option {
+	return func(c *Config) {
+		c.operation = operation
+	}
+}
+
+// Middleware создает transport.Middleware, ограничивающий количество одновременных запросов.
+// Запросы сверх лимита ждут в очереди limiter, а при ее переполнении или истечении ожидания получают 503.
+func Middleware(limiter *Limiter, opts ...Option) transport.Middleware {
+	config := Config{
+		priorityFunc: func(transport.Request) Priority { return PriorityNormal },
+		retryAfter:   time.Second,
+		operation:    "concurrency",
+	}
+
+	for _, applyOpt := range opts {
+		applyOpt(&config)
+	}
+
+	return func(next transport.Handler) transport.Handler {
+		return func(req transport.Request, resp transport.Response) error {
+			if config.collector != nil {
+				config.collector.RecordCall(config.operation)
+			}
+
+			start := time.Now()
+			token, err := limiter.Acquire(req.Context(), config.priorityFunc(req))
+			if err != nil {
+				if config.collector != nil {
+					config.collector.RecordError(config.operation, time.Since(start))
+				}
+				if !errors.Is(err, ErrLimitExceeded) {
+					return err
+				}
+
+				problem := transport.NewProblem(http.StatusServiceUnavailable, "server is overloaded").
+					WithHeader("Retry-After", strconv.Itoa(int(math.Ceil(config.retryAfter.Seconds()))))
+				resp.WriteData(req, problem)
+
+				return nil
+			}
+
+			recordGauges(config, limiter)
+
+			// Паника обработчика считается потерей запроса
+			dropped := true
+			defer func() {
+				token.Release(dropped)
+				recordGauges(config, limiter)
+			}()
+
+			// Ошибки обработчика и ответы 5xx считаются потерями, как и истекший дедлайн
+			recorder := transport.NewResponseRecorder(resp)
+			err = next(req, recorder)
+			dropped = recorder.ResultStatusCode(err) >= http.StatusInternalServerError ||
+				errors.Is(err, context.DeadlineExceeded) || errors.Is(req.Context().Err(), context.DeadlineExceeded)
+
+			if config.collector != nil {
+				if dropped {
+					config.collector.RecordError(config.operation, time.Since(start))
+				} else {
+					config.collector.RecordSuccess(config.operation, time.Since(start))
+				}
+			}
+
+			return err
+		}
+	}
+}
+
+func recordGauges(config Config, limiter *Limiter) {
+	if gauges, ok := config.collector.(log.GaugeCollector); ok {
+		gauges.RecordGauge(config.operation+".limit", float64(limiter.Limit()))
+		gauges.RecordGauge(config.operation+".inflight", float64(limiter.Inflight()))
+	}
+}
@@ -0,0 +1,295 @@
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/go-mosaic/runtime/transport"
+	transportchi "github.com/go-mosaic/runtime/transport/chi"
+)
+
+func TestAIMD(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []Sample
+		want    int
+	}{
+		{name: "grows under load", samples: []Sample{{Inflight: 5}, {Inflight: 6}}, want: 12},
+		{name: "idle keeps limit", samples: []Sample{{Inflight: 1}}, want: 10},
+		{name: "drop backs off", samples: []Sample{{Inflight: 10, Dropped: true}}, want: 9},
+		{name: "slow response counts as drop", samples: []Sample{{Inflight: 10, RTT: time.Second}}, want: 9},
+		{name: "respects min", samples: []Sample{{Dropped: true}, {Dropped: true}, {Dropped: true}}, want: 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewAIMD(10, 8, 12, WithTimeout(500*time.Millisecond))
+			for _, s := range tt.samples {
+				a.Update(s)
+			}
+			if got := a.Limit(); got != tt.want {
+				t.Errorf("Limit() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVegas(t *testing.T) {
+	v := NewVegas(20, 1, 100)
+	v.Update(Sample{RTT: 10 * time.Millisecond, Inflight: 20})
+	v.Update(Sample{RTT: 10 * time.Millisecond, Inflight: 20})
+	if got := v.Limit(); got <= 20 {
+		t.Errorf("Limit() without queueing = %v, want > 20", got)
+	}
+
+	grown := v.Limit()
+	v.Update(Sample{RTT: 100 * time.Millisecond, Inflight: grown})
+	if got := v.Limit(); got >= grown {
+		t.Errorf("Limit() with queueing = %v, want < %v", got, grown)
+	}
+}
+
+func TestGradient(t *testing.T) {
+	g := NewGradient(20, 1, 100)
+	for range 50 {
+		g.Update(Sample{RTT: 10 * time.Millisecond, Inflight: 20})
+	}
+	steady := g.Limit()
+	if steady <= 20 {
+		t.Errorf("Limit() with stable latency = %v, want > 20", steady)
+	}
+
+	for range 50 {
+		g.Update(Sample{RTT: 100 * time.Millisecond, Inflight: steady})
+	}
+	if got := g.Limit(); got >= steady {
+		t.Errorf("Limit() with growing latency = %v, want < %v", got, steady)
+	}
+}
+
+func TestMinLimit(t *testing.T) {
+	algorithms := map[string]Algorithm{
+		"aimd":     NewAIMD(1, 0, 10),
+		"gradient": NewGradient(1, 0, 10),
+		"vegas":    NewVegas(1, 0, 10),
+	}
+	for name, a := range algorithms {
+		t.Run(name, func(t *testing.T) {
+			for range 20 {
+				a.Update(Sample{RTT: time.Second, Inflight: 1, Dropped: true})
+			}
+			if got := a.Limit(); got != 1 {
+				t.Errorf("Limit() = %v, want 1", got)
+			}
+		})
+	}
+
+	limiter := NewLimiter(FixedLimit(0), 0, 0)
+	token, err := limiter.Acquire(context.Background(), PriorityNormal)
+	if err != nil {
+		t.Fatalf("Acquire() with zero limit error = %v", err)
+	}
+	token.Release(false)
+}
+
+// recordingAlgorithm запоминает переданные алгоритму результаты запросов
+type recordingAlgorithm struct {
+	FixedLimit
+	samples []Sample
+}
+
+func (a *recordingAlgorithm) Update(s Sample) {
+	a.samples = append(a.samples, s)
+}
+
+func TestMiddlewareDrops(t *testing.T) {
+	tests := []struct {
+		name        string
+		handler     transport.Handler
+		wantDropped bool
+	}{
+		{
+			name: "success",
+			handler: func(req transport.Request, resp transport.Response) error {
+				resp.WriteHeader(http.StatusOK)
+				return nil
+			},
+		},
+		{
+			name: "client error",
+			handler: func(req transport.Request, resp transport.Response) error {
+				resp.WriteHeader(http.StatusNotFound)
+				return nil
+			},
+		},
+		{
+			name: "server error status",
+			handler: func(req transport.Request, resp transport.Response) error {
+				resp.WriteHeader(http.StatusBadGateway)
+				return nil
+			},
+			wantDropped: true,
+		},
+		{
+			name: "handler error",
+			handler: func(transport.Request, transport.Response) error {
+				return errors.New("database is down")
+			},
+			wantDropped: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			algorithm := &recordingAlgorithm{FixedLimit: 10}
+			collector := &gaugeCollector{gauges: make(map[string]float64)}
+
+			router := chi.NewRouter()
+			tr := transportchi.NewChiTransport(router)
+			tr.AddRoute(http.MethodGet, "/", tt.handler, Middleware(NewLimiter(algorithm, 0, 0), WithMetricsCollector(collector)))
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if len(algorithm.samples) != 1 || algorithm.samples[0].Dropped != tt.wantDropped {
+				t.Errorf("samples = %+v, want one with Dropped %v", algorithm.samples, tt.wantDropped)
+			}
+			wantErrors := 0
+			if tt.wantDropped {
+				wantErrors = 1
+			}
+			if collector.errors != wantErrors {
+				t.Errorf("errors = %v, want %v", collector.errors, wantErrors)
+			}
+		})
+	}
+}
+
+func TestLimiterPriority(t *testing.T) {
+	limiter := NewLimiter(FixedLimit(1), 10, time.Second)
+
+	token, err := limiter.Acquire(context.Background(), PriorityNormal)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	var (
+		mu    sync.Mutex
+		order []Priority
+		wg    sync.WaitGroup
+	)
+	for i, p := range []Priority{PriorityLow, PriorityNormal, PriorityCritical} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tok, err := limiter.Acquire(context.Background(), p)
+			if err != nil {
+				t.Errorf("Acquire(%v) error = %v", p, err)
+				return
+			}
+			mu.Lock()
+			order = append(order, p)
+			mu.Unlock()
+			tok.Release(false)
+		}()
+		// Гарантируем порядок постановки в очередь
+		for limiter.Queued() < i+1 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	token.Release(false)
+	wg.Wait()
+
+	want := []Priority{PriorityCritical, PriorityNormal, PriorityLow}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("order = %v, want %v", order, want)
+		}
+	}
+}
+
+func TestLimiterMaxWait(t *testing.T) {
+	limiter := NewLimiter(FixedLimit(1), 1, 10*time.Millisecond)
+
+	token, _ := limiter.Acquire(context.Background(), PriorityNormal)
+	defer token.Release(false)
+
+	if _, err := limiter.Acquire(context.Background(), PriorityNormal); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("Acquire() error = %v, want %v", err, ErrLimitExceeded)
+	}
+	if got := limiter.Queued(); got != 0 {
+		t.Errorf("queue length = %v, want 0", got)
+	}
+}
+
+type gaugeCollector struct {
+	mu     sync.Mutex
+	errors int
+	gauges map[string]float64
+}
+
+func (c *gaugeCollector) RecordSuccess(string, time.Duration) {}
+func (c *gaugeCollector) RecordCall(string)                   {}
+
+func (c *gaugeCollector) RecordError(string, time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.errors++
+}
+
+func (c *gaugeCollector) RecordGauge(name string, value float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gauges[name] = value
+}
+
+func TestMiddleware(t *testing.T) {
+	collector := &gaugeCollector{gauges: make(map[string]float64)}
+	limiter := NewLimiter(FixedLimit(1), 0, 0)
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	router := chi.NewRouter()
+	tr := transportchi.NewChiTransport(router)
+	tr.AddRoute(http.MethodGet, "/", func(req transport.Request, resp transport.Response) error {
+		close(started)
+		<-release
+		resp.WriteHeader(http.StatusOK)
+		return nil
+	}, Middleware(limiter, WithMetricsCollector(collector), WithRetryAfter(2*time.Second)))
+
+	done := make(chan int)
+	go func() {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		done <- w.Code
+	}()
+	<-started
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %v, want %v", w.Code, http.StatusServiceUnavailable)
+	}
+	if got := w.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("Retry-After = %q, want 2", got)
+	}
+
+	close(release)
+	if code := <-done; code != http.StatusOK {
+		t.Errorf("first request status = %v, want %v", code, http.StatusOK)
+	}
+
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+	if collector.errors != 1 {
+		t.Errorf("errors = %v, want 1", collector.errors)
+	}
+	if collector.gauges["concurrency.limit"] != 1 || collector.gauges["concurrency.inflight"] != 0 {
+		t.Errorf("gauges = %v, want limit 1 and inflight 0", collector.gauges)
+	}
+}
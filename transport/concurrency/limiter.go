@@ -0,0 +1,192 @@
+package concurrency
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrLimitExceeded возвращается, если запрос не получил разрешение на выполнение
+var ErrLimitExceeded = errors.New("concurrency limit exceeded")
+
+// Priority класс приоритета запроса, запросы с большим значением обслуживаются из очереди первыми
+type Priority int
+
+const (
+	PriorityLow Priority = iota - 1
+	PriorityNormal
+	PriorityHigh
+	PriorityCritical
+)
+
+// Limiter ограничивает количество одновременно выполняемых запросов
+type Limiter struct {
+	mu        sync.Mutex
+	algorithm Algorithm
+	inflight  int
+	queue     waitQueue
+	seq       uint64
+	maxQueue  int
+	maxWait   time.Duration
+}
+
+// NewLimiter создает Limiter с алгоритмом algorithm.
+// maxQueue задает максимальный размер очереди ожидания, maxWait максимальное время ожидания в ней.
+func NewLimiter(algorithm Algorithm, maxQueue int, maxWait time.Duration) *Limiter {
+	return &Limiter{
+		algorithm: algorithm,
+		maxQueue:  maxQueue,
+		maxWait:   maxWait,
+	}
+}
+
+// Token разрешение на выполнение запроса
+type Token struct {
+	limiter *Limiter
+	start   time.Time
+	once    sync.Once
+}
+
+// Release освобождает разрешение и передает алгоритму результат выполнения
+func (t *Token) Release(dropped bool) {
+	t.once.Do(func() {
+		t.limiter.release(time.Since(t.start), dropped)
+	})
+}
+
+// Limit возвращает текущий лимит
+func (l *Limiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.limit()
+}
+
+// limit возвращает лимит алгоритма, но не меньше единицы: при нулевом лимите запросы ждали бы бесконечно
+func (l *Limiter) limit() int {
+	return max(l.algorithm.Limit(), 1)
+}
+
+// Inflight возвращает количество выполняемых запросов
+func (l *Limiter) Inflight() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.inflight
+}
+
+// Queued возвращает количество запросов в очереди ожидания
+func (l *Limiter) Queued() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.queue.Len()
+}
+
+// Acquire получает разрешение на выполнение запроса, ожидая в очереди не дольше maxWait
+func (l *Limiter) Acquire(ctx context.Context, priority Priority) (*Token, error) {
+	l.mu.Lock()
+
+	if l.inflight < l.limit() && l.queue.Len() == 0 {
+		l.inflight++
+		l.mu.Unlock()
+
+		return &Token{limiter: l, start: time.Now()}, nil
+	}
+
+	if l.queue.Len() >= l.maxQueue || l.maxWait <= 0 {
+		l.mu.Unlock()
+		return nil, ErrLimitExceeded
+	}
+
+	w := &waiter{priority: priority, seq: l.seq, ready: make(chan struct{})}
+	l.seq++
+	heap.Push(&l.queue, w)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(l.maxWait)
+	defer timer.Stop()
+
+	var err error
+	select {
+	case <-w.ready:
+		return &Token{limiter: l, start: time.Now()}, nil
+	case <-timer.C:
+		err = ErrLimitExceeded
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Разрешение могло быть выдано одновременно с истечением ожидания
+	if w.granted {
+		return &Token{limiter: l, start: time.Now()}, nil
+	}
+	heap.Remove(&l.queue, w.index)
+
+	return nil, err
+}
+
+func (l *Limiter) release(rtt time.Duration, dropped bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.algorithm.Update(Sample{RTT: rtt, Inflight: l.inflight, Dropped: dropped})
+	l.inflight--
+
+	for l.queue.Len() > 0 && l.inflight < l.limit() {
+		w := heap.Pop(&l.queue).(*waiter) //nolint:errcheck
+		w.granted = true
+		l.inflight++
+		close(w.ready)
+	}
+}
+
+type waiter struct {
+	priority Priority
+	seq      uint64
+	index    int
+	granted  bool
+	ready    chan struct{}
+}
+
+// waitQueue очередь ожидания, упорядоченная по приоритету и времени постановки
+type waitQueue []*waiter
+
+func (q waitQueue) Len() int {
+	return len(q)
+}
+
+func (q waitQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+
+	return q[i].seq < q[j].seq
+}
+
+func (q waitQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *waitQueue) Push(x any) {
+	w := x.(*waiter) //nolint:errcheck
+	w.index = len(*q)
+	*q = append(*q, w)
+}
+
+func (q *waitQueue) Pop() any {
+	old := *q
+	n := len(old)
+	w := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+
+	return w
+}
@@ -0,0 +1,162 @@
+package transport
+
+// ErrorHandler обрабатывает ошибку, возвращенную handler
+type ErrorHandler func(req Request, resp Response, err error)
+
+// DefaultErrorHandler записывает ошибку в ответ через WriteData
+func DefaultErrorHandler(req Request, resp Response, err error) {
+	resp.WriteData(req, err)
+}
+
+// Config конфигурация транспорта или отдельного маршрута
+type Config struct {
+	writeResponse WriteResponse
+	readData      ReadData
+	errorHandler  ErrorHandler
+	notFound      Handler
+	codecs        []Codec
+	maxBodySize   int64
+}
+
+// Option тип для функциональных опций транспорта.
+// Опции передаются в конструкторы транспортов, а также в AddRouteWith для переопределения на уровне маршрута.
+type Option func(*Config)
+
+func (o Option) applyRoute(r *Route) {
+	r.options = append(r.options, o)
+}
+
+// NewConfig создает конфигурацию, применяя опции
+func NewConfig(opts ...Option) Config {
+	var config Config
+
+	for _, applyOpt := range opts {
+		applyOpt(&config)
+	}
+
+	return config
+}
+
+// WithWriteResponse задает функцию записи данных в ответ
+func WithWriteResponse(writeResponse WriteResponse) Option {
+	return func(c *Config) {
+		c.writeResponse = writeResponse
+	}
+}
+
+// WithReadData задает функцию чтения данных из тела запроса
+func WithReadData(readData ReadData) Option {
+	return func(c *Config) {
+		c.readData = readData
+		c.codecs = nil
+	}
+}
+
+// WithErrorHandler задает обработчик ошибок, возвращенных handler
+func WithErrorHandler(errorHandler ErrorHandler) Option {
+	return func(c *Config) {
+		c.errorHandler = errorHandler
+	}
+}
+
+// WithNotFound задает handler для запросов, не совпавших ни с одним маршрутом.
+// На уровне маршрута применяется к запросам, не прошедшим ограничения параметров шаблона.
+func WithNotFound(notFound Handler) Option {
+	return func(c *Config) {
+		c.notFound = notFound
+	}
+}
+
+// WithCodecs задает кодеки тела запроса и ответа: ответ кодируется кодеком, выбранным по Accept,
+// а запрос декодируется кодеком, выбранным по Content-Type. Заменяет WithWriteResponse и WithReadData.
+func WithCodecs(codecs ...Codec) Option {
+	return func(c *Config) {
+		c.writeResponse = NewCodecWriteResponse(codecs...)
+		c.readData = nil
+		c.codecs = codecs
+	}
+}
+
+// WithMaxBodySize задает максимальный размер тела запроса, декодируемого кодеками WithCodecs,
+// по умолчанию DefaultMaxBodySize. Для тела большего размера ReadData возвращает Problem 413;
+// отрицательный size снимает ограничение.
+func WithMaxBodySize(size int64) Option {
+	return func(c *Config) {
+		c.maxBodySize = size
+	}
+}
+
+// WriteResponse возвращает функцию записи ответа или DefaultWriteResponse
+func (c Config) WriteResponse() WriteResponse {
+	if c.writeResponse == nil {
+		return DefaultWriteResponse
+	}
+
+	return c.writeResponse
+}
+
+// ReadData возвращает функцию чтения запроса или DefaultReadData
+func (c Config) ReadData() ReadData {
+	if readData := c.configuredReadData(); readData != nil {
+		return readData
+	}
+
+	return DefaultReadData
+}
+
+// configuredReadData возвращает функцию чтения запроса, заданную WithReadData или WithCodecs, либо nil
+func (c Config) configuredReadData() ReadData {
+	if len(c.codecs) == 0 {
+		return c.readData
+	}
+
+	maxBodySize := c.maxBodySize
+	if maxBodySize == 0 {
+		maxBodySize = DefaultMaxBodySize
+	}
+
+	return NewLimitedCodecReadData(maxBodySize, c.codecs...)
+}
+
+// ErrorHandler возвращает обработчик ошибок или DefaultErrorHandler
+func (c Config) ErrorHandler() ErrorHandler {
+	if c.errorHandler == nil {
+		return DefaultErrorHandler
+	}
+
+	return c.errorHandler
+}
+
+// NotFound возвращает handler ненайденных маршрутов или NotFound
+func (c Config) NotFound() Handler {
+	if c.notFound == nil {
+		return NotFound
+	}
+
+	return c.notFound
+}
+
+// wrap применяет к handler маршрута заданные переопределения
+func (c Config) wrap(handler Handler) Handler {
+	readData := c.configuredReadData()
+	if c.writeResponse == nil && readData == nil && c.errorHandler == nil {
+		return handler
+	}
+
+	return func(req Request, resp Response) error {
+		if readData != nil {
+			req = &readDataRequest{Request: req, readData: readData}
+		}
+		if c.writeResponse != nil {
+			resp = &writeDataResponse{Response: resp, writeResponse: c.writeResponse}
+		}
+
+		err := handler(req, resp)
+		if err != nil && c.errorHandler != nil {
+			c.errorHandler(req, resp, err)
+			return nil
+		}
+
+		return err
+	}
+}
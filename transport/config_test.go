@@ -0,0 +1,209 @@
+package transport_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-mosaic/runtime/transport"
+	"github.com/go-mosaic/runtime/transport/internal/transporttest"
+)
+
+type item struct {
+	Name string `json:"name" xml:"name"`
+}
+
+func echoItem(req transport.Request, resp transport.Response) error {
+	var it item
+	if err := req.ReadData(&it); err != nil {
+		return err
+	}
+	resp.WriteData(req, it)
+
+	return nil
+}
+
+func failing(req transport.Request, resp transport.Response) error {
+	return errors.New("boom")
+}
+
+func customWriter(req transport.Request, resp transport.Response, data any) {
+	resp.SetHeader("X-Writer", "custom")
+	transport.DefaultWriteResponse(req, resp, data)
+}
+
+func teapot(req transport.Request, resp transport.Response, err error) {
+	resp.SetBody([]byte("handled: "+err.Error()), http.StatusTeapot)
+}
+
+func TestTransportOptions(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []transport.Option
+		routeOpts   []transport.RouteOption
+		handler     transport.Handler
+		path        string
+		contentType string
+		body        string
+		wantStatus  int
+		wantBody    string
+		wantWriter  string
+	}{
+		{
+			name:       "write response",
+			opts:       []transport.Option{transport.WithWriteResponse(customWriter)},
+			handler:    echoItem,
+			path:       "/items",
+			body:       `{"name":"a"}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"name":"a"}`,
+			wantWriter: "custom",
+		},
+		{
+			name: "read data",
+			opts: []transport.Option{transport.WithReadData(func(req transport.Request, data any) error {
+				data.(*item).Name = "fixed"
+				return nil
+			})},
+			handler:    echoItem,
+			path:       "/items",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"name":"fixed"}`,
+		},
+		{
+			name:       "error handler",
+			opts:       []transport.Option{transport.WithErrorHandler(teapot)},
+			handler:    failing,
+			path:       "/items",
+			wantStatus: http.StatusTeapot,
+			wantBody:   "handled: boom",
+		},
+		{
+			name: "not found",
+			opts: []transport.Option{transport.WithNotFound(func(req transport.Request, resp transport.Response) error {
+				resp.SetBody([]byte("custom 404"), http.StatusNotFound)
+				return nil
+			})},
+			handler:    echoItem,
+			path:       "/missing",
+			wantStatus: http.StatusNotFound,
+			wantBody:   "custom 404",
+		},
+		{
+			name:        "codecs",
+			opts:        []transport.Option{transport.WithCodecs(transport.XMLCodec{}, transport.JSONCodec{})},
+			handler:     echoItem,
+			path:        "/items",
+			contentType: "application/json",
+			body:        `{"name":"a"}`,
+			wantStatus:  http.StatusOK,
+			wantBody:    `<item><name>a</name></item>`,
+		},
+		{
+			name:        "codecs unsupported media type",
+			opts:        []transport.Option{transport.WithCodecs(transport.JSONCodec{})},
+			handler:     echoItem,
+			path:        "/items",
+			contentType: "text/csv",
+			body:        `a`,
+			wantStatus:  http.StatusUnsupportedMediaType,
+		},
+		{
+			name:        "codecs body too large",
+			opts:        []transport.Option{transport.WithMaxBodySize(8), transport.WithCodecs(transport.JSONCodec{})},
+			handler:     echoItem,
+			path:        "/items",
+			contentType: "application/json",
+			body:        `{"name":"long"}`,
+			wantStatus:  http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:        "codecs body within limit",
+			opts:        []transport.Option{transport.WithCodecs(transport.JSONCodec{}), transport.WithMaxBodySize(12)},
+			handler:     echoItem,
+			path:        "/items",
+			contentType: "application/json",
+			body:        `{"name":"a"}`,
+			wantStatus:  http.StatusOK,
+			wantBody:    `{"name":"a"}`,
+		},
+		{
+			name:        "route max body size",
+			opts:        []transport.Option{transport.WithCodecs(transport.JSONCodec{})},
+			routeOpts:   []transport.RouteOption{transport.WithCodecs(transport.JSONCodec{}), transport.WithMaxBodySize(8)},
+			handler:     echoItem,
+			path:        "/items",
+			contentType: "application/json",
+			body:        `{"name":"long"}`,
+			wantStatus:  http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:       "route write response",
+			routeOpts:  []transport.RouteOption{transport.WithWriteResponse(customWriter)},
+			handler:    echoItem,
+			path:       "/items",
+			body:       `{"name":"a"}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"name":"a"}`,
+			wantWriter: "custom",
+		},
+		{
+			name:       "route error handler overrides transport",
+			opts:       []transport.Option{transport.WithErrorHandler(transport.DefaultErrorHandler)},
+			routeOpts:  []transport.RouteOption{transport.WithErrorHandler(teapot)},
+			handler:    failing,
+			path:       "/items",
+			wantStatus: http.StatusTeapot,
+			wantBody:   "handled: boom",
+		},
+	}
+
+	for _, adapter := range transporttest.Adapters() {
+		for _, tt := range tests {
+			t.Run(adapter.Name+"/"+tt.name, func(t *testing.T) {
+				srv := adapter.New(tt.opts...)
+				srv.Transport.AddRouteWith(http.MethodPost, "/items", tt.handler, tt.routeOpts...)
+
+				r := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+				if tt.contentType != "" {
+					r.Header.Set("Content-Type", tt.contentType)
+				}
+				resp := srv.Do(t, r)
+				body, _ := io.ReadAll(resp.Body)
+				resp.Body.Close()
+
+				if resp.StatusCode != tt.wantStatus {
+					t.Errorf("status = %d, want %d (body %q)", resp.StatusCode, tt.wantStatus, body)
+				}
+				if tt.wantBody != "" && string(body) != tt.wantBody {
+					t.Errorf("body = %q, want %q", body, tt.wantBody)
+				}
+				if got := resp.Header.Get("X-Writer"); got != tt.wantWriter {
+					t.Errorf("X-Writer = %q, want %q", got, tt.wantWriter)
+				}
+			})
+		}
+	}
+}
+
+func TestRouteOptionsScope(t *testing.T) {
+	for _, adapter := range transporttest.Adapters() {
+		t.Run(adapter.Name, func(t *testing.T) {
+			srv := adapter.New()
+			srv.Transport.AddRouteWith(http.MethodPost, "/custom", echoItem, transport.WithWriteResponse(customWriter))
+			srv.Transport.AddRoute(http.MethodPost, "/default", echoItem)
+
+			for path, want := range map[string]string{"/custom": "custom", "/default": ""} {
+				resp := srv.Do(t, httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"name":"a"}`)))
+				resp.Body.Close()
+
+				if got := resp.Header.Get("X-Writer"); got != want {
+					t.Errorf("%s: X-Writer = %q, want %q", path, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package transport_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofiber/fiber/v3"
+	"github.com/labstack/echo/v4"
+	"github.com/valyala/fasthttp"
+
+	"github.com/go-mosaic/runtime/transport"
+	transportchi "github.com/go-mosaic/runtime/transport/chi"
+	transportecho "github.com/go-mosaic/runtime/transport/echo"
+	transportfasthttp "github.com/go-mosaic/runtime/transport/fasthttp"
+	transportfiber "github.com/go-mosaic/runtime/transport/fiber"
+	transportgin "github.com/go-mosaic/runtime/transport/gin"
+	transportgorilla "github.com/go-mosaic/runtime/transport/gorilla"
+	transporthttp "github.com/go-mosaic/runtime/transport/http"
+	transporthttprouter "github.com/go-mosaic/runtime/transport/httprouter"
+	"github.com/go-mosaic/runtime/transport/internal/transporttest"
+)
+
+type convertKey struct{}
+
+const (
+	headerBlock = "X-Block"
+	blockedBody = "blocked"
+	afterNext   = "!"
+)
+
+// httpConverted middleware net/http: при X-Block отвечает 403 без вызова next,
+// иначе добавляет значение в контекст и дописывает тело после next
+func httpConverted(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(headerBlock) != "" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = io.WriteString(w, blockedBody)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), convertKey{}, "http")))
+		_, _ = io.WriteString(w, afterNext)
+	})
+}
+
+func echoConverted(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if c.Request().Header.Get(headerBlock) != "" {
+			return c.String(http.StatusForbidden, blockedBody)
+		}
+
+		c.SetRequest(c.Request().WithContext(context.WithValue(c.Request().Context(), convertKey{}, "echo")))
+		if err := next(c); err != nil {
+			return err
+		}
+		_, err := io.WriteString(c.Response(), afterNext)
+
+		return err
+	}
+}
+
+func ginConverted(c *gin.Context) {
+	if c.GetHeader(headerBlock) != "" {
+		c.String(http.StatusForbidden, blockedBody)
+		c.Abort()
+		return
+	}
+
+	c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), convertKey{}, "gin"))
+	c.Next()
+	_, _ = c.Writer.WriteString(afterNext)
+}
+
+func fiberConverted(c fiber.Ctx) error {
+	if c.Get(headerBlock) != "" {
+		return c.Status(http.StatusForbidden).SendString(blockedBody)
+	}
+
+	c.SetContext(context.WithValue(c.Context(), convertKey{}, "fiber"))
+	if err := c.Next(); err != nil {
+		return err
+	}
+	c.Response().AppendBodyString(afterNext)
+
+	return nil
+}
+
+func fasthttpConverted(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		if len(ctx.Request.Header.Peek(headerBlock)) != 0 {
+			ctx.SetStatusCode(http.StatusForbidden)
+			ctx.SetBodyString(blockedBody)
+			return
+		}
+
+		ctx.SetUserValue(convertKey{}, "fasthttp")
+		next(ctx)
+		ctx.Response.AppendBodyString(afterNext)
+	}
+}
+
+func TestToMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	converted := map[string]struct {
+		middleware transport.Middleware
+		value      string
+	}{
+		"http":       {middleware: transporthttp.HTTPToMiddleware(httpConverted), value: "http"},
+		"chi":        {middleware: transportchi.ChiToMiddleware(httpConverted), value: "http"},
+		"gorilla":    {middleware: transportgorilla.GorillaToMiddleware(httpConverted), value: "http"},
+		"httprouter": {middleware: transporthttprouter.HTTPRouterToMiddleware(httpConverted), value: "http"},
+		"echo":       {middleware: transportecho.EchoToMiddleware(echoConverted), value: "echo"},
+		"gin":        {middleware: transportgin.GinToMiddleware(ginConverted), value: "gin"},
+		"fiber":      {middleware: transportfiber.FiberToMiddleware(fiberConverted), value: "fiber"},
+		"fasthttp":   {middleware: transportfasthttp.FastHTTPToMiddleware(fasthttpConverted), value: "fasthttp"},
+	}
+
+	for _, adapter := range transporttest.Adapters() {
+		c := converted[adapter.Name]
+
+		tests := []struct {
+			name       string
+			block      bool
+			nested     bool
+			wantStatus int
+			wantBody   string
+			wantCalls  int
+		}{
+			{name: "context and write after next", wantStatus: http.StatusOK, wantBody: "value=" + c.value + afterNext, wantCalls: 1},
+			{name: "short circuit", block: true, wantStatus: http.StatusForbidden, wantBody: blockedBody},
+			{name: "nested", nested: true, wantStatus: http.StatusOK, wantBody: "value=" + c.value + afterNext + afterNext, wantCalls: 1},
+		}
+		for _, tt := range tests {
+			t.Run(adapter.Name+"/"+tt.name, func(t *testing.T) {
+				var calls int
+				middlewares := []transport.Middleware{c.middleware}
+				if tt.nested {
+					middlewares = append(middlewares, c.middleware)
+				}
+
+				srv := adapter.New()
+				srv.Transport.AddRoute(http.MethodGet, "/convert", func(req transport.Request, resp transport.Response) error {
+					calls++
+					resp.SetBody([]byte(fmt.Sprint("value=", req.Context().Value(convertKey{}))), http.StatusOK)
+					return nil
+				}, middlewares...)
+
+				r := httptest.NewRequest(http.MethodGet, "/convert", nil)
+				if tt.block {
+					r.Header.Set(headerBlock, "1")
+				}
+				resp := srv.Do(t, r)
+				body, _ := io.ReadAll(resp.Body)
+				resp.Body.Close()
+
+				if resp.StatusCode != tt.wantStatus || string(body) != tt.wantBody {
+					t.Errorf("response = %d %q, want %d %q", resp.StatusCode, body, tt.wantStatus, tt.wantBody)
+				}
+				if calls != tt.wantCalls {
+					t.Errorf("handler calls = %d, want %d", calls, tt.wantCalls)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package transport
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// NotFound отвечает 404 в формате problem details; используется транспортами по умолчанию
+func NotFound(req Request, resp Response) error {
+	resp.WriteData(req, NewProblem(http.StatusNotFound, "no route matches "+req.Path()))
+	return nil
+}
+
+// Dispatcher выбирает handler по методу запроса для одного шаблона пути.
+// Транспорт регистрирует Dispatcher в роутере один раз для всех методов, после чего:
+//   - HEAD без собственного handler обслуживается handler GET без тела ответа;
+//   - OPTIONS без собственного handler получает 204 и заголовок Allow;
+//   - остальные методы получают 405 в формате problem details и заголовок Allow.
+type Dispatcher struct {
+	mu       sync.RWMutex
+	handlers map[string]Handler
+	auto     Handler
+}
+
+// NewDispatcher создает Dispatcher; автоматические ответы OPTIONS и 405 оборачиваются middleware стека
+func NewDispatcher(middlewares *MiddlewareStack) *Dispatcher {
+	d := &Dispatcher{handlers: make(map[string]Handler)}
+	d.auto = middlewares.Handler(d.serveAuto)
+
+	return d
+}
+
+// Handle регистрирует handler метода
+func (d *Dispatcher) Handle(method string, handler Handler) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.handlers[method]; ok {
+		return fmt.Errorf("transport: duplicate handler for method %s", method)
+	}
+	d.handlers[method] = handler
+
+	return nil
+}
+
+// Allow возвращает значение заголовка Allow
+func (d *Dispatcher) Allow() string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	methods := make([]string, 0, len(d.handlers)+2) //nolint:mnd
+	for method := range d.handlers {
+		methods = append(methods, method)
+	}
+	if _, ok := d.handlers[http.MethodGet]; ok {
+		methods = append(methods, http.MethodHead)
+	}
+	methods = append(methods, http.MethodOptions)
+
+	slices.Sort(methods)
+
+	return strings.Join(slices.Compact(methods), ", ")
+}
+
+// Serve обрабатывает запрос handler его метода
+func (d *Dispatcher) Serve(req Request, resp Response) error {
+	d.mu.RLock()
+	handler, ok := d.handlers[req.Method()]
+	get := d.handlers[http.MethodGet]
+	d.mu.RUnlock()
+
+	switch {
+	case ok:
+		return handler(req, resp)
+	case req.Method() == http.MethodHead && get != nil:
+		return get(req, &headResponse{Response: resp})
+	default:
+		return d.auto(req, resp)
+	}
+}
+
+func (d *Dispatcher) serveAuto(req Request, resp Response) error {
+	allow := d.Allow()
+
+	if req.Method() == http.MethodOptions {
+		resp.SetHeader("Allow", allow)
+		resp.WriteHeader(http.StatusNoContent)
+
+		return nil
+	}
+
+	problem := NewProblem(http.StatusMethodNotAllowed, "method "+req.Method()+" is not allowed").
+		WithHeader("Allow", allow)
+	resp.WriteData(req, problem)
+
+	return nil
+}
+
+// headResponse ответ на HEAD: статус и заголовки передаются, тело отбрасывается
+type headResponse struct {
+	Response
+}
+
+func (r *headResponse) SetBody(body []byte, statusCode int) int {
+	r.WriteHeader(statusCode)
+
+	return len(body)
+}
+
+func (r *headResponse) AddHeader(key, value string) {
+	AddHeader(r.Response, key, value)
+}
+
+func (r *headResponse) Write(body []byte) (int, error) {
+	return len(body), nil
+}
+
+func (r *headResponse) WriteData(req Request, data any) {
+	WriteResponseOf(r.Response)(req, r, data)
+}
+
+func (r *headResponse) WriteResponseFunc() WriteResponse {
+	return WriteResponseOf(r.Response)
+}
+
+func (r *headResponse) Unwrap() Response {
+	return r.Response
+}
@@ -0,0 +1,113 @@
+package transport_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-mosaic/runtime/transport"
+	"github.com/go-mosaic/runtime/transport/internal/transporttest"
+)
+
+func TestDispatcherMethods(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+		wantBody   string
+		wantAllow  string
+	}{
+		{name: "get", method: http.MethodGet, path: "/items/1", wantStatus: http.StatusOK, wantBody: "get 1"},
+		{name: "post", method: http.MethodPost, path: "/items/1", wantStatus: http.StatusOK, wantBody: "post 1"},
+		{name: "head from get", method: http.MethodHead, path: "/items/1", wantStatus: http.StatusOK},
+		{name: "options", method: http.MethodOptions, path: "/items/1", wantStatus: http.StatusNoContent,
+			wantAllow: "GET, HEAD, OPTIONS, POST"},
+		{name: "method not allowed", method: http.MethodDelete, path: "/items/1", wantStatus: http.StatusMethodNotAllowed,
+			wantAllow: "GET, HEAD, OPTIONS, POST"},
+		{name: "not found", method: http.MethodGet, path: "/missing", wantStatus: http.StatusNotFound},
+	}
+
+	for _, adapter := range transporttest.Adapters() {
+		srv := adapter.New()
+		srv.Transport.Use(func(next transport.Handler) transport.Handler {
+			return func(req transport.Request, resp transport.Response) error {
+				resp.SetHeader("X-Global", "1")
+				return next(req, resp)
+			}
+		})
+		srv.Transport.AddRoute(http.MethodGet, "/items/{id}", textHandler("get", "id"))
+		srv.Transport.AddRoute(http.MethodPost, "/items/{id}", textHandler("post", "id"))
+
+		for _, tt := range tests {
+			t.Run(adapter.Name+"/"+tt.name, func(t *testing.T) {
+				resp := srv.Do(t, httptest.NewRequest(tt.method, tt.path, nil))
+				body, _ := io.ReadAll(resp.Body)
+				resp.Body.Close()
+
+				if resp.StatusCode != tt.wantStatus {
+					t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+				}
+				if tt.wantBody != "" && string(body) != tt.wantBody {
+					t.Errorf("body = %q, want %q", body, tt.wantBody)
+				}
+				if tt.method == http.MethodHead && len(body) != 0 {
+					t.Errorf("HEAD body = %q, want empty", body)
+				}
+				if got := resp.Header.Get("Allow"); got != tt.wantAllow {
+					t.Errorf("Allow = %q, want %q", got, tt.wantAllow)
+				}
+				if resp.Header.Get("X-Global") != "1" {
+					t.Errorf("global middleware did not run")
+				}
+				if tt.wantStatus >= http.StatusBadRequest && !strings.Contains(string(body), `"status":`) {
+					t.Errorf("body = %q, want problem details", body)
+				}
+			})
+		}
+	}
+}
+
+func TestCustomNotFound(t *testing.T) {
+	for _, adapter := range transporttest.Adapters() {
+		t.Run(adapter.Name, func(t *testing.T) {
+			srv := adapter.New()
+			srv.Transport.AddRoute(http.MethodGet, "/users/{id:int}", textHandler("user", "id"))
+			srv.Transport.(interface{ NotFound(transport.Handler) }).NotFound(func(req transport.Request, resp transport.Response) error {
+				resp.WriteData(req, transport.NewProblem(http.StatusNotFound, "nothing at "+req.Path()))
+				return nil
+			})
+
+			for _, path := range []string{"/missing", "/users/abc"} {
+				r := httptest.NewRequest(http.MethodGet, path, nil)
+				r.Header.Set("Accept", "application/json")
+				resp := srv.Do(t, r)
+				body, _ := io.ReadAll(resp.Body)
+				resp.Body.Close()
+
+				want := `"detail":"nothing at ` + path + `"`
+				if resp.StatusCode != http.StatusNotFound || !strings.Contains(string(body), want) {
+					t.Errorf("%s: status = %d, body = %s, want 404 with %s", path, resp.StatusCode, body, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDuplicateRoute(t *testing.T) {
+	for _, adapter := range transporttest.Adapters() {
+		t.Run(adapter.Name, func(t *testing.T) {
+			srv := adapter.New()
+			srv.Transport.AddRoute(http.MethodGet, "/items", textHandler("a"))
+
+			defer func() {
+				if recover() == nil {
+					t.Errorf("AddRoute() duplicate did not panic")
+				}
+			}()
+			srv.Transport.AddRoute(http.MethodGet, "/items", textHandler("b"))
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"slices"
 
 	"github.com/labstack/echo/v4"
 
@@ -14,40 +15,59 @@ import (
 // EchoRequest адаптер для echo.Context
 type EchoRequest struct {
 	ctx      echo.Context
+	req      *http.Request
 	readData transport.ReadData
 }
 
 func (r *EchoRequest) WithContext(ctx context.Context) transport.Request {
-	newCtx := r.ctx.Echo().NewContext(
-		r.ctx.Request().WithContext(ctx),
-		r.ctx.Response(),
-	)
+	return &EchoRequest{ctx: r.ctx, req: r.request().WithContext(ctx), readData: r.readData}
+}
+
+// Detach возвращает запрос с новым echo.Context: echo переиспользует Context после возврата обработчика
+func (r *EchoRequest) Detach() transport.Request {
+	c := r.ctx.Echo().NewContext(r.request(), nil)
+	c.SetPath(r.ctx.Path())
+	c.SetParamNames(slices.Clone(r.ctx.ParamNames())...)
+	c.SetParamValues(slices.Clone(r.ctx.ParamValues())...)
+
+	return &EchoRequest{ctx: c, req: r.req, readData: r.readData}
+}
+
+// request возвращает запрос с учетом контекста, установленного через WithContext
+func (r *EchoRequest) request() *http.Request {
+	if r.req != nil {
+		return r.req
+	}
 
-	return &EchoRequest{ctx: newCtx, readData: r.readData}
+	return r.ctx.Request()
 }
 
 func (r *EchoRequest) Context() context.Context {
-	return r.ctx.Request().Context()
+	return r.request().Context()
 }
 
 func (r *EchoRequest) Method() string {
-	return r.ctx.Request().Method
+	return r.request().Method
 }
 
 func (r *EchoRequest) Path() string {
-	return r.ctx.Request().URL.Path
+	return r.request().URL.Path
+}
+
+func (r *EchoRequest) Pattern() string {
+	return r.ctx.Path()
 }
 
 func (r *EchoRequest) Body() io.ReadCloser {
-	return r.ctx.Request().Body
+	return r.request().Body
 }
 
 func (r *EchoRequest) Header(key string) string {
-	return r.ctx.Request().Header.Get(key)
+	return r.request().Header.Get(key)
 }
 
 func (r *EchoRequest) Queries() url.Values {
-	return r.ctx.Request().URL.Query()
+	return r.request().URL.Query()
 }
 
 func (r *EchoRequest) PathValue(name string) string {
@@ -55,9 +75,9 @@ func (r *EchoRequest) PathValue(name string) string {
 }
 
 func (r *EchoRequest) MultipartForm(maxMemory int64) (transport.Form, error) {
-	err := r.ctx.Request().ParseMultipartForm(maxMemory)
+	err := r.request().ParseMultipartForm(maxMemory)
 
-	return transport.MultipartFormWrap(r.ctx.Request().MultipartForm), err
+	return transport.MultipartFormWrap(r.request().MultipartForm), err
 }
 
 func (r *EchoRequest) URLEncodedForm() (url.Values, error) {
@@ -68,6 +88,10 @@ func (r *EchoRequest) ReadData(data any) error {
 	return r.readData(r, data)
 }
 
+func (r *EchoRequest) ReadDataFunc() transport.ReadData {
+	return r.readData
+}
+
 func (r *EchoRequest) SetCookie(name, value string, maxAge int, path, domain string, secure, httpOnly bool) {
 	r.ctx.SetCookie(&http.Cookie{
 		Name:     name,
@@ -89,6 +113,10 @@ func (r *EchoRequest) Cookie(name string) (string, error) {
 	return c.Value, nil
 }
 
+func (r *EchoRequest) RemoteAddr() string {
+	return r.request().RemoteAddr
+}
+
 // EchoResponse адаптер для echo.Context
 type EchoResponse struct {
 	ctx           echo.Context
@@ -103,10 +131,18 @@ func (r *EchoResponse) SetHeader(key, value string) {
 	r.ctx.Response().Header().Set(key, value)
 }
 
+func (r *EchoResponse) AddHeader(key, value string) {
+	r.ctx.Response().Header().Add(key, value)
+}
+
 func (r *EchoResponse) WriteData(req transport.Request, data any) {
 	r.writeResponse(req, r, data)
 }
 
+func (r *EchoResponse) WriteResponseFunc() transport.WriteResponse {
+	return r.writeResponse
+}
+
 func (r *EchoResponse) Write(body []byte) (int, error) {
 	return r.ctx.Response().Write(body)
 }
@@ -126,6 +162,17 @@ func (r *EchoResponse) WriteHeader(statusCode int) {
 type EchoAdapter struct {
 	writeResponse transport.WriteResponse
 	readData      transport.ReadData
+	notFound      transport.Handler
+	errorHandler  transport.ErrorHandler
+}
+
+// serveNotFound обрабатывает запрос, не совпавший ни с одним маршрутом
+func (a *EchoAdapter) serveNotFound(req transport.Request, resp transport.Response) error {
+	if a.notFound != nil {
+		return a.notFound(req, resp)
+	}
+
+	return transport.NotFound(req, resp)
 }
 
 func (a *EchoAdapter) AdaptHandler(handler transport.Handler) echo.HandlerFunc {
@@ -133,7 +180,7 @@ func (a *EchoAdapter) AdaptHandler(handler transport.Handler) echo.HandlerFunc {
 		req := &EchoRequest{ctx: c, readData: a.readData}
 		resp := &EchoResponse{ctx: c, writeResponse: a.writeResponse}
 		if err := handler(req, resp); err != nil {
-			a.writeResponse(req, resp, err)
+			a.errorHandler(req, resp, err)
 			return nil
 		}
 		return nil
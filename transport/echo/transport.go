@@ -1,6 +1,10 @@
 package echo
 
 import (
+	"fmt"
+	"net/http"
+	"strings"
+
 	"github.com/labstack/echo/v4"
 
 	"github.com/go-mosaic/runtime/transport"
@@ -10,55 +14,194 @@ import (
 func EchoToMiddleware(echoMiddleware echo.MiddlewareFunc) transport.Middleware {
 	return func(next transport.Handler) transport.Handler {
 		return func(req transport.Request, resp transport.Response) error {
+			echoReq, ok := transport.UnwrapRequest(req).(*EchoRequest)
+			if !ok {
+				return fmt.Errorf("%w: %T", transport.ErrAdapterMismatch, req)
+			}
+
+			c := echoReq.ctx
+			orig := c.Request()
+			c.SetRequest(orig.WithContext(req.Context()))
+			defer c.SetRequest(orig)
+
 			echoHandler := func(c echo.Context) error {
-				return next(req, resp)
+				// контекст, измененный middleware echo, передается дальше по цепочке
+				return next(req.WithContext(c.Request().Context()), resp)
 			}
 
 			wrappedHandler := echoMiddleware(echoHandler)
 
-			return wrappedHandler(req.(*EchoRequest).ctx)
+			return wrappedHandler(c)
 		}
 	}
 }
 
+// patternSyntax синтаксис шаблонов echo: параметры ":name", catch-all доступен только как "*"
+var patternSyntax = transport.PatternSyntax{
+	Param:    func(name string) string { return ":" + name },
+	CatchAll: func(string) (string, string) { return "*", "*" },
+}
+
+// router общие методы echo.Echo и echo.Group
+type router interface {
+	Any(path string, handler echo.HandlerFunc, middleware ...echo.MiddlewareFunc) []*echo.Route
+	Group(prefix string, middleware ...echo.MiddlewareFunc) *echo.Group
+}
+
 type EchoTransport struct {
-	router  *echo.Echo
-	adapter *EchoAdapter
+	echo        *echo.Echo
+	router      router
+	middlewares *transport.MiddlewareStack
+	adapter     *EchoAdapter
+	routes      *transport.RouteTable
+	prefix      string
+	dispatchers map[string]*transport.Dispatcher
 }
 
-func NewEchoTransport(router *echo.Echo) *EchoTransport {
-	return &EchoTransport{
-		router: router,
+func NewEchoTransport(e *echo.Echo, opts ...transport.Option) *EchoTransport {
+	config := transport.NewConfig(opts...)
+
+	t := &EchoTransport{
+		echo:        e,
+		router:      e,
+		middlewares: transport.NewMiddlewareStack(nil),
+		routes:      transport.NewRouteTable(),
+		dispatchers: make(map[string]*transport.Dispatcher),
 		adapter: &EchoAdapter{
-			writeResponse: transport.DefaultWriteResponse,
-			readData:      transport.DefaultReadData,
+			writeResponse: config.WriteResponse(),
+			readData:      config.ReadData(),
+			notFound:      config.NotFound(),
+			errorHandler:  config.ErrorHandler(),
 		},
 	}
+	e.RouteNotFound("/*", t.adapter.AdaptHandler(t.middlewares.Handler(t.adapter.serveNotFound)))
+
+	return t
 }
 
+// AddRoute регистрирует маршрут с middleware
 func (t *EchoTransport) AddRoute(method, path string, handler transport.Handler, middlewares ...transport.Middleware) {
-	wrappedHandler := handler
-	for _, mw := range middlewares {
-		wrappedHandler = mw(wrappedHandler)
+	t.AddRouteWith(method, path, handler, transport.MiddlewareOptions(middlewares...)...)
+}
+
+// AddRouteWith регистрирует маршрут с опциями: именем, тегами, метаданными и middleware
+func (t *EchoTransport) AddRouteWith(method, path string, handler transport.Handler, opts ...transport.RouteOption) {
+	// ограничения проверяются по полному шаблону, а в группу echo регистрируется относительный путь
+	pattern, full := transport.MustNativePattern(transport.JoinPattern(t.prefix, path), patternSyntax)
+	_, native := transport.MustNativePattern(path, patternSyntax)
+	route := transport.NewRoute(method, path, opts...)
+	if err := t.routes.Add(route); err != nil {
+		panic(err)
+	}
+
+	paths := native.Paths
+	if t.prefix != "" && path == "/" {
+		// корневой маршрут группы совпадает с префиксом как с завершающим "/", так и без него
+		paths = []string{"", "/"}
 	}
 
-	t.router.Add(method, path, t.adapter.AdaptHandler(wrappedHandler))
+	h := t.middlewares.Handler(pattern.Bind(route.Handler(handler), native.Aliases, route.NotFound(t.adapter.serveNotFound)))
+	if err := t.dispatcher(full, paths).Handle(method, h); err != nil {
+		panic(fmt.Errorf("%w: %s", err, pattern))
+	}
 }
 
-func (t *EchoTransport) Use(middlewares ...transport.Middleware) {
-	for _, mw := range middlewares {
-		t.router.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
-			return func(c echo.Context) error {
-				wrappedHandler := mw(func(req transport.Request, resp transport.Response) error {
-					return next(c)
-				})
-
-				if err := wrappedHandler(&EchoRequest{ctx: c}, &EchoResponse{ctx: c}); err != nil {
-					return err
-				}
-
-				return nil
-			}
-		})
+// dispatcher возвращает Dispatcher шаблона, регистрируя его в роутере для всех методов при первом обращении
+func (t *EchoTransport) dispatcher(full transport.NativePattern, paths []string) *transport.Dispatcher {
+	key := strings.Join(full.Paths, " ")
+	if d, ok := t.dispatchers[key]; ok {
+		return d
+	}
+
+	d := transport.NewDispatcher(t.middlewares)
+	t.dispatchers[key] = d
+
+	h := t.adapter.AdaptHandler(d.Serve)
+	for _, p := range paths {
+		t.router.Any(p, h)
+	}
+
+	return d
+}
+
+// NotFound задает handler для запросов, не совпавших ни с одним маршрутом.
+// Ответ проходит через глобальные middleware.
+func (t *EchoTransport) NotFound(handler transport.Handler) {
+	t.adapter.notFound = handler
+}
+
+// Routes возвращает таблицу зарегистрированных маршрутов
+func (t *EchoTransport) Routes() *transport.RouteTable {
+	return t.routes
+}
+
+// Group возвращает группу маршрутов под префиксом prefix на основе echo.Group
+func (t *EchoTransport) Group(prefix string, middlewares ...transport.Middleware) transport.Transport {
+	_, native := transport.MustNativePattern(prefix, patternSyntax)
+
+	group := &EchoTransport{
+		echo:        t.echo,
+		router:      t.router.Group(native.Paths[0]),
+		middlewares: transport.NewMiddlewareStack(t.middlewares, middlewares...),
+		adapter:     t.adapter,
+		routes:      transport.NewRouteTable(),
+		prefix:      transport.JoinPattern(t.prefix, prefix),
+		dispatchers: t.dispatchers,
+	}
+	if err := t.routes.Mount(prefix, group.routes); err != nil {
+		panic(err)
+	}
+
+	return group
+}
+
+// Mount подключает транспорт sub под префиксом prefix, удаляя префикс из пути запроса
+func (t *EchoTransport) Mount(prefix string, sub transport.Transport) {
+	if err := t.routes.Mount(prefix, sub.Routes()); err != nil {
+		panic(err)
+	}
+
+	h, err := transport.HandlerOf(sub)
+	if err != nil {
+		panic(err)
+	}
+
+	_, native := transport.MustNativePattern(prefix, patternSyntax)
+	base := strings.TrimSuffix(native.Paths[0], "/")
+	handler := t.adapter.AdaptHandler(t.middlewares.Handler(mountHandler(transport.StripPrefix(transport.JoinPattern(t.prefix, prefix), h))))
+
+	if base != "" {
+		t.router.Any(base, handler)
 	}
+	t.router.Any(base+"/*", handler)
+}
+
+// mountHandler преобразует подключаемый http.Handler в универсальный Handler,
+// чтобы к подключенному транспорту применялись middleware родителя
+func mountHandler(h http.Handler) transport.Handler {
+	return func(req transport.Request, resp transport.Response) error {
+		echoReq, ok := transport.UnwrapRequest(req).(*EchoRequest)
+		if !ok {
+			return fmt.Errorf("%w: %T", transport.ErrAdapterMismatch, req)
+		}
+
+		var w http.ResponseWriter
+		if echoResp, ok := transport.UnwrapResponse(resp).(*EchoResponse); ok {
+			w = echoResp.ctx.Response()
+		}
+
+		transport.ServeMounted(h, req, resp, w, echoReq.request())
+
+		return nil
+	}
+}
+
+// ServeHTTP реализует http.Handler
+func (t *EchoTransport) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	t.echo.ServeHTTP(w, r)
+}
+
+// Use добавляет глобальные middleware; они применяются и к маршрутам, зарегистрированным ранее
+func (t *EchoTransport) Use(middlewares ...transport.Middleware) {
+	t.middlewares.Use(middlewares...)
 }
@@ -0,0 +1,233 @@
+package fasthttp
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/fasthttp/router"
+	"github.com/valyala/fasthttp"
+
+	"github.com/go-mosaic/runtime/transport"
+)
+
+// FastHTTPRequest адаптер для fasthttp.RequestCtx
+type FastHTTPRequest struct {
+	ctx      *fasthttp.RequestCtx
+	userCtx  context.Context
+	readData transport.ReadData
+}
+
+func (r *FastHTTPRequest) WithContext(ctx context.Context) transport.Request {
+	return &FastHTTPRequest{ctx: r.ctx, userCtx: ctx, readData: r.readData}
+}
+
+// Context возвращает контекст, установленный через WithContext, или сам fasthttp.RequestCtx,
+// значения которого доступны через SetUserValue
+func (r *FastHTTPRequest) Context() context.Context {
+	if r.userCtx != nil {
+		return r.userCtx
+	}
+
+	return r.ctx
+}
+
+func (r *FastHTTPRequest) Method() string {
+	return string(r.ctx.Method())
+}
+
+func (r *FastHTTPRequest) Path() string {
+	return string(r.ctx.Path())
+}
+
+func (r *FastHTTPRequest) Pattern() string {
+	pattern, _ := r.ctx.UserValue(router.MatchedRoutePathParam).(string)
+
+	return pattern
+}
+
+// Body возвращает тело запроса без копирования; данные действительны только до завершения обработки запроса
+func (r *FastHTTPRequest) Body() io.ReadCloser {
+	return io.NopCloser(bytes.NewReader(r.ctx.PostBody()))
+}
+
+func (r *FastHTTPRequest) Header(key string) string {
+	return string(r.ctx.Request.Header.Peek(key))
+}
+
+func (r *FastHTTPRequest) Queries() url.Values {
+	return argsValues(r.ctx.QueryArgs(), nil)
+}
+
+func (r *FastHTTPRequest) PathValue(name string) string {
+	switch v := r.ctx.UserValue(name).(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	default:
+		return ""
+	}
+}
+
+// MultipartForm разбирает multipart форму; размер данных в памяти задается настройками fasthttp.Server,
+// поэтому maxMemory не используется
+func (r *FastHTTPRequest) MultipartForm(_ int64) (transport.Form, error) {
+	form, err := r.ctx.MultipartForm()
+	if err != nil {
+		return nil, err
+	}
+
+	return transport.MultipartFormWrap(form), nil
+}
+
+// URLEncodedForm возвращает параметры тела запроса, за которыми следуют параметры строки запроса, как в net/http
+func (r *FastHTTPRequest) URLEncodedForm() (url.Values, error) {
+	values := argsValues(r.ctx.PostArgs(), nil)
+
+	return argsValues(r.ctx.QueryArgs(), values), nil
+}
+
+func (r *FastHTTPRequest) ReadData(data any) error {
+	return r.readData(r, data)
+}
+
+func (r *FastHTTPRequest) ReadDataFunc() transport.ReadData {
+	return r.readData
+}
+
+func (r *FastHTTPRequest) SetCookie(c transport.Cookie) error {
+	cookie := fasthttp.AcquireCookie()
+	defer fasthttp.ReleaseCookie(cookie)
+
+	cookie.SetKey(c.Name)
+	cookie.SetValue(c.Value)
+	cookie.SetPath(c.Path)
+	cookie.SetDomain(c.Domain)
+	cookie.SetExpire(c.Expires)
+	cookie.SetMaxAge(c.MaxAge)
+	cookie.SetSecure(c.Secure)
+	cookie.SetHTTPOnly(c.HttpOnly)
+	cookie.SetSameSite(fasthttp.CookieSameSite(c.SameSite))
+	cookie.SetPartitioned(c.Partitioned)
+	r.ctx.Response.Header.SetCookie(cookie)
+
+	return nil
+}
+
+func (r *FastHTTPRequest) Cookie(name string) (string, error) {
+	v := r.ctx.Request.Header.Cookie(name)
+	if v == nil {
+		return "", http.ErrNoCookie
+	}
+
+	return string(v), nil
+}
+
+func (r *FastHTTPRequest) RemoteAddr() string {
+	return r.ctx.RemoteAddr().String()
+}
+
+// Detach возвращает запрос поверх копии fasthttp.RequestCtx: методы fasthttp.Request небезопасны
+// для одновременного вызова, а fasthttp переиспользует RequestCtx после возврата обработчика.
+// Копируются запрос и значения SetUserValue, в том числе параметры пути; контекст из WithContext сохраняется.
+func (r *FastHTTPRequest) Detach() transport.Request {
+	ctx := new(fasthttp.RequestCtx)
+	ctx.Init(&r.ctx.Request, r.ctx.RemoteAddr(), nil)
+	r.ctx.VisitUserValuesAll(func(key, value any) {
+		if b, ok := value.([]byte); ok {
+			value = bytes.Clone(b)
+		}
+		ctx.SetUserValue(key, value)
+	})
+
+	return &FastHTTPRequest{ctx: ctx, userCtx: r.userCtx, readData: r.readData}
+}
+
+// argsValues добавляет все значения args, включая повторяющиеся ключи, в values
+func argsValues(args *fasthttp.Args, values url.Values) url.Values {
+	if values == nil {
+		values = make(url.Values, args.Len())
+	}
+	args.VisitAll(func(key, value []byte) {
+		values.Add(string(key), string(value))
+	})
+
+	return values
+}
+
+// FastHTTPResponse адаптер для fasthttp.RequestCtx
+type FastHTTPResponse struct {
+	ctx           *fasthttp.RequestCtx
+	writeResponse transport.WriteResponse
+}
+
+func (r *FastHTTPResponse) SetStatusCode(code int) {
+	r.ctx.SetStatusCode(code)
+}
+
+func (r *FastHTTPResponse) SetHeader(key, value string) {
+	r.ctx.Response.Header.Set(key, value)
+}
+
+func (r *FastHTTPResponse) AddHeader(key, value string) {
+	r.ctx.Response.Header.Add(key, value)
+}
+
+func (r *FastHTTPResponse) WriteData(req transport.Request, data any) {
+	r.writeResponse(req, r, data)
+}
+
+func (r *FastHTTPResponse) WriteResponseFunc() transport.WriteResponse {
+	return r.writeResponse
+}
+
+func (r *FastHTTPResponse) Write(body []byte) (int, error) {
+	return r.ctx.Write(body)
+}
+
+func (r *FastHTTPResponse) SetBody(body []byte, statusCode int) int {
+	r.WriteHeader(statusCode)
+	n, _ := r.Write(body)
+
+	return n
+}
+
+func (r *FastHTTPResponse) WriteHeader(statusCode int) {
+	r.ctx.SetStatusCode(statusCode)
+}
+
+// Abandon передает записанный ответ в RequestCtx.TimeoutErrorWithResponse,
+// после чего fasthttp.Server не переиспользует RequestCtx
+func (r *FastHTTPResponse) Abandon() {
+	r.ctx.TimeoutErrorWithResponse(&r.ctx.Response)
+}
+
+// FastHTTPAdapter адаптер для fasthttp
+type FastHTTPAdapter struct {
+	writeResponse transport.WriteResponse
+	readData      transport.ReadData
+	notFound      transport.Handler
+	errorHandler  transport.ErrorHandler
+}
+
+// serveNotFound обрабатывает запрос, не совпавший ни с одним маршрутом
+func (a *FastHTTPAdapter) serveNotFound(req transport.Request, resp transport.Response) error {
+	if a.notFound != nil {
+		return a.notFound(req, resp)
+	}
+
+	return transport.NotFound(req, resp)
+}
+
+func (a *FastHTTPAdapter) AdaptHandler(handler transport.Handler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		req := &FastHTTPRequest{ctx: ctx, readData: a.readData}
+		resp := &FastHTTPResponse{ctx: ctx, writeResponse: a.writeResponse}
+		if err := handler(req, resp); err != nil {
+			a.errorHandler(req, resp, err)
+		}
+	}
+}
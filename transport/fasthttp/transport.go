@@ -0,0 +1,322 @@
+package fasthttp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/fasthttp/router"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
+
+	"github.com/go-mosaic/runtime/transport"
+	"github.com/go-mosaic/runtime/transport/server"
+)
+
+// FastHTTPToMiddleware преобразует middleware fasthttp в универсальный Middleware.
+// Значения, установленные middleware через RequestCtx.SetUserValue, доступны через Context запроса.
+func FastHTTPToMiddleware(fastMiddleware func(fasthttp.RequestHandler) fasthttp.RequestHandler) transport.Middleware {
+	return func(next transport.Handler) transport.Handler {
+		return func(req transport.Request, resp transport.Response) error {
+			fastReq, ok := transport.UnwrapRequest(req).(*FastHTTPRequest)
+			if !ok {
+				return fmt.Errorf("%w: %T", transport.ErrAdapterMismatch, req)
+			}
+
+			var err error
+			fastHandler := func(*fasthttp.RequestCtx) {
+				err = next(req, resp)
+			}
+
+			fastMiddleware(fastHandler)(fastReq.ctx)
+
+			return err
+		}
+	}
+}
+
+// patternSyntax синтаксис шаблонов fasthttp/router: параметры "{name}", catch-all "{name:*}"
+var patternSyntax = transport.PatternSyntax{
+	Param:    func(name string) string { return "{" + name + "}" },
+	CatchAll: func(name string) (string, string) { return "{" + name + ":*}", name },
+}
+
+// FastHTTPTransport реализация Transport на основе fasthttp и fasthttp/router
+type FastHTTPTransport struct {
+	router      *router.Router
+	slashed     *router.Router
+	server      *fasthttp.Server
+	middlewares *transport.MiddlewareStack
+	adapter     *FastHTTPAdapter
+	routes      *transport.RouteTable
+	prefix      string
+	dispatchers map[string]*transport.Dispatcher
+}
+
+// NewFastHTTPTransport создает новый экземпляр FastHTTPTransport.
+// Для Request.Pattern в роутере включается SaveMatchedRoutePath.
+func NewFastHTTPTransport(r *router.Router, opts ...transport.Option) *FastHTTPTransport {
+	config := transport.NewConfig(opts...)
+
+	t := &FastHTTPTransport{
+		router:      r,
+		slashed:     router.New(),
+		server:      &fasthttp.Server{},
+		middlewares: transport.NewMiddlewareStack(nil),
+		routes:      transport.NewRouteTable(),
+		dispatchers: make(map[string]*transport.Dispatcher),
+		adapter: &FastHTTPAdapter{
+			writeResponse: config.WriteResponse(),
+			readData:      config.ReadData(),
+			notFound:      config.NotFound(),
+			errorHandler:  config.ErrorHandler(),
+		},
+	}
+	t.server.Handler = t.Handler
+	r.SaveMatchedRoutePath = true
+	t.slashed.SaveMatchedRoutePath = true
+	r.NotFound = t.adapter.AdaptHandler(t.middlewares.Handler(t.adapter.serveNotFound))
+
+	return t
+}
+
+// AddRoute регистрирует маршрут с middleware
+func (t *FastHTTPTransport) AddRoute(method, path string, handler transport.Handler, middlewares ...transport.Middleware) {
+	t.AddRouteWith(method, path, handler, transport.MiddlewareOptions(middlewares...)...)
+}
+
+// AddRouteWith регистрирует маршрут с опциями: именем, тегами, метаданными и middleware
+func (t *FastHTTPTransport) AddRouteWith(method, path string, handler transport.Handler, opts ...transport.RouteOption) {
+	// группы fasthttp/router не допускают пустой путь, поэтому маршруты регистрируются в роутере по полному шаблону
+	pattern, native := transport.MustNativePattern(transport.JoinPattern(t.prefix, path), patternSyntax)
+	route := transport.NewRoute(method, path, opts...)
+	if err := t.routes.Add(route); err != nil {
+		panic(err)
+	}
+
+	h := t.middlewares.Handler(pattern.Bind(route.Handler(handler), native.Aliases, route.NotFound(t.adapter.serveNotFound)))
+	if err := t.dispatcher(pattern, native).Handle(method, h); err != nil {
+		panic(fmt.Errorf("%w: %s", err, pattern))
+	}
+}
+
+// dispatcher возвращает Dispatcher шаблона, регистрируя его в роутере для всех методов при первом обращении.
+// fasthttp/router не позволяет зарегистрировать путь одновременно с завершающим "/" и без него,
+// поэтому вариант с "/" шаблона с необязательным завершающим "/" регистрируется в отдельном роутере.
+func (t *FastHTTPTransport) dispatcher(pattern transport.Pattern, native transport.NativePattern) *transport.Dispatcher {
+	key := strings.Join(native.Paths, " ")
+	if d, ok := t.dispatchers[key]; ok {
+		return d
+	}
+
+	d := transport.NewDispatcher(t.middlewares)
+	t.dispatchers[key] = d
+
+	h := t.adapter.AdaptHandler(d.Serve)
+	if pattern.TrailingSlash == transport.TrailingSlashOptional {
+		t.router.ANY(native.Paths[0], h)
+		t.slashed.ANY(native.Paths[1], h)
+	} else {
+		t.router.ANY(native.Paths[0], h)
+	}
+
+	return d
+}
+
+// NotFound задает handler для запросов, не совпавших ни с одним маршрутом.
+// Ответ проходит через глобальные middleware.
+func (t *FastHTTPTransport) NotFound(handler transport.Handler) {
+	t.adapter.notFound = handler
+}
+
+// Routes возвращает таблицу зарегистрированных маршрутов
+func (t *FastHTTPTransport) Routes() *transport.RouteTable {
+	return t.routes
+}
+
+// Group возвращает группу маршрутов под префиксом prefix
+func (t *FastHTTPTransport) Group(prefix string, middlewares ...transport.Middleware) transport.Transport {
+	transport.MustNativePattern(prefix, patternSyntax)
+
+	group := &FastHTTPTransport{
+		router:      t.router,
+		slashed:     t.slashed,
+		server:      t.server,
+		middlewares: transport.NewMiddlewareStack(t.middlewares, middlewares...),
+		adapter:     t.adapter,
+		routes:      transport.NewRouteTable(),
+		prefix:      transport.JoinPattern(t.prefix, prefix),
+		dispatchers: t.dispatchers,
+	}
+	if err := t.routes.Mount(prefix, group.routes); err != nil {
+		panic(err)
+	}
+
+	return group
+}
+
+// Mount подключает транспорт sub под префиксом prefix, удаляя префикс из пути запроса.
+// Помимо FastHTTPTransport подключается любой транспорт, реализующий http.Handler.
+func (t *FastHTTPTransport) Mount(prefix string, sub transport.Transport) {
+	if err := t.routes.Mount(prefix, sub.Routes()); err != nil {
+		panic(err)
+	}
+
+	full := transport.JoinPattern(t.prefix, prefix)
+
+	var handler fasthttp.RequestHandler
+	if s, ok := sub.(*FastHTTPTransport); ok {
+		handler = stripPrefix(full, s.Handler)
+	} else {
+		h, err := transport.HandlerOf(sub)
+		if err != nil {
+			panic(err)
+		}
+
+		handler = fasthttpadaptor.NewFastHTTPHandler(transport.StripPrefix(full, h))
+	}
+
+	// middleware родителя применяются и к маршрутам подключенного транспорта
+	handler = t.adapter.AdaptHandler(t.middlewares.Handler(mountHandler(handler)))
+
+	_, native := transport.MustNativePattern(full, patternSyntax)
+	base := strings.TrimSuffix(native.Paths[0], "/")
+	if base != "" {
+		t.router.ANY(base, handler)
+	}
+	t.router.ANY(base+"/{mount:*}", handler)
+}
+
+// mountHandler преобразует обработчик подключенного транспорта в универсальный Handler
+func mountHandler(h fasthttp.RequestHandler) transport.Handler {
+	return func(req transport.Request, resp transport.Response) error {
+		fastReq, ok := transport.UnwrapRequest(req).(*FastHTTPRequest)
+		if !ok {
+			return fmt.Errorf("%w: %T", transport.ErrAdapterMismatch, req)
+		}
+
+		h(fastReq.ctx)
+
+		if _, ok := resp.(*FastHTTPResponse); !ok {
+			// ответ подключенного транспорта передается через обёртки middleware
+			replayResponse(&fastReq.ctx.Response, resp)
+		}
+
+		return nil
+	}
+}
+
+// replayResponse переносит записанный в r ответ в resp.
+// Заголовки копируются, только если resp не пишет в r сам.
+func replayResponse(r *fasthttp.Response, resp transport.Response) {
+	body := append([]byte(nil), r.Body()...)
+	statusCode := r.StatusCode()
+	r.ResetBody()
+
+	if _, ok := transport.UnwrapResponse(resp).(*FastHTTPResponse); !ok {
+		r.Header.VisitAll(func(key, value []byte) {
+			if !bytes.EqualFold(key, []byte(fasthttp.HeaderContentLength)) {
+				resp.SetHeader(string(key), string(value))
+			}
+		})
+	}
+
+	resp.SetBody(body, statusCode)
+}
+
+// stripPrefix удаляет из пути запроса сегменты префикса prefix, который может содержать параметры
+func stripPrefix(prefix string, h fasthttp.RequestHandler) fasthttp.RequestHandler {
+	p, err := transport.ParsePattern(prefix)
+	if err != nil {
+		panic(err)
+	}
+
+	return func(ctx *fasthttp.RequestCtx) {
+		rest := string(ctx.Request.URI().PathOriginal())
+		for range p.Segments {
+			i := strings.IndexByte(rest[1:], '/')
+			if i < 0 {
+				rest = "/"
+				break
+			}
+			rest = rest[i+1:]
+		}
+
+		if query := ctx.Request.URI().QueryString(); len(query) > 0 {
+			rest += "?" + string(query)
+		}
+		ctx.Request.SetRequestURI(rest)
+
+		h(ctx)
+	}
+}
+
+// Handler обрабатывает запрос; передается в fasthttp.Server
+func (t *FastHTTPTransport) Handler(ctx *fasthttp.RequestCtx) {
+	if path := ctx.Path(); len(path) > 1 && path[len(path)-1] == '/' {
+		if h, _ := t.slashed.Lookup(router.MethodWild, string(path), ctx); h != nil {
+			h(ctx)
+			return
+		}
+	}
+
+	t.router.Handler(ctx)
+}
+
+// Use добавляет глобальные middleware; они применяются и к маршрутам, зарегистрированным ранее
+func (t *FastHTTPTransport) Use(middlewares ...transport.Middleware) {
+	t.middlewares.Use(middlewares...)
+}
+
+// Serve обслуживает соединения listener сервером fasthttp
+func (t *FastHTTPTransport) Serve(ln net.Listener, timeouts server.Timeouts) error {
+	t.server.ReadTimeout = timeouts.Read
+	t.server.WriteTimeout = timeouts.Write
+	t.server.IdleTimeout = timeouts.Idle
+	SetReadHeaderTimeout(t.server, timeouts.ReadHeader)
+
+	return t.server.Serve(ln)
+}
+
+// noReadTimeout таймаут чтения тела без ограничения: HeaderReceived учитывает только положительные значения
+const noReadTimeout = time.Duration(math.MaxInt64)
+
+// SetReadHeaderTimeout ограничивает чтение заголовков запроса сервером srv временем readHeader.
+// fasthttp не различает таймауты заголовков и тела, поэтому ReadTimeout сервера становится таймаутом заголовков,
+// а после их получения HeaderReceived продлевает чтение тела на прежний ReadTimeout или снимает ограничение.
+// Если IdleTimeout не задан, ожидание следующего запроса ограничивается прежним ReadTimeout или readHeader.
+func SetReadHeaderTimeout(srv *fasthttp.Server, readHeader time.Duration) {
+	if readHeader <= 0 {
+		return
+	}
+
+	body := srv.ReadTimeout
+	if body <= 0 {
+		body = noReadTimeout
+	} else if srv.IdleTimeout <= 0 {
+		srv.IdleTimeout = body
+	}
+	srv.ReadTimeout = readHeader
+
+	received := srv.HeaderReceived
+	srv.HeaderReceived = func(header *fasthttp.RequestHeader) fasthttp.RequestConfig {
+		var config fasthttp.RequestConfig
+		if received != nil {
+			config = received(header)
+		}
+		if config.ReadTimeout <= 0 {
+			config.ReadTimeout = body
+		}
+
+		return config
+	}
+}
+
+// Shutdown завершает сервер fasthttp, дожидаясь обработки текущих запросов
+func (t *FastHTTPTransport) Shutdown(ctx context.Context) error {
+	return t.server.ShutdownWithContext(ctx)
+}
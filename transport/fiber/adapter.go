@@ -1,11 +1,14 @@
 package fiber
 
 import (
+	"bytes"
 	"context"
 	"io"
 	"net/url"
+	"strings"
 
 	"github.com/gofiber/fiber/v3"
+	"github.com/valyala/fasthttp"
 
 	"github.com/go-mosaic/runtime/transport"
 )
@@ -32,15 +35,19 @@ func (f *fiberReadCloser) Read(p []byte) (n int, err error) {
 // FiberRequest адаптер для fiber.Ctx
 type FiberRequest struct {
 	ctx      fiber.Ctx
+	userCtx  context.Context
 	readData transport.ReadData
 }
 
 func (r *FiberRequest) WithContext(ctx context.Context) transport.Request {
-	// TODO: необходимо найти реализацию
-	return r
+	return &FiberRequest{ctx: r.ctx, userCtx: ctx, readData: r.readData}
 }
 
 func (r *FiberRequest) Context() context.Context {
+	if r.userCtx != nil {
+		return r.userCtx
+	}
+
 	return r.ctx.RequestCtx()
 }
 
@@ -52,6 +59,10 @@ func (r *FiberRequest) Path() string {
 	return r.ctx.Path()
 }
 
+func (r *FiberRequest) Pattern() string {
+	return r.ctx.Route().Path
+}
+
 func (r *FiberRequest) Body() io.ReadCloser {
 	return &fiberReadCloser{data: r.ctx.Body()}
 }
@@ -90,6 +101,10 @@ func (r *FiberRequest) ReadData(data any) error {
 	return r.readData(r, data)
 }
 
+func (r *FiberRequest) ReadDataFunc() transport.ReadData {
+	return r.readData
+}
+
 func (r *FiberRequest) SetCookie(c transport.Cookie) error {
 	r.ctx.Cookie(&fiber.Cookie{
 		Name:        c.Name,
@@ -111,6 +126,131 @@ func (r *FiberRequest) Cookie(name string) (string, error) {
 	return r.ctx.Cookies(name), nil
 }
 
+func (r *FiberRequest) RemoteAddr() string {
+	return r.ctx.RequestCtx().RemoteAddr().String()
+}
+
+// Detach возвращает копию запроса, не связанную с fiber.Ctx: fiber переиспользует Ctx после возврата обработчика.
+// Копируются запрос fasthttp, значения Locals, параметры пути и шаблон маршрута.
+func (r *FiberRequest) Detach() transport.Request {
+	fctx := new(fasthttp.RequestCtx)
+	fctx.Init(&r.ctx.RequestCtx().Request, r.ctx.RequestCtx().RemoteAddr(), nil)
+	r.ctx.RequestCtx().VisitUserValuesAll(func(key, value any) {
+		fctx.SetUserValue(key, value)
+	})
+
+	params := make(map[string]string, len(r.ctx.Route().Params))
+	for _, name := range r.ctx.Route().Params {
+		params[name] = strings.Clone(r.ctx.Params(name))
+	}
+
+	return &detachedRequest{
+		fctx:     fctx,
+		userCtx:  r.userCtx,
+		path:     strings.Clone(r.ctx.Path()),
+		pattern:  strings.Clone(r.ctx.Route().Path),
+		body:     bytes.Clone(r.ctx.Body()),
+		params:   params,
+		readData: r.readData,
+	}
+}
+
+// detachedRequest копия запроса fiber поверх собственного fasthttp.RequestCtx
+type detachedRequest struct {
+	fctx     *fasthttp.RequestCtx
+	userCtx  context.Context
+	path     string
+	pattern  string
+	body     []byte
+	params   map[string]string
+	readData transport.ReadData
+}
+
+func (r *detachedRequest) WithContext(ctx context.Context) transport.Request {
+	detached := *r
+	detached.userCtx = ctx
+
+	return &detached
+}
+
+func (r *detachedRequest) Context() context.Context {
+	if r.userCtx != nil {
+		return r.userCtx
+	}
+
+	return r.fctx
+}
+
+func (r *detachedRequest) Method() string {
+	return string(r.fctx.Method())
+}
+
+func (r *detachedRequest) Path() string {
+	return r.path
+}
+
+func (r *detachedRequest) Pattern() string {
+	return r.pattern
+}
+
+func (r *detachedRequest) Body() io.ReadCloser {
+	return &fiberReadCloser{data: r.body}
+}
+
+func (r *detachedRequest) Header(key string) string {
+	return string(r.fctx.Request.Header.Peek(key))
+}
+
+func (r *detachedRequest) Queries() url.Values {
+	m := make(url.Values, r.fctx.QueryArgs().Len())
+	r.fctx.QueryArgs().VisitAll(func(key, value []byte) {
+		m[string(key)] = []string{string(value)}
+	})
+
+	return m
+}
+
+func (r *detachedRequest) PathValue(name string) string {
+	if v, ok := r.params[name]; ok {
+		return v
+	}
+	if name == "*" {
+		// fiber нумерует catch-all параметры, "*" соответствует первому из них
+		return r.params["*1"]
+	}
+
+	return ""
+}
+
+func (r *detachedRequest) MultipartForm(_ int64) (transport.Form, error) {
+	form, err := r.fctx.MultipartForm()
+	if err != nil {
+		return nil, err
+	}
+
+	return transport.MultipartFormWrap(form), nil
+}
+
+func (r *detachedRequest) URLEncodedForm() (url.Values, error) {
+	return url.Values{}, nil
+}
+
+func (r *detachedRequest) ReadData(data any) error {
+	return r.readData(r, data)
+}
+
+func (r *detachedRequest) ReadDataFunc() transport.ReadData {
+	return r.readData
+}
+
+func (r *detachedRequest) Cookie(name string) (string, error) {
+	return string(r.fctx.Request.Header.Cookie(name)), nil
+}
+
+func (r *detachedRequest) RemoteAddr() string {
+	return r.fctx.RemoteAddr().String()
+}
+
 // FiberResponse адаптер для fiber.Ctx
 type FiberResponse struct {
 	ctx           fiber.Ctx
@@ -125,10 +265,18 @@ func (r *FiberResponse) SetHeader(key, value string) {
 	r.ctx.Set(key, value)
 }
 
+func (r *FiberResponse) AddHeader(key, value string) {
+	r.ctx.Response().Header.Add(key, value)
+}
+
 func (r *FiberResponse) WriteData(req transport.Request, data any) {
 	r.writeResponse(req, r, data)
 }
 
+func (r *FiberResponse) WriteResponseFunc() transport.WriteResponse {
+	return r.writeResponse
+}
+
 func (r *FiberResponse) Write(body []byte) (int, error) {
 	return r.ctx.Write(body)
 }
@@ -144,10 +292,27 @@ func (r *FiberResponse) WriteHeader(statusCode int) {
 	r.ctx.Status(statusCode)
 }
 
+// Abandon передает записанный ответ в RequestCtx.TimeoutErrorWithResponse,
+// после чего fasthttp.Server не переиспользует RequestCtx
+func (r *FiberResponse) Abandon() {
+	r.ctx.RequestCtx().TimeoutErrorWithResponse(r.ctx.Response())
+}
+
 // FiberAdapter адаптер для fiber
 type FiberAdapter struct {
 	writeResponse transport.WriteResponse
 	readData      transport.ReadData
+	notFound      transport.Handler
+	errorHandler  transport.ErrorHandler
+}
+
+// serveNotFound обрабатывает запрос, не совпавший ни с одним маршрутом
+func (a *FiberAdapter) serveNotFound(req transport.Request, resp transport.Response) error {
+	if a.notFound != nil {
+		return a.notFound(req, resp)
+	}
+
+	return transport.NotFound(req, resp)
 }
 
 func (a *FiberAdapter) AdaptHandler(handler transport.Handler) fiber.Handler {
@@ -155,7 +320,7 @@ func (a *FiberAdapter) AdaptHandler(handler transport.Handler) fiber.Handler {
 		req := &FiberRequest{ctx: c, readData: a.readData}
 		resp := &FiberResponse{ctx: c, writeResponse: a.writeResponse}
 		if err := handler(req, resp); err != nil {
-			a.writeResponse(req, resp, err)
+			a.errorHandler(req, resp, err)
 		}
 		return nil
 	}
@@ -1,58 +1,371 @@
 package fiber
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+
 	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/adaptor"
+	"github.com/valyala/fasthttp"
 
 	"github.com/go-mosaic/runtime/transport"
+	transportfasthttp "github.com/go-mosaic/runtime/transport/fasthttp"
+	"github.com/go-mosaic/runtime/transport/server"
 )
 
-// FiberToMiddleware преобразует middleware fiber в универсальный Middleware
+// fiberCallKey ключ Locals вызова следующего handler из вспомогательного fiber.App FiberToMiddleware
+type fiberCallKey struct{}
+
+// fiberCall вызов следующего handler универсальной цепочки и ошибка, которой завершилась middleware fiber
+type fiberCall struct {
+	ctx  context.Context
+	next func(ctx context.Context) error
+	err  error
+}
+
+// FiberToMiddleware преобразует middleware fiber в универсальный Middleware.
+// Middleware выполняется во вспомогательном fiber.App над тем же fasthttp.RequestCtx: c.Next() вызывает
+// следующий handler, а контекст, заданный через fiber.Ctx.SetContext, передается дальше по цепочке.
 func FiberToMiddleware(fiberMiddleware fiber.Handler) transport.Middleware {
+	app := fiber.New(fiber.Config{
+		// ошибка middleware или следующего handler возвращается из универсальной middleware
+		ErrorHandler: func(c fiber.Ctx, err error) error {
+			if call, ok := c.Locals(fiberCallKey{}).(*fiberCall); ok {
+				call.err = err
+			}
+			return nil
+		},
+	})
+	app.Use(func(c fiber.Ctx) error {
+		if call, ok := c.Locals(fiberCallKey{}).(*fiberCall); ok {
+			c.SetContext(call.ctx)
+		}
+
+		return c.Next()
+	})
+	app.Use(fiberMiddleware)
+	app.Use(func(c fiber.Ctx) error {
+		call, ok := c.Locals(fiberCallKey{}).(*fiberCall)
+		if !ok {
+			return nil
+		}
+
+		return call.next(c.Context())
+	})
+	handler := app.Handler()
+
 	return func(next transport.Handler) transport.Handler {
 		return func(req transport.Request, resp transport.Response) error {
-			if err := fiberMiddleware(req.(*FiberRequest).ctx); err != nil {
-				return err
+			fiberReq, ok := transport.UnwrapRequest(req).(*FiberRequest)
+			if !ok {
+				return fmt.Errorf("%w: %T", transport.ErrAdapterMismatch, req)
+			}
+
+			call := &fiberCall{
+				ctx: req.Context(),
+				next: func(ctx context.Context) error {
+					// контекст, измененный middleware fiber, передается дальше по цепочке
+					return next(req.WithContext(ctx), resp)
+				},
 			}
 
-			return next(req, resp)
+			// вложенные FiberToMiddleware используют тот же RequestCtx, поэтому внешний вызов восстанавливается
+			rctx := fiberReq.ctx.RequestCtx()
+			prev := rctx.UserValue(fiberCallKey{})
+			rctx.SetUserValue(fiberCallKey{}, call)
+			defer func() {
+				if prev == nil {
+					rctx.RemoveUserValue(fiberCallKey{})
+				} else {
+					rctx.SetUserValue(fiberCallKey{}, prev)
+				}
+			}()
+
+			handler(rctx)
+
+			return call.err
 		}
 	}
 }
 
+// paramName допустимое имя параметра fiber: символы "-" и "." fiber считает разделителями
+var paramName = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// patternSyntax возвращает синтаксис шаблонов fiber: параметры ":name", catch-all доступен только как "*"
+func patternSyntax(strict bool) transport.PatternSyntax {
+	return transport.PatternSyntax{
+		Param:    func(name string) string { return ":" + name },
+		CatchAll: func(string) (string, string) { return "*", "*" },
+		Validate: func(p transport.Pattern) error {
+			if !strict && p.TrailingSlash == transport.TrailingSlashRequired {
+				return errors.New("fiber without StrictRouting cannot require a trailing slash")
+			}
+			for _, seg := range p.Segments {
+				if seg.Param != "" && !seg.CatchAll && !paramName.MatchString(seg.Param) {
+					return fmt.Errorf("fiber parameter names may contain only letters, digits and _, got %q", seg.Param)
+				}
+			}
+			return nil
+		},
+	}
+}
+
 // FiberTransport реализация Transport с использованием fiber
 type FiberTransport struct {
-	app     *fiber.App
-	adapter *FiberAdapter
+	app         *fiber.App
+	router      fiber.Router
+	middlewares *transport.MiddlewareStack
+	adapter     *FiberAdapter
+	routes      *transport.RouteTable
+	prefix      string
+	dispatchers map[string]*transport.Dispatcher
 }
 
 // NewFiberTransport создает новый экземпляр FiberTransport
-func NewFiberTransport(app *fiber.App) *FiberTransport {
-	return &FiberTransport{
-		app: app,
+func NewFiberTransport(app *fiber.App, opts ...transport.Option) *FiberTransport {
+	config := transport.NewConfig(opts...)
+
+	t := &FiberTransport{
+		app:         app,
+		router:      app,
+		middlewares: transport.NewMiddlewareStack(nil),
+		routes:      transport.NewRouteTable(),
+		dispatchers: make(map[string]*transport.Dispatcher),
 		adapter: &FiberAdapter{
-			writeResponse: transport.DefaultWriteResponse,
-			readData:      transport.DefaultReadData,
+			writeResponse: config.WriteResponse(),
+			readData:      config.ReadData(),
+			notFound:      config.NotFound(),
+			errorHandler:  config.ErrorHandler(),
 		},
 	}
+
+	// fiber не позволяет задать handler 404 без ErrorHandler приложения,
+	// поэтому ошибка "маршрут не найден" перехватывается middleware, зарегистрированной первой
+	notFound := t.adapter.AdaptHandler(t.middlewares.Handler(t.adapter.serveNotFound))
+	app.Use(func(c fiber.Ctx) error {
+		err := c.Next()
+
+		var fiberErr *fiber.Error
+		if errors.As(err, &fiberErr) && fiberErr.Code == fiber.StatusNotFound {
+			return notFound(c)
+		}
+
+		return err
+	})
+
+	return t
 }
 
+// AddRoute регистрирует маршрут с middleware
 func (t *FiberTransport) AddRoute(method, path string, handler transport.Handler, middlewares ...transport.Middleware) {
-	wrappedHandler := handler
-	for _, mw := range middlewares {
-		wrappedHandler = mw(wrappedHandler)
+	t.AddRouteWith(method, path, handler, transport.MiddlewareOptions(middlewares...)...)
+}
+
+// AddRouteWith регистрирует маршрут с опциями: именем, тегами, метаданными и middleware
+func (t *FiberTransport) AddRouteWith(method, path string, handler transport.Handler, opts ...transport.RouteOption) {
+	strict := t.app.Config().StrictRouting
+	// ограничения проверяются по полному шаблону, а в группу fiber регистрируется относительный путь
+	pattern, full := transport.MustNativePattern(transport.JoinPattern(t.prefix, path), patternSyntax(strict))
+	_, native := transport.MustNativePattern(path, patternSyntax(strict))
+	route := transport.NewRoute(method, path, opts...)
+	if err := t.routes.Add(route); err != nil {
+		panic(err)
 	}
 
-	t.app.Add([]string{method}, path, t.adapter.AdaptHandler(wrappedHandler))
+	paths := native.Paths
+	if t.prefix != "" && path == "/" {
+		// корневой маршрут группы совпадает с префиксом как с завершающим "/", так и без него
+		paths = []string{"", "/"}
+	}
+	if !strict {
+		// без StrictRouting fiber сам не различает завершающий "/"
+		paths = paths[:1]
+	}
+
+	h := t.middlewares.Handler(pattern.Bind(route.Handler(handler), native.Aliases, route.NotFound(t.adapter.serveNotFound)))
+	if err := t.dispatcher(full, paths).Handle(method, h); err != nil {
+		panic(fmt.Errorf("%w: %s", err, pattern))
+	}
 }
 
-func (t *FiberTransport) Use(middlewares ...transport.Middleware) {
-	for _, mw := range middlewares {
-		t.app.Use(func(c fiber.Ctx) error {
-			wrappedHandler := mw(func(req transport.Request, resp transport.Response) error {
-				return c.Next()
-			})
+// dispatcher возвращает Dispatcher шаблона, регистрируя его в роутере для всех методов при первом обращении
+func (t *FiberTransport) dispatcher(full transport.NativePattern, paths []string) *transport.Dispatcher {
+	key := strings.Join(full.Paths, " ")
+	if d, ok := t.dispatchers[key]; ok {
+		return d
+	}
 
-			return wrappedHandler(&FiberRequest{ctx: c}, &FiberResponse{ctx: c})
+	d := transport.NewDispatcher(t.middlewares)
+	t.dispatchers[key] = d
+
+	h := t.adapter.AdaptHandler(d.Serve)
+	for _, p := range paths {
+		t.router.All(p, h)
+	}
+
+	return d
+}
+
+// NotFound задает handler для запросов, не совпавших ни с одним маршрутом.
+// Ответ проходит через глобальные middleware.
+func (t *FiberTransport) NotFound(handler transport.Handler) {
+	t.adapter.notFound = handler
+}
+
+// Routes возвращает таблицу зарегистрированных маршрутов
+func (t *FiberTransport) Routes() *transport.RouteTable {
+	return t.routes
+}
+
+// Group возвращает группу маршрутов под префиксом prefix на основе fiber.Router.Group
+func (t *FiberTransport) Group(prefix string, middlewares ...transport.Middleware) transport.Transport {
+	_, native := transport.MustNativePattern(prefix, patternSyntax(t.app.Config().StrictRouting))
+
+	group := &FiberTransport{
+		app:         t.app,
+		router:      t.router.Group(native.Paths[0]),
+		middlewares: transport.NewMiddlewareStack(t.middlewares, middlewares...),
+		adapter:     t.adapter,
+		routes:      transport.NewRouteTable(),
+		prefix:      transport.JoinPattern(t.prefix, prefix),
+		dispatchers: t.dispatchers,
+	}
+	if err := t.routes.Mount(prefix, group.routes); err != nil {
+		panic(err)
+	}
+
+	return group
+}
+
+// Mount подключает транспорт sub под префиксом prefix.
+// FiberTransport монтируется как вложенное приложение fiber, остальные транспорты — как http.Handler.
+func (t *FiberTransport) Mount(prefix string, sub transport.Transport) {
+	if err := t.routes.Mount(prefix, sub.Routes()); err != nil {
+		panic(err)
+	}
+
+	_, native := transport.MustNativePattern(prefix, patternSyntax(t.app.Config().StrictRouting))
+
+	if s, ok := sub.(*FiberTransport); ok {
+		// middleware родителя выполняются перед маршрутами вложенного приложения
+		t.router.Use(native.Paths[0], t.mountMiddleware())
+		t.router.Use(native.Paths[0], s.app)
+	} else {
+		h, err := transport.HandlerOf(sub)
+		if err != nil {
+			panic(err)
+		}
+
+		base := strings.TrimSuffix(native.Paths[0], "/")
+		handler := t.adapter.AdaptHandler(t.middlewares.Handler(
+			mountHandler(adaptor.HTTPHandler(transport.StripPrefix(transport.JoinPattern(t.prefix, prefix), h))),
+		))
+		if base != "" {
+			t.router.All(base, handler)
+		}
+		t.router.All(base+"/*", handler)
+	}
+}
+
+// mountNextErrKey ключ Locals для ошибки маршрутов вложенного приложения
+type mountNextErrKey struct{}
+
+// mountMiddleware возвращает fiber middleware, применяющий middleware транспорта к вложенному приложению.
+// Ошибка следующих обработчиков, например fiber.ErrNotFound, возвращается fiber без изменений.
+func (t *FiberTransport) mountMiddleware() fiber.Handler {
+	handler := t.adapter.AdaptHandler(t.middlewares.Handler(func(req transport.Request, _ transport.Response) error {
+		fiberReq, ok := transport.UnwrapRequest(req).(*FiberRequest)
+		if !ok {
+			return fmt.Errorf("%w: %T", transport.ErrAdapterMismatch, req)
+		}
+
+		if err := fiberReq.ctx.Next(); err != nil {
+			fiberReq.ctx.Locals(mountNextErrKey{}, err)
+		}
+
+		return nil
+	}))
+
+	return func(c fiber.Ctx) error {
+		if err := handler(c); err != nil {
+			return err
+		}
+		if err, ok := c.Locals(mountNextErrKey{}).(error); ok {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// mountHandler преобразует обработчик подключенного транспорта в универсальный Handler
+func mountHandler(h fiber.Handler) transport.Handler {
+	return func(req transport.Request, resp transport.Response) error {
+		fiberReq, ok := transport.UnwrapRequest(req).(*FiberRequest)
+		if !ok {
+			return fmt.Errorf("%w: %T", transport.ErrAdapterMismatch, req)
+		}
+
+		if err := h(fiberReq.ctx); err != nil {
+			return err
+		}
+
+		if _, ok := resp.(*FiberResponse); !ok {
+			// ответ подключенного транспорта передается через обёртки middleware
+			replayResponse(fiberReq.ctx.Response(), resp)
+		}
+
+		return nil
+	}
+}
+
+// replayResponse переносит записанный в r ответ в resp.
+// Заголовки копируются, только если resp не пишет в r сам.
+func replayResponse(r *fasthttp.Response, resp transport.Response) {
+	body := append([]byte(nil), r.Body()...)
+	statusCode := r.StatusCode()
+	r.ResetBody()
+
+	if _, ok := transport.UnwrapResponse(resp).(*FiberResponse); !ok {
+		r.Header.VisitAll(func(key, value []byte) {
+			if !bytes.EqualFold(key, []byte(fasthttp.HeaderContentLength)) {
+				resp.SetHeader(string(key), string(value))
+			}
 		})
 	}
+
+	resp.SetBody(body, statusCode)
+}
+
+// Use добавляет глобальные middleware; они применяются и к маршрутам, зарегистрированным ранее
+func (t *FiberTransport) Use(middlewares ...transport.Middleware) {
+	t.middlewares.Use(middlewares...)
+}
+
+// Serve обслуживает соединения listener сервером приложения fiber; ненулевые таймауты
+// заменяют заданные в конфигурации приложения
+func (t *FiberTransport) Serve(ln net.Listener, timeouts server.Timeouts) error {
+	srv := t.app.Server()
+	if timeouts.Read > 0 {
+		srv.ReadTimeout = timeouts.Read
+	}
+	if timeouts.Write > 0 {
+		srv.WriteTimeout = timeouts.Write
+	}
+	if timeouts.Idle > 0 {
+		srv.IdleTimeout = timeouts.Idle
+	}
+	transportfasthttp.SetReadHeaderTimeout(srv, timeouts.ReadHeader)
+
+	return t.app.Listener(ln, fiber.ListenConfig{DisableStartupMessage: true})
+}
+
+// Shutdown завершает сервер приложения fiber
+func (t *FiberTransport) Shutdown(ctx context.Context) error {
+	return t.app.ShutdownWithContext(ctx)
 }
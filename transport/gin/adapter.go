@@ -0,0 +1,198 @@
+package gin
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/go-mosaic/runtime/transport"
+)
+
+// GinRequest адаптер для gin.Context
+type GinRequest struct {
+	ctx      *gin.Context
+	req      *http.Request
+	readData transport.ReadData
+}
+
+func (r *GinRequest) WithContext(ctx context.Context) transport.Request {
+	return &GinRequest{ctx: r.ctx, req: r.request().WithContext(ctx), readData: r.readData}
+}
+
+// Detach возвращает запрос с копией gin.Context: gin переиспользует Context после возврата обработчика
+func (r *GinRequest) Detach() transport.Request {
+	return &GinRequest{ctx: r.ctx.Copy(), req: r.req, readData: r.readData}
+}
+
+// request возвращает запрос с учетом контекста, установленного через WithContext
+func (r *GinRequest) request() *http.Request {
+	if r.req != nil {
+		return r.req
+	}
+
+	return r.ctx.Request
+}
+
+func (r *GinRequest) Context() context.Context {
+	return r.request().Context()
+}
+
+func (r *GinRequest) Method() string {
+	return r.request().Method
+}
+
+func (r *GinRequest) Path() string {
+	return r.request().URL.Path
+}
+
+func (r *GinRequest) Pattern() string {
+	return r.ctx.FullPath()
+}
+
+func (r *GinRequest) Body() io.ReadCloser {
+	return r.request().Body
+}
+
+func (r *GinRequest) Header(key string) string {
+	return r.request().Header.Get(key)
+}
+
+func (r *GinRequest) Queries() url.Values {
+	return r.request().URL.Query()
+}
+
+func (r *GinRequest) PathValue(name string) string {
+	value := r.ctx.Param(name)
+	// значение catch-all параметра gin начинается с "/"
+	if strings.HasSuffix(r.ctx.FullPath(), "/*"+name) {
+		return strings.TrimPrefix(value, "/")
+	}
+
+	return value
+}
+
+func (r *GinRequest) MultipartForm(maxMemory int64) (transport.Form, error) {
+	err := r.request().ParseMultipartForm(maxMemory)
+
+	return transport.MultipartFormWrap(r.request().MultipartForm), err
+}
+
+func (r *GinRequest) URLEncodedForm() (url.Values, error) {
+	if err := r.request().ParseForm(); err != nil {
+		return nil, err
+	}
+
+	return r.request().Form, nil
+}
+
+func (r *GinRequest) ReadData(data any) error {
+	return r.readData(r, data)
+}
+
+func (r *GinRequest) ReadDataFunc() transport.ReadData {
+	return r.readData
+}
+
+func (r *GinRequest) SetCookie(c transport.Cookie) error {
+	http.SetCookie(r.ctx.Writer, &http.Cookie{
+		Name:        c.Name,
+		Value:       c.Value,
+		Path:        c.Path,
+		Domain:      c.Domain,
+		Expires:     c.Expires,
+		MaxAge:      c.MaxAge,
+		Secure:      c.Secure,
+		HttpOnly:    c.HttpOnly,
+		SameSite:    http.SameSite(c.SameSite),
+		Partitioned: c.Partitioned,
+	})
+
+	return nil
+}
+
+func (r *GinRequest) Cookie(name string) (string, error) {
+	c, err := r.request().Cookie(name)
+	if err != nil {
+		return "", err
+	}
+
+	return c.Value, nil
+}
+
+func (r *GinRequest) RemoteAddr() string {
+	return r.request().RemoteAddr
+}
+
+// GinResponse адаптер для gin.ResponseWriter
+type GinResponse struct {
+	ctx           *gin.Context
+	writeResponse transport.WriteResponse
+}
+
+func (r *GinResponse) SetStatusCode(code int) {
+	r.WriteHeader(code)
+}
+
+func (r *GinResponse) SetHeader(key, value string) {
+	r.ctx.Writer.Header().Set(key, value)
+}
+
+func (r *GinResponse) AddHeader(key, value string) {
+	r.ctx.Writer.Header().Add(key, value)
+}
+
+func (r *GinResponse) WriteData(req transport.Request, data any) {
+	r.writeResponse(req, r, data)
+}
+
+func (r *GinResponse) WriteResponseFunc() transport.WriteResponse {
+	return r.writeResponse
+}
+
+func (r *GinResponse) Write(body []byte) (int, error) {
+	return r.ctx.Writer.Write(body)
+}
+
+func (r *GinResponse) SetBody(body []byte, statusCode int) int {
+	r.WriteHeader(statusCode)
+	n, _ := r.Write(body)
+
+	return n
+}
+
+func (r *GinResponse) WriteHeader(statusCode int) {
+	// gin откладывает запись статуса до первой записи тела, поэтому статус фиксируется сразу
+	r.ctx.Writer.WriteHeader(statusCode)
+	r.ctx.Writer.WriteHeaderNow()
+}
+
+// GinAdapter адаптер для gin
+type GinAdapter struct {
+	writeResponse transport.WriteResponse
+	readData      transport.ReadData
+	notFound      transport.Handler
+	errorHandler  transport.ErrorHandler
+}
+
+// serveNotFound обрабатывает запрос, не совпавший ни с одним маршрутом
+func (a *GinAdapter) serveNotFound(req transport.Request, resp transport.Response) error {
+	if a.notFound != nil {
+		return a.notFound(req, resp)
+	}
+
+	return transport.NotFound(req, resp)
+}
+
+func (a *GinAdapter) AdaptHandler(handler transport.Handler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		req := &GinRequest{ctx: c, readData: a.readData}
+		resp := &GinResponse{ctx: c, writeResponse: a.writeResponse}
+		if err := handler(req, resp); err != nil {
+			a.errorHandler(req, resp, err)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package gin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/go-mosaic/runtime/transport"
+)
+
+type nextKey struct{}
+
+// GinToMiddleware преобразует middleware gin в универсальный Middleware.
+// Middleware выполняется во вспомогательном gin.Engine; значения, установленные через gin.Context.Set,
+// копируются в исходный контекст gin перед вызовом следующего handler.
+func GinToMiddleware(ginMiddleware gin.HandlerFunc) transport.Middleware {
+	engine := gin.New()
+	engine.Use(ginMiddleware)
+	engine.Any("/*path", func(c *gin.Context) {
+		if next, ok := c.Request.Context().Value(nextKey{}).(func(c *gin.Context)); ok {
+			next(c)
+		}
+	})
+
+	return func(next transport.Handler) transport.Handler {
+		return func(req transport.Request, resp transport.Response) error {
+			ginReq, ok := transport.UnwrapRequest(req).(*GinRequest)
+			if !ok {
+				return fmt.Errorf("%w: %T", transport.ErrAdapterMismatch, req)
+			}
+
+			var err error
+			call := func(c *gin.Context) {
+				for k, v := range c.Keys {
+					ginReq.ctx.Set(k, v)
+				}
+				// контекст, измененный middleware gin, передается дальше по цепочке
+				err = next(req.WithContext(c.Request.Context()), resp)
+			}
+
+			ctx := context.WithValue(req.Context(), nextKey{}, call)
+			engine.ServeHTTP(writtenGuard{ginReq.ctx.Writer}, ginReq.request().WithContext(ctx))
+
+			return err
+		}
+	}
+}
+
+// writtenGuard не дает вспомогательному gin.Engine перезаписать статус уже записанного ответа
+type writtenGuard struct {
+	gin.ResponseWriter
+}
+
+func (w writtenGuard) WriteHeader(code int) {
+	if !w.Written() {
+		w.ResponseWriter.WriteHeader(code)
+	}
+}
+
+// patternSyntax синтаксис шаблонов gin: параметры ":name", catch-all "*name"
+var patternSyntax = transport.PatternSyntax{
+	Param:    func(name string) string { return ":" + name },
+	CatchAll: func(name string) (string, string) { return "*" + name, name },
+}
+
+// GinTransport реализация Transport с использованием gin
+type GinTransport struct {
+	engine      *gin.Engine
+	router      *gin.RouterGroup
+	middlewares *transport.MiddlewareStack
+	adapter     *GinAdapter
+	routes      *transport.RouteTable
+	prefix      string
+	dispatchers map[string]*transport.Dispatcher
+}
+
+// NewGinTransport создает новый экземпляр GinTransport
+func NewGinTransport(engine *gin.Engine, opts ...transport.Option) *GinTransport {
+	config := transport.NewConfig(opts...)
+
+	t := &GinTransport{
+		engine:      engine,
+		router:      &engine.RouterGroup,
+		middlewares: transport.NewMiddlewareStack(nil),
+		routes:      transport.NewRouteTable(),
+		dispatchers: make(map[string]*transport.Dispatcher),
+		adapter: &GinAdapter{
+			writeResponse: config.WriteResponse(),
+			readData:      config.ReadData(),
+			notFound:      config.NotFound(),
+			errorHandler:  config.ErrorHandler(),
+		},
+	}
+	engine.NoRoute(t.adapter.AdaptHandler(t.middlewares.Handler(t.adapter.serveNotFound)))
+
+	return t
+}
+
+// AddRoute регистрирует маршрут с middleware
+func (t *GinTransport) AddRoute(method, path string, handler transport.Handler, middlewares ...transport.Middleware) {
+	t.AddRouteWith(method, path, handler, transport.MiddlewareOptions(middlewares...)...)
+}
+
+// AddRouteWith регистрирует маршрут с опциями: именем, тегами, метаданными и middleware
+func (t *GinTransport) AddRouteWith(method, path string, handler transport.Handler, opts ...transport.RouteOption) {
+	// ограничения проверяются по полному шаблону, а в группу gin регистрируется относительный путь
+	pattern, full := transport.MustNativePattern(transport.JoinPattern(t.prefix, path), patternSyntax)
+	_, native := transport.MustNativePattern(path, patternSyntax)
+	route := transport.NewRoute(method, path, opts...)
+	if err := t.routes.Add(route); err != nil {
+		panic(err)
+	}
+
+	paths := native.Paths
+	if t.prefix != "" && path == "/" {
+		// корневой маршрут группы совпадает с префиксом как с завершающим "/", так и без него
+		paths = []string{"", "/"}
+	}
+
+	h := t.middlewares.Handler(pattern.Bind(route.Handler(handler), native.Aliases, route.NotFound(t.adapter.serveNotFound)))
+	if err := t.dispatcher(full, paths).Handle(method, h); err != nil {
+		panic(fmt.Errorf("%w: %s", err, pattern))
+	}
+}
+
+// dispatcher возвращает Dispatcher шаблона, регистрируя его в роутере для всех методов при первом обращении
+func (t *GinTransport) dispatcher(full transport.NativePattern, paths []string) *transport.Dispatcher {
+	key := strings.Join(full.Paths, " ")
+	if d, ok := t.dispatchers[key]; ok {
+		return d
+	}
+
+	d := transport.NewDispatcher(t.middlewares)
+	t.dispatchers[key] = d
+
+	h := t.adapter.AdaptHandler(d.Serve)
+	for _, p := range paths {
+		t.router.Any(p, h)
+	}
+
+	return d
+}
+
+// NotFound задает handler для запросов, не совпавших ни с одним маршрутом.
+// Ответ проходит через глобальные middleware.
+func (t *GinTransport) NotFound(handler transport.Handler) {
+	t.adapter.notFound = handler
+}
+
+// Routes возвращает таблицу зарегистрированных маршрутов
+func (t *GinTransport) Routes() *transport.RouteTable {
+	return t.routes
+}
+
+// Group возвращает группу маршрутов под префиксом prefix на основе gin.RouterGroup
+func (t *GinTransport) Group(prefix string, middlewares ...transport.Middleware) transport.Transport {
+	_, native := transport.MustNativePattern(prefix, patternSyntax)
+
+	group := &GinTransport{
+		engine:      t.engine,
+		router:      t.router.Group(native.Paths[0]),
+		middlewares: transport.NewMiddlewareStack(t.middlewares, middlewares...),
+		adapter:     t.adapter,
+		routes:      transport.NewRouteTable(),
+		prefix:      transport.JoinPattern(t.prefix, prefix),
+		dispatchers: t.dispatchers,
+	}
+	if err := t.routes.Mount(prefix, group.routes); err != nil {
+		panic(err)
+	}
+
+	return group
+}
+
+// Mount подключает транспорт sub под префиксом prefix, удаляя префикс из пути запроса
+func (t *GinTransport) Mount(prefix string, sub transport.Transport) {
+	if err := t.routes.Mount(prefix, sub.Routes()); err != nil {
+		panic(err)
+	}
+
+	h, err := transport.HandlerOf(sub)
+	if err != nil {
+		panic(err)
+	}
+
+	_, native := transport.MustNativePattern(prefix, patternSyntax)
+	base := strings.TrimSuffix(native.Paths[0], "/")
+	handler := t.adapter.AdaptHandler(t.middlewares.Handler(mountHandler(transport.StripPrefix(transport.JoinPattern(t.prefix, prefix), h))))
+
+	if base != "" {
+		t.router.Any(base, handler)
+	}
+	t.router.Any(base+"/*mount", handler)
+}
+
+// mountHandler преобразует подключаемый http.Handler в универсальный Handler,
+// чтобы к подключенному транспорту применялись middleware родителя
+func mountHandler(h http.Handler) transport.Handler {
+	return func(req transport.Request, resp transport.Response) error {
+		ginReq, ok := transport.UnwrapRequest(req).(*GinRequest)
+		if !ok {
+			return fmt.Errorf("%w: %T", transport.ErrAdapterMismatch, req)
+		}
+
+		var w http.ResponseWriter
+		if ginResp, ok := transport.UnwrapResponse(resp).(*GinResponse); ok {
+			w = ginResp.ctx.Writer
+		}
+
+		transport.ServeMounted(h, req, resp, w, ginReq.request())
+
+		return nil
+	}
+}
+
+// ServeHTTP реализует http.Handler
+func (t *GinTransport) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	t.engine.ServeHTTP(w, r)
+}
+
+// Use добавляет глобальные middleware; они применяются и к маршрутам, зарегистрированным ранее
+func (t *GinTransport) Use(middlewares ...transport.Middleware) {
+	t.middlewares.Use(middlewares...)
+}
@@ -0,0 +1,57 @@
+package gorilla
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/go-mosaic/runtime/transport"
+	transporthttp "github.com/go-mosaic/runtime/transport/http"
+)
+
+// GorillaToMiddleware преобразует middleware gorilla/mux в универсальный Middleware
+func GorillaToMiddleware(gorillaMiddleware mux.MiddlewareFunc) transport.Middleware {
+	return transporthttp.HTTPToMiddleware(gorillaMiddleware)
+}
+
+// PathValue возвращает параметр пути, сохраненный gorilla/mux
+func PathValue(r *http.Request, name string) string {
+	return mux.Vars(r)[name]
+}
+
+// Pattern возвращает шаблон маршрута gorilla/mux
+func Pattern(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return ""
+	}
+
+	tpl, _ := route.GetPathTemplate()
+
+	return tpl
+}
+
+// patternSyntax синтаксис шаблонов gorilla/mux: параметры "{name}", catch-all "{name:.*}"
+var patternSyntax = transport.PatternSyntax{
+	Param:    func(name string) string { return "{" + name + "}" },
+	CatchAll: func(name string) (string, string) { return "{" + name + ":.*}", name },
+}
+
+// GorillaTransport реализация Transport на основе gorilla/mux
+type GorillaTransport = transporthttp.RouterTransport
+
+// NewGorillaTransport создает транспорт поверх router; маршруты регистрируются без ограничения по методу
+func NewGorillaTransport(router *mux.Router, opts ...transport.Option) *GorillaTransport {
+	return transporthttp.NewRouterTransport(transporthttp.Router{
+		Handler: router,
+		Handle: func(path string, handler http.Handler) {
+			router.Handle(path, handler)
+		},
+		NotFound: func(handler http.Handler) {
+			router.NotFoundHandler = handler
+		},
+		PathValue: PathValue,
+		Pattern:   Pattern,
+		Syntax:    patternSyntax,
+	}, opts...)
+}
@@ -0,0 +1,332 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-mosaic/runtime/log"
+	"github.com/go-mosaic/runtime/transport"
+)
+
+// ContentType тип ответа в формате draft-inadarei-api-health-check
+const ContentType = "application/health+json"
+
+// Status статус проверки
+type Status string
+
+const (
+	// StatusPass компонент исправен
+	StatusPass Status = "pass"
+	// StatusWarn компонент работает с ограничениями
+	StatusWarn Status = "warn"
+	// StatusFail компонент неисправен
+	StatusFail Status = "fail"
+)
+
+// Kind набор проверок, выполняемых эндпоинтом
+type Kind int
+
+const (
+	// Liveness проверки /livez: процесс работоспособен и не требует перезапуска
+	Liveness Kind = iota
+	// Readiness проверки /readyz: экземпляр готов принимать запросы
+	Readiness
+	// All все проверки, /healthz
+	All
+)
+
+// CheckFunc проверка компонента; ошибка означает, что компонент неисправен
+type CheckFunc func(ctx context.Context) error
+
+// CheckResult результат проверки
+type CheckResult struct {
+	Status Status    `json:"status"`
+	Output string    `json:"output,omitempty"`
+	Time   time.Time `json:"time"`
+}
+
+// Response ответ эндпоинта проверки
+type Response struct {
+	Status Status                   `json:"status"`
+	Checks map[string][]CheckResult `json:"checks,omitempty"`
+}
+
+// StatusCode возвращает статус код ответа: 503 для fail, иначе 200
+func (r Response) StatusCode() int {
+	if r.Status == StatusFail {
+		return http.StatusServiceUnavailable
+	}
+
+	return http.StatusOK
+}
+
+// Config конфигурация проверок
+type Config struct {
+	timeout time.Duration
+	logger  log.Logger
+	ready   func() bool
+	now     func() time.Time
+}
+
+// Option тип для функциональных опций
+type Option func(*Config)
+
+// WithTimeout задает таймаут проверки по умолчанию, 5 секунд
+func WithTimeout(d time.Duration) Option {
+	return func(c *Config) {
+		c.timeout = d
+	}
+}
+
+// WithLogger задает логгер для записи смены статуса проверок
+func WithLogger(logger log.Logger) Option {
+	return func(c *Config) {
+		c.logger = logger
+	}
+}
+
+// WithReadiness задает флаг готовности, например server.Server.Ready; пока он false, /readyz отвечает fail
+func WithReadiness(ready func() bool) Option {
+	return func(c *Config) {
+		c.ready = ready
+	}
+}
+
+// WithClock задает источник текущего времени
+func WithClock(now func() time.Time) Option {
+	return func(c *Config) {
+		c.now = now
+	}
+}
+
+// CheckOption опция проверки
+type CheckOption func(*check)
+
+// WithCheckTimeout задает таймаут проверки
+func WithCheckTimeout(d time.Duration) CheckOption {
+	return func(c *check) {
+		c.timeout = d
+	}
+}
+
+// WithCritical задает критичность проверки, по умолчанию true.
+// Неудачная некритичная проверка дает статус warn вместо fail.
+func WithCritical(critical bool) CheckOption {
+	return func(c *check) {
+		c.critical = critical
+	}
+}
+
+// WithCacheTTL задает время, в течение которого используется предыдущий результат проверки
+func WithCacheTTL(d time.Duration) CheckOption {
+	return func(c *check) {
+		c.cacheTTL = d
+	}
+}
+
+// WithLiveness включает проверку в /livez; по умолчанию проверки выполняются только в /readyz и /healthz
+func WithLiveness() CheckOption {
+	return func(c *check) {
+		c.liveness = true
+	}
+}
+
+type check struct {
+	name     string
+	fn       CheckFunc
+	timeout  time.Duration
+	critical bool
+	cacheTTL time.Duration
+	liveness bool
+
+	mu     sync.Mutex
+	last   CheckResult
+	hasRun bool
+}
+
+// Health набор проверок состояния сервиса
+type Health struct {
+	config Config
+
+	mu     sync.RWMutex
+	checks []*check
+}
+
+// New создает набор проверок
+func New(opts ...Option) *Health {
+	config := Config{
+		timeout: 5 * time.Second,
+		now:     time.Now,
+	}
+
+	for _, applyOpt := range opts {
+		applyOpt(&config)
+	}
+
+	return &Health{config: config}
+}
+
+// Register добавляет проверку с именем name, например "postgres:connections"
+func (h *Health) Register(name string, fn CheckFunc, opts ...CheckOption) {
+	c := &check{name: name, fn: fn, timeout: h.config.timeout, critical: true}
+	for _, applyOpt := range opts {
+		applyOpt(c)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.checks = append(h.checks, c)
+}
+
+// Check выполняет проверки набора kind параллельно.
+// Отмена ctx не прерывает проверки: их результаты кэшируются и логируются, поэтому проверки ограничены только таймаутом.
+func (h *Health) Check(ctx context.Context, kind Kind) Response {
+	h.mu.RLock()
+	checks := make([]*check, 0, len(h.checks))
+	for _, c := range h.checks {
+		if kind != Liveness || c.liveness {
+			checks = append(checks, c)
+		}
+	}
+	h.mu.RUnlock()
+
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = h.run(ctx, c)
+		}()
+	}
+	wg.Wait()
+
+	resp := Response{Status: StatusPass, Checks: make(map[string][]CheckResult, len(checks)+1)}
+	for i, c := range checks {
+		resp.Checks[c.name] = append(resp.Checks[c.name], results[i])
+		resp.Status = worst(resp.Status, results[i].Status)
+	}
+
+	if kind == Readiness && h.config.ready != nil && !h.config.ready() {
+		resp.Checks["server:ready"] = []CheckResult{{Status: StatusFail, Output: "server is not ready", Time: h.config.now()}}
+		resp.Status = StatusFail
+	}
+
+	return resp
+}
+
+// run выполняет проверку или возвращает ее кэшированный результат
+func (h *Health) run(ctx context.Context, c *check) CheckResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := h.config.now()
+	if c.hasRun && c.cacheTTL > 0 && now.Sub(c.last.Time) < c.cacheTTL {
+		return c.last
+	}
+
+	// отключение клиента не должно давать ложный fail, который попадет в кэш и журнал
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.timeout)
+	defer cancel()
+
+	result := CheckResult{Status: StatusPass, Time: now}
+	if err := runCheck(ctx, c.fn); err != nil {
+		result.Status = StatusFail
+		if !c.critical {
+			result.Status = StatusWarn
+		}
+		result.Output = err.Error()
+	}
+
+	previous := StatusPass
+	if c.hasRun {
+		previous = c.last.Status
+	}
+	if previous != result.Status {
+		h.logTransition(c.name, previous, result)
+	}
+	c.last, c.hasRun = result, true
+
+	return result
+}
+
+// runCheck выполняет проверку и ограничивает ее таймаутом, даже если проверка не учитывает контекст
+func runCheck(ctx context.Context, fn CheckFunc) error {
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("health: check panicked: %v", r)
+			}
+		}()
+		done <- fn(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (h *Health) logTransition(name string, from Status, result CheckResult) {
+	if h.config.logger == nil {
+		return
+	}
+
+	fields := map[string]any{
+		"check": name,
+		"from":  string(from),
+		"to":    string(result.Status),
+	}
+	if result.Output != "" {
+		fields["error"] = result.Output
+	}
+
+	if result.Status == StatusPass {
+		h.config.logger.Info("health check status changed", fields)
+	} else {
+		h.config.logger.Warn("health check status changed", fields)
+	}
+}
+
+// Handler возвращает handler эндпоинта проверок набора kind
+func (h *Health) Handler(kind Kind) transport.Handler {
+	return func(req transport.Request, resp transport.Response) error {
+		result := h.Check(req.Context(), kind)
+
+		body, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+
+		resp.SetHeader("Content-Type", ContentType)
+		resp.SetHeader("Cache-Control", "no-store")
+		resp.SetBody(body, result.StatusCode())
+
+		return nil
+	}
+}
+
+// Routes регистрирует эндпоинты /livez, /readyz и /healthz
+func (h *Health) Routes(t transport.Transport, opts ...transport.RouteOption) {
+	t.AddRouteWith(http.MethodGet, "/livez", h.Handler(Liveness), opts...)
+	t.AddRouteWith(http.MethodGet, "/readyz", h.Handler(Readiness), opts...)
+	t.AddRouteWith(http.MethodGet, "/healthz", h.Handler(All), opts...)
+}
+
+// worst возвращает худший из статусов
+func worst(a, b Status) Status {
+	rank := map[Status]int{StatusPass: 0, StatusWarn: 1, StatusFail: 2}
+	if rank[b] > rank[a] {
+		return b
+	}
+
+	return a
+}
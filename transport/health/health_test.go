@@ -0,0 +1,211 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-mosaic/runtime/transport/internal/transporttest"
+)
+
+var errDown = errors.New("connection refused")
+
+func pass(context.Context) error { return nil }
+func fail(context.Context) error { return errDown }
+
+func TestRoutes(t *testing.T) {
+	tests := []struct {
+		name       string
+		setup      func(h *Health)
+		ready      bool
+		path       string
+		wantStatus int
+		want       Status
+		wantChecks []string
+	}{
+		{
+			name: "liveness runs only liveness checks",
+			setup: func(h *Health) {
+				h.Register("process:goroutines", pass, WithLiveness())
+				h.Register("postgres:connections", fail)
+			},
+			ready: true, path: "/livez",
+			wantStatus: http.StatusOK, want: StatusPass, wantChecks: []string{"process:goroutines"},
+		},
+		{
+			name: "critical failure",
+			setup: func(h *Health) {
+				h.Register("process:goroutines", pass, WithLiveness())
+				h.Register("postgres:connections", fail)
+			},
+			ready: true, path: "/readyz",
+			wantStatus: http.StatusServiceUnavailable, want: StatusFail,
+			wantChecks: []string{"process:goroutines", "postgres:connections"},
+		},
+		{
+			name: "non-critical failure",
+			setup: func(h *Health) {
+				h.Register("postgres:connections", pass)
+				h.Register("cache:responseTime", fail, WithCritical(false))
+			},
+			ready: true, path: "/healthz",
+			wantStatus: http.StatusOK, want: StatusWarn,
+			wantChecks: []string{"postgres:connections", "cache:responseTime"},
+		},
+		{
+			name: "not ready",
+			setup: func(h *Health) {
+				h.Register("postgres:connections", pass)
+			},
+			path:       "/readyz",
+			wantStatus: http.StatusServiceUnavailable, want: StatusFail,
+			wantChecks: []string{"postgres:connections", "server:ready"},
+		},
+		{
+			name:  "liveness ignores readiness flag",
+			setup: func(*Health) {},
+			path:  "/livez", wantStatus: http.StatusOK, want: StatusPass,
+		},
+	}
+	for _, adapter := range transporttest.Adapters() {
+		for _, tt := range tests {
+			t.Run(adapter.Name+"/"+tt.name, func(t *testing.T) {
+				h := New(WithReadiness(func() bool { return tt.ready }))
+				tt.setup(h)
+
+				srv := adapter.New()
+				h.Routes(srv.Transport)
+
+				resp := srv.Do(t, httptest.NewRequest(http.MethodGet, tt.path, nil))
+				defer resp.Body.Close()
+
+				if resp.StatusCode != tt.wantStatus {
+					t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+				}
+				if ct := resp.Header.Get("Content-Type"); ct != ContentType {
+					t.Errorf("Content-Type = %q, want %q", ct, ContentType)
+				}
+
+				var got Response
+				if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+					t.Fatal(err)
+				}
+				if got.Status != tt.want {
+					t.Errorf("status = %q, want %q", got.Status, tt.want)
+				}
+				if len(got.Checks) != len(tt.wantChecks) {
+					t.Errorf("checks = %v, want %v", got.Checks, tt.wantChecks)
+				}
+				for _, name := range tt.wantChecks {
+					if _, ok := got.Checks[name]; !ok {
+						t.Errorf("check %q is missing in %v", name, got.Checks)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestCheckTimeout(t *testing.T) {
+	h := New()
+	h.Register("slow", func(context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	}, WithCheckTimeout(10*time.Millisecond))
+	h.Register("fast", pass)
+
+	start := time.Now()
+	got := h.Check(context.Background(), All)
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Check() took %v, want timeout", elapsed)
+	}
+	if got.Status != StatusFail || got.Checks["slow"][0].Output != context.DeadlineExceeded.Error() {
+		t.Errorf("Check() = %+v", got)
+	}
+}
+
+func TestCheckIgnoresCallerCancel(t *testing.T) {
+	type key struct{}
+
+	logger := &recordingLogger{}
+	h := New(WithLogger(logger))
+	h.Register("db", func(ctx context.Context) error {
+		if ctx.Value(key{}) != "value" {
+			return errDown
+		}
+		return ctx.Err()
+	}, WithCacheTTL(time.Minute))
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), key{}, "value"))
+	cancel()
+
+	if got := h.Check(ctx, All); got.Status != StatusPass {
+		t.Errorf("Check() with canceled context = %+v, want pass", got)
+	}
+	if len(logger.messages) != 0 {
+		t.Errorf("transitions = %v, want none", logger.messages)
+	}
+}
+
+type recordingLogger struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (l *recordingLogger) Debug(string, map[string]any) {}
+func (l *recordingLogger) Error(string, map[string]any) {}
+func (l *recordingLogger) Info(_ string, fields map[string]any) {
+	l.record(fields)
+}
+func (l *recordingLogger) Warn(_ string, fields map[string]any) {
+	l.record(fields)
+}
+
+func (l *recordingLogger) record(fields map[string]any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.messages = append(l.messages, fields["from"].(string)+"->"+fields["to"].(string))
+}
+
+func TestCacheAndTransitions(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	logger := &recordingLogger{}
+	h := New(WithClock(func() time.Time { return now }), WithLogger(logger))
+
+	calls := 0
+	results := []error{nil, errDown, errDown, nil}
+	h.Register("db", func(context.Context) error {
+		err := results[calls]
+		calls++
+		return err
+	}, WithCacheTTL(time.Second))
+
+	steps := []struct {
+		advance   time.Duration
+		want      Status
+		wantCalls int
+	}{
+		{want: StatusPass, wantCalls: 1},
+		{advance: 500 * time.Millisecond, want: StatusPass, wantCalls: 1},
+		{advance: 500 * time.Millisecond, want: StatusFail, wantCalls: 2},
+		{advance: time.Second, want: StatusFail, wantCalls: 3},
+		{advance: time.Second, want: StatusPass, wantCalls: 4},
+	}
+	for i, s := range steps {
+		now = now.Add(s.advance)
+		got := h.Check(context.Background(), All)
+		if got.Status != s.want || calls != s.wantCalls {
+			t.Errorf("step %d: status = %q, calls = %d, want %q, %d", i, got.Status, calls, s.want, s.wantCalls)
+		}
+	}
+
+	if len(logger.messages) != 2 || logger.messages[0] != "pass->fail" || logger.messages[1] != "fail->pass" {
+		t.Errorf("transitions = %v", logger.messages)
+	}
+}
@@ -5,18 +5,57 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
 
 	"github.com/go-mosaic/runtime/transport"
 )
 
-// HTTPRequest адаптер для net/http.Request
+// PathValueFunc возвращает значение параметра пути name запроса r
+type PathValueFunc func(r *http.Request, name string) string
+
+// PatternFunc возвращает шаблон маршрута, с которым совпал запрос r
+type PatternFunc func(r *http.Request) string
+
+// DetachFunc возвращает копию запроса r, не ссылающуюся на объекты, которые роутер переиспользует после обработки запроса
+type DetachFunc func(r *http.Request) *http.Request
+
+// ServeMuxPathValue возвращает параметр пути, сохраненный http.ServeMux
+func ServeMuxPathValue(r *http.Request, name string) string {
+	return r.PathValue(name)
+}
+
+// ServeMuxPattern возвращает шаблон http.ServeMux без метода
+func ServeMuxPattern(r *http.Request) string {
+	// Шаблон net/http может содержать метод: "GET /users/{id}"
+	if _, pattern, ok := strings.Cut(r.Pattern, " "); ok {
+		return pattern
+	}
+
+	return r.Pattern
+}
+
+// HTTPRequest адаптер для net/http.Request.
+// Параметры пути и шаблон маршрута определяются функциями роутера, обслужившего запрос.
 type HTTPRequest struct {
-	req      *http.Request
-	readData transport.ReadData
+	req       *http.Request
+	readData  transport.ReadData
+	pathValue PathValueFunc
+	pattern   PatternFunc
+	detach    DetachFunc
 }
 
 func (r *HTTPRequest) WithContext(ctx context.Context) transport.Request {
-	return &HTTPRequest{req: r.req.WithContext(ctx), readData: r.readData}
+	return &HTTPRequest{req: r.req.WithContext(ctx), readData: r.readData, pathValue: r.pathValue, pattern: r.pattern, detach: r.detach}
+}
+
+// Detach возвращает копию запроса, созданную функцией, заданной HTTPAdapter.DetachRequest.
+// Без нее запрос возвращается без изменений: net/http не переиспользует http.Request.
+func (r *HTTPRequest) Detach() transport.Request {
+	if r.detach == nil {
+		return r
+	}
+
+	return &HTTPRequest{req: r.detach(r.req), readData: r.readData, pathValue: r.pathValue, pattern: r.pattern, detach: r.detach}
 }
 
 func (r *HTTPRequest) Context() context.Context {
@@ -31,6 +70,10 @@ func (r *HTTPRequest) Path() string {
 	return r.req.URL.Path
 }
 
+func (r *HTTPRequest) Pattern() string {
+	return r.pattern(r.req)
+}
+
 func (r *HTTPRequest) Body() io.ReadCloser {
 	return r.req.Body
 }
@@ -44,7 +87,7 @@ func (r *HTTPRequest) Queries() url.Values {
 }
 
 func (r *HTTPRequest) PathValue(name string) string {
-	return r.req.PathValue(name)
+	return r.pathValue(r.req, name)
 }
 
 func (r *HTTPRequest) MultipartForm(maxMemory int64) (transport.Form, error) {
@@ -64,6 +107,10 @@ func (r *HTTPRequest) ReadData(data any) error {
 	return r.readData(r, data)
 }
 
+func (r *HTTPRequest) ReadDataFunc() transport.ReadData {
+	return r.readData
+}
+
 func (r *HTTPRequest) SetCookie(c transport.Cookie) error {
 	r.req.AddCookie(&http.Cookie{
 		Name:        c.Name,
@@ -90,6 +137,10 @@ func (r *HTTPRequest) Cookie(name string) (string, error) {
 	return c.Value, nil
 }
 
+func (r *HTTPRequest) RemoteAddr() string {
+	return r.req.RemoteAddr
+}
+
 // HTTPResponse адаптер для net/http.ResponseWriter
 type HTTPResponse struct {
 	w             http.ResponseWriter
@@ -104,6 +155,10 @@ func (r *HTTPResponse) SetHeader(key, value string) {
 	r.w.Header().Set(key, value)
 }
 
+func (r *HTTPResponse) AddHeader(key, value string) {
+	r.w.Header().Add(key, value)
+}
+
 func (r *HTTPResponse) Write(body []byte) (int, error) {
 	return r.w.Write(body)
 }
@@ -112,6 +167,10 @@ func (r *HTTPResponse) WriteData(req transport.Request, data any) {
 	r.writeResponse(req, r, data)
 }
 
+func (r *HTTPResponse) WriteResponseFunc() transport.WriteResponse {
+	return r.writeResponse
+}
+
 func (r *HTTPResponse) SetBody(body []byte, statusCode int) int {
 	r.WriteHeader(statusCode)
 	n, _ := r.Write(body)
@@ -123,18 +182,64 @@ func (r *HTTPResponse) WriteHeader(statusCode int) {
 	r.w.WriteHeader(statusCode)
 }
 
-// HTTPAdapter адаптер для net/http
+// HTTPAdapter адаптер для роутеров на основе net/http
 type HTTPAdapter struct {
 	writeResponse transport.WriteResponse
 	readData      transport.ReadData
+	notFound      transport.Handler
+	errorHandler  transport.ErrorHandler
+	pathValue     PathValueFunc
+	pattern       PatternFunc
+	detach        DetachFunc
+}
+
+// NewHTTPAdapter создает адаптер для роутера, параметры пути и шаблон маршрута которого
+// возвращают pathValue и pattern; nil соответствует http.ServeMux
+func NewHTTPAdapter(pathValue PathValueFunc, pattern PatternFunc, opts ...transport.Option) *HTTPAdapter {
+	config := transport.NewConfig(opts...)
+
+	if pathValue == nil {
+		pathValue = ServeMuxPathValue
+	}
+	if pattern == nil {
+		pattern = ServeMuxPattern
+	}
+
+	return &HTTPAdapter{
+		writeResponse: config.WriteResponse(),
+		readData:      config.ReadData(),
+		notFound:      config.NotFound(),
+		errorHandler:  config.ErrorHandler(),
+		pathValue:     pathValue,
+		pattern:       pattern,
+	}
+}
+
+// NotFound задает handler для запросов, не совпавших ни с одним маршрутом
+func (a *HTTPAdapter) NotFound(handler transport.Handler) {
+	a.notFound = handler
+}
+
+// DetachRequest задает функцию, копирующую данные роутера, которые он переиспользует после обработки запроса
+func (a *HTTPAdapter) DetachRequest(detach DetachFunc) {
+	a.detach = detach
+}
+
+// ServeNotFound обрабатывает запрос, не совпавший ни с одним маршрутом
+func (a *HTTPAdapter) ServeNotFound(req transport.Request, resp transport.Response) error {
+	if a.notFound != nil {
+		return a.notFound(req, resp)
+	}
+
+	return transport.NotFound(req, resp)
 }
 
 func (a *HTTPAdapter) AdaptHandler(handler transport.Handler) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		req := &HTTPRequest{req: r, readData: a.readData}
+		req := &HTTPRequest{req: r, readData: a.readData, pathValue: a.pathValue, pattern: a.pattern, detach: a.detach}
 		resp := &HTTPResponse{w: w, writeResponse: a.writeResponse}
 		if err := handler(req, resp); err != nil {
-			a.writeResponse(req, resp, err)
+			a.errorHandler(req, resp, err)
 		}
 	}
 }
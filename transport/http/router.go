@@ -0,0 +1,157 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-mosaic/runtime/transport"
+)
+
+// Router описывает роутер на основе net/http, подключаемый через RouterTransport.
+// Чтобы поддержать новый роутер, достаточно указать, как в нем регистрируется handler
+// и как из запроса получить параметры пути.
+type Router struct {
+	// Handler обслуживает запросы, обычно это сам роутер
+	Handler http.Handler
+	// Handle регистрирует handler шаблона пути для всех методов
+	Handle func(path string, handler http.Handler)
+	// NotFound задает handler запросов, не совпавших ни с одним маршрутом
+	NotFound func(handler http.Handler)
+	// PathValue возвращает значение параметра пути; nil соответствует http.ServeMux
+	PathValue PathValueFunc
+	// Pattern возвращает шаблон совпавшего маршрута; nil соответствует http.ServeMux
+	Pattern PatternFunc
+	// Syntax синтаксис шаблонов роутера
+	Syntax transport.PatternSyntax
+}
+
+// RouterTransport реализация Transport для произвольного роутера на основе net/http.
+// Группы регистрируют маршруты в том же роутере с полным шаблоном.
+type RouterTransport struct {
+	router      Router
+	middlewares *transport.MiddlewareStack
+	adapter     *HTTPAdapter
+	routes      *transport.RouteTable
+	prefix      string
+	dispatchers map[string]*transport.Dispatcher
+}
+
+// NewRouterTransport создает RouterTransport поверх router
+func NewRouterTransport(router Router, opts ...transport.Option) *RouterTransport {
+	t := &RouterTransport{
+		router:      router,
+		middlewares: transport.NewMiddlewareStack(nil),
+		adapter:     NewHTTPAdapter(router.PathValue, router.Pattern, opts...),
+		routes:      transport.NewRouteTable(),
+		dispatchers: make(map[string]*transport.Dispatcher),
+	}
+	if router.NotFound != nil {
+		router.NotFound(t.adapter.AdaptHandler(t.middlewares.Handler(t.adapter.ServeNotFound)))
+	}
+
+	return t
+}
+
+// AddRoute регистрирует маршрут с middleware
+func (t *RouterTransport) AddRoute(method, path string, handler transport.Handler, middlewares ...transport.Middleware) {
+	t.AddRouteWith(method, path, handler, transport.MiddlewareOptions(middlewares...)...)
+}
+
+// AddRouteWith регистрирует маршрут с опциями: именем, тегами, метаданными и middleware
+func (t *RouterTransport) AddRouteWith(method, path string, handler transport.Handler, opts ...transport.RouteOption) {
+	pattern, native := transport.MustNativePattern(transport.JoinPattern(t.prefix, path), t.router.Syntax)
+	route := transport.NewRoute(method, path, opts...)
+	if err := t.routes.Add(route); err != nil {
+		panic(err)
+	}
+
+	h := t.middlewares.Handler(pattern.Bind(route.Handler(handler), native.Aliases, route.NotFound(t.adapter.ServeNotFound)))
+	if err := t.dispatcher(native).Handle(method, h); err != nil {
+		panic(fmt.Errorf("%w: %s", err, pattern))
+	}
+}
+
+// dispatcher возвращает Dispatcher шаблона, регистрируя его в роутере для всех методов при первом обращении
+func (t *RouterTransport) dispatcher(native transport.NativePattern) *transport.Dispatcher {
+	key := strings.Join(native.Paths, " ")
+	if d, ok := t.dispatchers[key]; ok {
+		return d
+	}
+
+	d := transport.NewDispatcher(t.middlewares)
+	t.dispatchers[key] = d
+
+	h := t.adapter.AdaptHandler(d.Serve)
+	for _, p := range native.Paths {
+		t.router.Handle(p, h)
+	}
+
+	return d
+}
+
+// NotFound задает handler для запросов, не совпавших ни с одним маршрутом.
+// Ответ проходит через глобальные middleware.
+func (t *RouterTransport) NotFound(handler transport.Handler) {
+	t.adapter.NotFound(handler)
+}
+
+// Routes возвращает таблицу зарегистрированных маршрутов
+func (t *RouterTransport) Routes() *transport.RouteTable {
+	return t.routes
+}
+
+// Use добавляет глобальные middleware; они применяются и к маршрутам, зарегистрированным ранее
+func (t *RouterTransport) Use(middlewares ...transport.Middleware) {
+	t.middlewares.Use(middlewares...)
+}
+
+// Group возвращает группу маршрутов под префиксом prefix
+func (t *RouterTransport) Group(prefix string, middlewares ...transport.Middleware) transport.Transport {
+	transport.MustNativePattern(prefix, t.router.Syntax)
+
+	group := &RouterTransport{
+		router:      t.router,
+		middlewares: transport.NewMiddlewareStack(t.middlewares, middlewares...),
+		adapter:     t.adapter,
+		routes:      transport.NewRouteTable(),
+		prefix:      transport.JoinPattern(t.prefix, prefix),
+		dispatchers: t.dispatchers,
+	}
+	if err := t.routes.Mount(prefix, group.routes); err != nil {
+		panic(err)
+	}
+
+	return group
+}
+
+// Mount подключает транспорт sub под префиксом prefix, удаляя префикс из пути запроса.
+// Префикс регистрируется как точный путь и как catch-all шаблон под ним.
+func (t *RouterTransport) Mount(prefix string, sub transport.Transport) {
+	// имена маршрутов проверяются до регистрации в роутере
+	if err := t.routes.Mount(prefix, sub.Routes()); err != nil {
+		panic(err)
+	}
+
+	h, err := transport.HandlerOf(sub)
+	if err != nil {
+		panic(err)
+	}
+
+	full := transport.JoinPattern(t.prefix, strings.TrimSuffix(prefix, "/"))
+	pattern, native := transport.MustNativePattern(full, t.router.Syntax)
+	h = t.adapter.AdaptHandler(t.middlewares.Handler(MountHandler(transport.StripPrefix(full, h))))
+
+	var base string
+	if len(pattern.Segments) > 0 {
+		base = native.Paths[0]
+		t.router.Handle(base, h)
+	}
+	catchAll, _ := t.router.Syntax.CatchAll("mount")
+	t.router.Handle(base+"/"+catchAll, h)
+}
+
+// ServeHTTP реализует http.Handler
+func (t *RouterTransport) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	t.router.Handler.ServeHTTP(w, r)
+}
@@ -1,6 +1,8 @@
 package http
 
 import (
+	"fmt"
+	"go/token"
 	"net/http"
 
 	"github.com/go-mosaic/runtime/transport"
@@ -10,44 +12,91 @@ import (
 func HTTPToMiddleware(httpMiddleware func(http.Handler) http.Handler) transport.Middleware {
 	return func(next transport.Handler) transport.Handler {
 		return func(req transport.Request, resp transport.Response) error {
+			httpReq, ok := transport.UnwrapRequest(req).(*HTTPRequest)
+			if !ok {
+				return fmt.Errorf("%w: %T", transport.ErrAdapterMismatch, req)
+			}
+			httpResp, ok := transport.UnwrapResponse(resp).(*HTTPResponse)
+			if !ok {
+				return fmt.Errorf("%w: %T", transport.ErrAdapterMismatch, resp)
+			}
+
+			var err error
 			httpHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				_ = next(req, resp)
+				// контекст, измененный middleware net/http, передается дальше по цепочке
+				err = next(req.WithContext(r.Context()), resp)
 			})
 
 			wrappedHandler := httpMiddleware(httpHandler)
-			wrappedHandler.ServeHTTP(resp.(*HTTPResponse).w, req.(*HTTPRequest).req)
+			wrappedHandler.ServeHTTP(httpResp.w, httpReq.req.WithContext(req.Context()))
 
-			return nil
+			return err
 		}
 	}
 }
 
-type HTTPTransport struct {
-	router      *http.ServeMux
-	middlewares []transport.Middleware
-	adapter     *HTTPAdapter
-}
+// MountHandler преобразует подключаемый http.Handler в универсальный Handler,
+// чтобы к подключенному транспорту применялись middleware родителя
+func MountHandler(h http.Handler) transport.Handler {
+	return func(req transport.Request, resp transport.Response) error {
+		httpReq, ok := transport.UnwrapRequest(req).(*HTTPRequest)
+		if !ok {
+			return fmt.Errorf("%w: %T", transport.ErrAdapterMismatch, req)
+		}
 
-func NewHTTPTransport() *HTTPTransport {
-	return &HTTPTransport{
-		router: http.NewServeMux(),
-		adapter: &HTTPAdapter{
-			writeResponse: transport.DefaultWriteResponse,
-			readData:      transport.DefaultReadData,
-		},
-	}
-}
+		var w http.ResponseWriter
+		if httpResp, ok := transport.UnwrapResponse(resp).(*HTTPResponse); ok {
+			w = httpResp.w
+		}
 
-func (t *HTTPTransport) AddRoute(method, path string, handler transport.Handler, middlewares ...transport.Middleware) {
-	wrappedHandler := handler
+		transport.ServeMounted(h, req, resp, w, httpReq.req)
 
-	for _, mw := range append(t.middlewares, middlewares...) {
-		wrappedHandler = mw(wrappedHandler)
+		return nil
 	}
+}
 
-	t.router.HandleFunc(path, t.adapter.AdaptHandler(wrappedHandler))
+// patternSyntax синтаксис шаблонов http.ServeMux
+var patternSyntax = transport.PatternSyntax{
+	Param: func(name string) string { return "{" + name + "}" },
+	CatchAll: func(name string) (string, string) {
+		if name == "*" {
+			return "{wildcard...}", "wildcard"
+		}
+		return "{" + name + "...}", name
+	},
+	ExactSuffix: "{$}",
+	Validate: func(p transport.Pattern) error {
+		for _, seg := range p.Segments {
+			if seg.Param != "" && seg.Param != "*" && !token.IsIdentifier(seg.Param) {
+				return fmt.Errorf("http.ServeMux requires parameter names to be Go identifiers, got %q", seg.Param)
+			}
+		}
+		return nil
+	},
 }
 
-func (t *HTTPTransport) Use(middlewares ...transport.Middleware) {
-	t.middlewares = append(t.middlewares, middlewares...)
+// HTTPTransport реализация Transport на основе http.ServeMux
+type HTTPTransport = RouterTransport
+
+// NewHTTPTransport создает транспорт с новым http.ServeMux
+func NewHTTPTransport(opts ...transport.Option) *HTTPTransport {
+	mux := http.NewServeMux()
+	var notFound http.Handler
+
+	return NewRouterTransport(Router{
+		// у http.ServeMux нет handler ненайденных маршрутов, поэтому совпадение проверяется до обработки запроса
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, pattern := mux.Handler(r); pattern == "" {
+				notFound.ServeHTTP(w, r)
+				return
+			}
+
+			mux.ServeHTTP(w, r)
+		}),
+		Handle: mux.Handle,
+		NotFound: func(handler http.Handler) {
+			notFound = handler
+		},
+		Syntax: patternSyntax,
+	}, opts...)
 }
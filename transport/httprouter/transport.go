@@ -0,0 +1,73 @@
+package httprouter
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/go-mosaic/runtime/transport"
+	transporthttp "github.com/go-mosaic/runtime/transport/http"
+)
+
+// methods методы, для которых регистрируется каждый маршрут: у httprouter нет регистрации для всех методов
+var methods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+	http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace,
+}
+
+type patternKey struct{}
+
+// HTTPRouterToMiddleware преобразует middleware net/http в универсальный Middleware для httprouter
+func HTTPRouterToMiddleware(httpMiddleware func(http.Handler) http.Handler) transport.Middleware {
+	return transporthttp.HTTPToMiddleware(httpMiddleware)
+}
+
+// PathValue возвращает параметр пути, сохраненный httprouter; значение catch-all параметра возвращается без начального "/"
+func PathValue(r *http.Request, name string) string {
+	value := httprouter.ParamsFromContext(r.Context()).ByName(name)
+	if strings.HasSuffix(Pattern(r), "/*"+name) {
+		return strings.TrimPrefix(value, "/")
+	}
+
+	return value
+}
+
+// Pattern возвращает шаблон маршрута httprouter, с которым совпал запрос
+func Pattern(r *http.Request) string {
+	pattern, _ := r.Context().Value(patternKey{}).(string)
+
+	return pattern
+}
+
+// patternSyntax синтаксис шаблонов httprouter: параметры ":name", catch-all "*name"
+var patternSyntax = transport.PatternSyntax{
+	Param:    func(name string) string { return ":" + name },
+	CatchAll: func(name string) (string, string) { return "*" + name, name },
+}
+
+// HTTPRouterTransport реализация Transport на основе julienschmidt/httprouter
+type HTTPRouterTransport = transporthttp.RouterTransport
+
+// NewHTTPRouterTransport создает транспорт поверх router
+func NewHTTPRouterTransport(router *httprouter.Router, opts ...transport.Option) *HTTPRouterTransport {
+	return transporthttp.NewRouterTransport(transporthttp.Router{
+		Handler: router,
+		Handle: func(path string, handler http.Handler) {
+			// httprouter не сохраняет шаблон совпавшего маршрута, поэтому он передается в контексте запроса
+			h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				handler.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), patternKey{}, path)))
+			})
+			for _, method := range methods {
+				router.Handler(method, path, h)
+			}
+		},
+		NotFound: func(handler http.Handler) {
+			router.NotFound = handler
+		},
+		PathValue: PathValue,
+		Pattern:   Pattern,
+		Syntax:    patternSyntax,
+	}, opts...)
+}
@@ -0,0 +1,210 @@
+package idempotency
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/go-mosaic/runtime/transport"
+)
+
+const (
+	// HeaderIdempotencyKey заголовок с ключом идемпотентности
+	HeaderIdempotencyKey = transport.HeaderIdempotencyKey
+	// HeaderReplayed заголовок, которым помечаются повторно отданные ответы
+	HeaderReplayed = "Idempotent-Replayed"
+
+	defaultTTL     = 24 * time.Hour
+	defaultLockTTL = time.Minute
+	maxKeyLength   = 255
+
+	defaultMaxBodySize = 1 << 20
+)
+
+// Config конфигурация middleware
+type Config struct {
+	methods     []string
+	ttl         time.Duration
+	lockTTL     time.Duration
+	required    bool
+	maxBodySize int64
+}
+
+// Option тип для функциональных опций
+type Option func(*Config)
+
+// WithMethods задает методы, для которых учитывается ключ, по умолчанию POST и PATCH
+func WithMethods(methods ...string) Option {
+	return func(c *Config) {
+		c.methods = methods
+	}
+}
+
+// WithTTL задает время хранения ответа, по умолчанию 24 часа
+func WithTTL(ttl time.Duration) Option {
+	return func(c *Config) {
+		c.ttl = ttl
+	}
+}
+
+// WithLockTTL задает максимальное время обработки первого запроса, после которого ключ освобождается
+func WithLockTTL(ttl time.Duration) Option {
+	return func(c *Config) {
+		c.lockTTL = ttl
+	}
+}
+
+// WithRequired требует наличия ключа для запросов с учитываемыми методами
+func WithRequired(required bool) Option {
+	return func(c *Config) {
+		c.required = required
+	}
+}
+
+// WithMaxBodySize задает максимальный размер тела запроса с ключом, по умолчанию 1 МиБ.
+// Запросы с телом большего размера получают 413.
+func WithMaxBodySize(size int64) Option {
+	return func(c *Config) {
+		c.maxBodySize = size
+	}
+}
+
+// Middleware реализует заголовок Idempotency-Key (draft-ietf-httpapi-idempotency-key-header).
+// Ответ на первый запрос с ключом сохраняется в store и отдается на повторы с тем же путем, строкой запроса и телом.
+// Конкурентный повтор получает 409, повтор с другим телом 422.
+// Ответы с кодом 5xx и ошибки обработчика не сохраняются, чтобы запрос можно было повторить.
+func Middleware(store Store, opts ...Option) transport.Middleware {
+	config := Config{
+		methods:     []string{http.MethodPost, http.MethodPatch},
+		ttl:         defaultTTL,
+		lockTTL:     defaultLockTTL,
+		maxBodySize: defaultMaxBodySize,
+	}
+
+	for _, applyOpt := range opts {
+		applyOpt(&config)
+	}
+
+	return func(next transport.Handler) transport.Handler {
+		return func(req transport.Request, resp transport.Response) error {
+			if !slices.Contains(config.methods, req.Method()) {
+				return next(req, resp)
+			}
+
+			idempotencyKey := req.Header(HeaderIdempotencyKey)
+			if idempotencyKey == "" {
+				if config.required {
+					resp.WriteData(req, transport.NewProblem(http.StatusBadRequest, "Idempotency-Key header is required"))
+					return nil
+				}
+
+				return next(req, resp)
+			}
+			if len(idempotencyKey) > maxKeyLength {
+				resp.WriteData(req, transport.NewProblem(http.StatusBadRequest, "Idempotency-Key header is too long"))
+				return nil
+			}
+
+			// тело читается целиком для вычисления отпечатка, поэтому его размер ограничен
+			body, err := io.ReadAll(io.LimitReader(req.Body(), config.maxBodySize+1))
+			if err != nil {
+				return err
+			}
+			if int64(len(body)) > config.maxBodySize {
+				resp.WriteData(req, transport.NewProblem(http.StatusRequestEntityTooLarge, "request body is too large"))
+				return nil
+			}
+			req = transport.WithBody(req, body)
+
+			ctx := req.Context()
+			key := storeKey(req, idempotencyKey)
+			fingerprint := Fingerprint(req.Method(), req.Path(), req.Queries().Encode(), body)
+
+			record, created, err := store.Begin(ctx, key, fingerprint, config.lockTTL)
+			if err != nil {
+				return err
+			}
+
+			if !created {
+				switch {
+				case record.Fingerprint != fingerprint:
+					resp.WriteData(req, transport.NewProblem(http.StatusUnprocessableEntity,
+						"Idempotency-Key is already used for a different request"))
+				case record.Response == nil:
+					resp.WriteData(req, transport.NewProblem(http.StatusConflict,
+						"a request with the same Idempotency-Key is being processed"))
+				default:
+					replay(resp, record.Response)
+				}
+
+				return nil
+			}
+
+			// ключ освобождается и при панике в next, иначе он остается занятым до истечения lockTTL
+			keep := false
+			defer func() {
+				if !keep {
+					_ = store.Release(ctx, key)
+				}
+			}()
+
+			buf := transport.NewResponseBuffer(transport.WriteResponseOf(resp))
+			if err := next(req, buf); err != nil {
+				if buf.Written() {
+					buf.FlushTo(resp)
+				}
+
+				return err
+			}
+
+			stored := Response{StatusCode: buf.StatusCode(), Header: buf.Header(), Body: buf.Bytes()}
+			if stored.StatusCode < http.StatusInternalServerError {
+				// запись, истекшую и занятую другим запросом, освобождать нельзя
+				err := store.Complete(ctx, key, fingerprint, stored, config.ttl)
+				keep = err == nil || errors.Is(err, ErrLockLost)
+			}
+
+			buf.FlushTo(resp)
+
+			return nil
+		}
+	}
+}
+
+// Fingerprint вычисляет отпечаток запроса по методу, пути, строке запроса и телу
+func Fingerprint(method, path, query string, body []byte) string {
+	h := sha256.New()
+	_, _ = io.WriteString(h, method)
+	_, _ = h.Write([]byte{0})
+	_, _ = io.WriteString(h, path)
+	_, _ = h.Write([]byte{0})
+	_, _ = io.WriteString(h, query)
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// storeKey объединяет ключ идемпотентности с идентификатором клиента
+func storeKey(req transport.Request, idempotencyKey string) string {
+	principal, _ := transport.PrincipalFromContext(req.Context())
+	return principal + "\x00" + idempotencyKey
+}
+
+func replay(resp transport.Response, stored *Response) {
+	for k, values := range stored.Header {
+		for i, v := range values {
+			if i == 0 {
+				resp.SetHeader(k, v)
+			} else {
+				transport.AddHeader(resp, k, v)
+			}
+		}
+	}
+	resp.SetHeader(HeaderReplayed, "true")
+	resp.SetBody(stored.Body, stored.StatusCode)
+}
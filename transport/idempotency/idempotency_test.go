@@ -0,0 +1,238 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/go-mosaic/runtime/transport"
+	transportchi "github.com/go-mosaic/runtime/transport/chi"
+)
+
+type order struct {
+	Item string `json:"item"`
+}
+
+func TestMiddleware(t *testing.T) {
+	var created atomic.Int32
+	inFlight := make(chan struct{})
+	unblock := make(chan struct{})
+
+	router := chi.NewRouter()
+	tr := transportchi.NewChiTransport(router)
+	tr.AddRoute(http.MethodPost, "/orders", func(req transport.Request, resp transport.Response) error {
+		var o order
+		if err := req.ReadData(&o); err != nil {
+			return err
+		}
+		if o.Item == "slow" {
+			close(inFlight)
+			<-unblock
+		}
+		n := created.Add(1)
+		resp.SetHeader("X-Order", o.Item)
+		resp.SetBody([]byte(strings.Repeat("#", int(n))), http.StatusCreated)
+		return nil
+	}, Middleware(NewMemoryStore()))
+
+	do := func(key, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+		if key != "" {
+			r.Header.Set(HeaderIdempotencyKey, key)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w
+	}
+
+	tests := []struct {
+		name         string
+		key          string
+		body         string
+		wantStatus   int
+		wantBody     string
+		wantReplayed bool
+	}{
+		{name: "first request", key: "k1", body: `{"item":"a"}`, wantStatus: http.StatusCreated, wantBody: "#"},
+		{name: "retry is replayed", key: "k1", body: `{"item":"a"}`, wantStatus: http.StatusCreated, wantBody: "#", wantReplayed: true},
+		{name: "key reused with other payload", key: "k1", body: `{"item":"b"}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "new key", key: "k2", body: `{"item":"a"}`, wantStatus: http.StatusCreated, wantBody: "##"},
+		{name: "no key", body: `{"item":"a"}`, wantStatus: http.StatusCreated, wantBody: "###"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := do(tt.key, tt.body)
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %v, want %v", w.Code, tt.wantStatus)
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", w.Body.String(), tt.wantBody)
+			}
+			if replayed := w.Header().Get(HeaderReplayed) == "true"; replayed != tt.wantReplayed {
+				t.Errorf("replayed = %v, want %v", replayed, tt.wantReplayed)
+			}
+			if tt.wantReplayed && w.Header().Get("X-Order") != "a" {
+				t.Errorf("replayed headers are missing")
+			}
+		})
+	}
+
+	t.Run("concurrent duplicate", func(t *testing.T) {
+		done := make(chan *httptest.ResponseRecorder)
+		go func() { done <- do("k3", `{"item":"slow"}`) }()
+		<-inFlight
+
+		if w := do("k3", `{"item":"slow"}`); w.Code != http.StatusConflict {
+			t.Errorf("status = %v, want %v", w.Code, http.StatusConflict)
+		}
+
+		close(unblock)
+		if w := <-done; w.Code != http.StatusCreated {
+			t.Errorf("first request status = %v, want %v", w.Code, http.StatusCreated)
+		}
+	})
+}
+
+func TestMiddlewareReleasesOnError(t *testing.T) {
+	var calls int
+	router := chi.NewRouter()
+	tr := transportchi.NewChiTransport(router)
+	tr.AddRoute(http.MethodPost, "/", func(req transport.Request, resp transport.Response) error {
+		calls++
+		if calls == 1 {
+			resp.SetBody(nil, http.StatusBadGateway)
+			return nil
+		}
+		body, _ := io.ReadAll(req.Body())
+		resp.SetBody(body, http.StatusOK)
+		return nil
+	}, Middleware(NewMemoryStore()))
+
+	for _, wantStatus := range []int{http.StatusBadGateway, http.StatusOK} {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("payload"))
+		r.Header.Set(HeaderIdempotencyKey, "k")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		if w.Code != wantStatus {
+			t.Errorf("status = %v, want %v", w.Code, wantStatus)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("handler calls = %v, want 2", calls)
+	}
+}
+
+func TestMiddlewareRequestIdentity(t *testing.T) {
+	router := chi.NewRouter()
+	tr := transportchi.NewChiTransport(router)
+	tr.AddRoute(http.MethodPost, "/orders", func(req transport.Request, resp transport.Response) error {
+		resp.SetBody(nil, http.StatusCreated)
+		return nil
+	}, Middleware(NewMemoryStore(), WithMaxBodySize(8)))
+
+	tests := []struct {
+		name       string
+		key        string
+		target     string
+		body       string
+		wantStatus int
+	}{
+		{name: "first request", key: "k1", target: "/orders?dry=false", body: "payload", wantStatus: http.StatusCreated},
+		{name: "other query", key: "k1", target: "/orders?dry=true", body: "payload", wantStatus: http.StatusUnprocessableEntity},
+		{name: "body at limit", key: "k2", target: "/orders", body: "12345678", wantStatus: http.StatusCreated},
+		{name: "body too large", key: "k3", target: "/orders", body: "123456789", wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "large body without key", target: "/orders", body: "123456789", wantStatus: http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body))
+			if tt.key != "" {
+				r.Header.Set(HeaderIdempotencyKey, tt.key)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, r)
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %v, want %v", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestMemoryStoreComplete(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		begin       string
+		advance     time.Duration
+		fingerprint string
+		wantErr     error
+	}{
+		{name: "locked record", begin: "f", fingerprint: "f"},
+		{name: "missing record", fingerprint: "f", wantErr: ErrLockLost},
+		{name: "expired lock", begin: "f", advance: 2 * time.Minute, fingerprint: "f", wantErr: ErrLockLost},
+		{name: "other request", begin: "other", fingerprint: "f", wantErr: ErrLockLost},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := now
+			store := NewMemoryStore(WithClock(func() time.Time { return clock }))
+			if tt.begin != "" {
+				_, _, _ = store.Begin(ctx, "k", tt.begin, time.Minute)
+			}
+			clock = clock.Add(tt.advance)
+
+			err := store.Complete(ctx, "k", tt.fingerprint, Response{StatusCode: http.StatusOK}, time.Hour)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Complete() error = %v, want %v", err, tt.wantErr)
+			}
+
+			record, created, _ := store.Begin(ctx, "k", tt.fingerprint, time.Minute)
+			if tt.wantErr == nil && (created || record.Response == nil) {
+				t.Errorf("Begin() after Complete() = %+v, %v, want stored response", record, created)
+			}
+			if tt.wantErr != nil && record != nil && record.Response != nil {
+				t.Errorf("Complete() stored response for %s", tt.name)
+			}
+		})
+	}
+}
+
+func TestMiddlewareReleasesOnPanic(t *testing.T) {
+	var calls int
+	router := chi.NewRouter()
+	tr := transportchi.NewChiTransport(router)
+	tr.AddRoute(http.MethodPost, "/", func(req transport.Request, resp transport.Response) error {
+		calls++
+		if calls == 1 {
+			panic("boom")
+		}
+		resp.SetBody(nil, http.StatusOK)
+		return nil
+	}, Middleware(NewMemoryStore()))
+
+	do := func() int {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("payload"))
+		r.Header.Set(HeaderIdempotencyKey, "k")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	func() {
+		defer func() { _ = recover() }()
+		do()
+	}()
+	if code := do(); code != http.StatusOK {
+		t.Errorf("retry after panic status = %v, want %v", code, http.StatusOK)
+	}
+}
@@ -0,0 +1,134 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrLockLost запись ключа истекла или принадлежит другому запросу
+var ErrLockLost = errors.New("idempotency: record is missing or belongs to a different request")
+
+// Response сохраненный ответ на первый запрос с ключом
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Record запись о запросе с ключом идемпотентности.
+// Response равен nil, пока первый запрос еще обрабатывается.
+type Record struct {
+	Fingerprint string
+	Response    *Response
+}
+
+// Store хранилище записей идемпотентности
+type Store interface {
+	// Begin атомарно создает запись в состоянии обработки.
+	// Если запись уже существует, возвращает ее и false.
+	Begin(ctx context.Context, key, fingerprint string, ttl time.Duration) (*Record, bool, error)
+
+	// Complete сохраняет ответ в существующую запись ключа с отпечатком fingerprint.
+	// Если записи нет или ее отпечаток отличается, возвращает ErrLockLost.
+	Complete(ctx context.Context, key, fingerprint string, resp Response, ttl time.Duration) error
+
+	// Release удаляет запись, чтобы клиент мог повторить запрос
+	Release(ctx context.Context, key string) error
+}
+
+const sweepEvery = 1024
+
+type memoryRecord struct {
+	record  Record
+	expires time.Time
+}
+
+// MemoryStore хранилище записей в памяти с ограниченным временем жизни
+type MemoryStore struct {
+	mu      sync.Mutex
+	records map[string]*memoryRecord
+	begins  int
+	now     func() time.Time
+}
+
+// MemoryStoreOption функциональная опция MemoryStore
+type MemoryStoreOption func(*MemoryStore)
+
+// WithClock задает источник текущего времени
+func WithClock(now func() time.Time) MemoryStoreOption {
+	return func(s *MemoryStore) {
+		s.now = now
+	}
+}
+
+// NewMemoryStore создает новый экземпляр MemoryStore
+func NewMemoryStore(opts ...MemoryStoreOption) *MemoryStore {
+	s := &MemoryStore{
+		records: make(map[string]*memoryRecord),
+		now:     time.Now,
+	}
+
+	for _, applyOpt := range opts {
+		applyOpt(s)
+	}
+
+	return s
+}
+
+func (s *MemoryStore) Begin(_ context.Context, key, fingerprint string, ttl time.Duration) (*Record, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.begins++
+	if s.begins%sweepEvery == 0 {
+		s.sweep(now)
+	}
+
+	if r, ok := s.records[key]; ok && !now.After(r.expires) {
+		record := r.record
+		return &record, false, nil
+	}
+
+	s.records[key] = &memoryRecord{
+		record:  Record{Fingerprint: fingerprint},
+		expires: now.Add(ttl),
+	}
+
+	return nil, true, nil
+}
+
+func (s *MemoryStore) Complete(_ context.Context, key, fingerprint string, resp Response, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	r, ok := s.records[key]
+	if !ok || now.After(r.expires) || r.record.Fingerprint != fingerprint || r.record.Response != nil {
+		return ErrLockLost
+	}
+	r.record.Response = &resp
+	r.expires = now.Add(ttl)
+
+	return nil
+}
+
+func (s *MemoryStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+
+	return nil
+}
+
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, r := range s.records {
+		if now.After(r.expires) {
+			delete(s.records, key)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+// Package transporttest содержит вспомогательные функции для проверки поведения
+// middleware и транспортов на всех адаптерах
+package transporttest
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	fastrouter "github.com/fasthttp/router"
+	"github.com/gin-gonic/gin"
+	"github.com/go-chi/chi/v5"
+	"github.com/gofiber/fiber/v3"
+	"github.com/gorilla/mux"
+	"github.com/julienschmidt/httprouter"
+	"github.com/labstack/echo/v4"
+	"github.com/valyala/fasthttp"
+
+	"github.com/go-mosaic/runtime/transport"
+	transportchi "github.com/go-mosaic/runtime/transport/chi"
+	transportecho "github.com/go-mosaic/runtime/transport/echo"
+	transportfasthttp "github.com/go-mosaic/runtime/transport/fasthttp"
+	transportfiber "github.com/go-mosaic/runtime/transport/fiber"
+	transportgin "github.com/go-mosaic/runtime/transport/gin"
+	transportgorilla "github.com/go-mosaic/runtime/transport/gorilla"
+	transporthttp "github.com/go-mosaic/runtime/transport/http"
+	transporthttprouter "github.com/go-mosaic/runtime/transport/httprouter"
+)
+
+// Server транспорт вместе с функцией выполнения запроса к нему
+type Server struct {
+	Transport transport.Transport
+	Do        func(t *testing.T, r *http.Request) *http.Response
+}
+
+// Adapter фабрика Server для одного адаптера
+type Adapter struct {
+	Name string
+	New  func(opts ...transport.Option) Server
+}
+
+// Adapters возвращает фабрики для всех поддерживаемых адаптеров
+func Adapters() []Adapter {
+	return []Adapter{
+		{Name: "http", New: newHTTP},
+		{Name: "chi", New: newChi},
+		{Name: "echo", New: newEcho},
+		{Name: "fiber", New: newFiber},
+		{Name: "gin", New: newGin},
+		{Name: "fasthttp", New: newFastHTTP},
+		{Name: "gorilla", New: newGorilla},
+		{Name: "httprouter", New: newHTTPRouter},
+	}
+}
+
+func serveHandler(h http.Handler) func(t *testing.T, r *http.Request) *http.Response {
+	return func(_ *testing.T, r *http.Request) *http.Response {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		return w.Result()
+	}
+}
+
+func newHTTP(opts ...transport.Option) Server {
+	tr := transporthttp.NewHTTPTransport(opts...)
+	return Server{Transport: tr, Do: serveHandler(tr)}
+}
+
+func newChi(opts ...transport.Option) Server {
+	router := chi.NewRouter()
+	return Server{Transport: transportchi.NewChiTransport(router, opts...), Do: serveHandler(router)}
+}
+
+func newEcho(opts ...transport.Option) Server {
+	e := echo.New()
+	return Server{Transport: transportecho.NewEchoTransport(e, opts...), Do: serveHandler(e)}
+}
+
+func newGin(opts ...transport.Option) Server {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+
+	return Server{Transport: transportgin.NewGinTransport(engine, opts...), Do: serveHandler(engine)}
+}
+
+func newFiber(opts ...transport.Option) Server {
+	app := fiber.New()
+	return Server{
+		Transport: transportfiber.NewFiberTransport(app, opts...),
+		Do: func(t *testing.T, r *http.Request) *http.Response {
+			t.Helper()
+
+			resp, err := app.Test(r, fiber.TestConfig{Timeout: 5 * time.Second}) //nolint:mnd
+			if err != nil {
+				t.Fatalf("fiber app.Test() error = %v", err)
+			}
+
+			return resp
+		},
+	}
+}
+
+func newGorilla(opts ...transport.Option) Server {
+	tr := transportgorilla.NewGorillaTransport(mux.NewRouter(), opts...)
+	return Server{Transport: tr, Do: serveHandler(tr)}
+}
+
+func newHTTPRouter(opts ...transport.Option) Server {
+	tr := transporthttprouter.NewHTTPRouterTransport(httprouter.New(), opts...)
+	return Server{Transport: tr, Do: serveHandler(tr)}
+}
+
+func newFastHTTP(opts ...transport.Option) Server {
+	tr := transportfasthttp.NewFastHTTPTransport(fastrouter.New(), opts...)
+	return Server{Transport: tr, Do: serveFastHTTP(tr.Handler)}
+}
+
+// serveFastHTTP выполняет запрос net/http через fasthttp.RequestHandler, передавая запрос и ответ
+// в сериализованном виде, как это делает fasthttp.Server
+func serveFastHTTP(h fasthttp.RequestHandler) func(t *testing.T, r *http.Request) *http.Response {
+	return func(t *testing.T, r *http.Request) *http.Response {
+		t.Helper()
+
+		var buf bytes.Buffer
+		if err := r.Write(&buf); err != nil {
+			t.Fatalf("write request: %v", err)
+		}
+
+		var req fasthttp.Request
+		if err := req.Read(bufio.NewReader(&buf)); err != nil {
+			t.Fatalf("read fasthttp request: %v", err)
+		}
+
+		var ctx fasthttp.RequestCtx
+		ctx.Init(&req, &net.TCPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 1234}, nil) //nolint:mnd
+		h(&ctx)
+		ctx.Response.SkipBody = ctx.IsHead()
+
+		buf.Reset()
+		w := bufio.NewWriter(&buf)
+		if err := ctx.Response.Write(w); err != nil {
+			t.Fatalf("write fasthttp response: %v", err)
+		}
+		w.Flush()
+
+		resp, err := http.ReadResponse(bufio.NewReader(&buf), r)
+		if err != nil {
+			t.Fatalf("read response: %v", err)
+		}
+
+		return resp
+	}
+}
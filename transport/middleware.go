@@ -0,0 +1,99 @@
+package transport
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// Порядок middleware одинаков во всех транспортах:
+//
+//	глобальные (Use) → middleware групп, начиная с внешней → middleware маршрута → handler
+//
+// Внутри глобального уровня и уровня групп первая переданная middleware выполняется первой, то есть является внешней.
+// Middleware маршрута, как и до появления групп, применяются по очереди к handler,
+// поэтому внешней является последняя переданная в AddRoute middleware.
+// Все middleware получают полностью инициализированные запрос и ответ адаптера,
+// а запрос, переданный в next, в том числе после WithContext, доходит до следующих звеньев без изменений.
+
+// ErrAdapterMismatch возвращается middleware, преобразованной из middleware роутера,
+// если запрос или ответ созданы другим адаптером
+var ErrAdapterMismatch = errors.New("transport: request was created by another adapter")
+
+// Chain объединяет middleware в одну; первая в списке является внешней
+func Chain(middlewares ...Middleware) Middleware {
+	return func(next Handler) Handler {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			next = middlewares[i](next)
+		}
+
+		return next
+	}
+}
+
+// MiddlewareStack набор middleware транспорта или группы.
+// Цепочка маршрута собирается при первом запросе и пересобирается после изменения стека,
+// поэтому Use действует и на маршруты, зарегистрированные ранее.
+type MiddlewareStack struct {
+	parent      *MiddlewareStack
+	version     *atomic.Uint64
+	mu          sync.RWMutex
+	middlewares []Middleware
+}
+
+// NewMiddlewareStack создает стек middleware; стек группы получает стек родителя в parent
+func NewMiddlewareStack(parent *MiddlewareStack, middlewares ...Middleware) *MiddlewareStack {
+	s := &MiddlewareStack{parent: parent, middlewares: middlewares}
+	if parent != nil {
+		s.version = parent.version
+	} else {
+		s.version = new(atomic.Uint64)
+	}
+
+	return s
+}
+
+// Use добавляет middleware в конец стека
+func (s *MiddlewareStack) Use(middlewares ...Middleware) {
+	s.mu.Lock()
+	s.middlewares = append(s.middlewares, middlewares...)
+	s.mu.Unlock()
+
+	s.version.Add(1)
+}
+
+// Middlewares возвращает middleware стека вместе с middleware родителей, начиная с внешней
+func (s *MiddlewareStack) Middlewares() []Middleware {
+	var parent []Middleware
+	if s.parent != nil {
+		parent = s.parent.Middlewares()
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append(parent, slices.Clone(s.middlewares)...)
+}
+
+type compiledHandler struct {
+	version uint64
+	handler Handler
+}
+
+// Handler оборачивает handler middleware стека
+func (s *MiddlewareStack) Handler(handler Handler) Handler {
+	var compiled atomic.Pointer[compiledHandler]
+
+	return func(req Request, resp Response) error {
+		version := s.version.Load()
+
+		c := compiled.Load()
+		if c == nil || c.version != version {
+			c = &compiledHandler{version: version, handler: Chain(s.Middlewares()...)(handler)}
+			compiled.Store(c)
+		}
+
+		return c.handler(req, resp)
+	}
+}
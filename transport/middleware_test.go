@@ -0,0 +1,135 @@
+package transport_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/go-mosaic/runtime/transport"
+	"github.com/go-mosaic/runtime/transport/internal/transporttest"
+)
+
+type ctxKey struct{}
+
+func TestChain(t *testing.T) {
+	var calls []string
+	record := func(name string) transport.Middleware {
+		return func(next transport.Handler) transport.Handler {
+			return func(req transport.Request, resp transport.Response) error {
+				calls = append(calls, name)
+				return next(req, resp)
+			}
+		}
+	}
+
+	h := transport.Chain(record("a"), record("b"), record("c"))(func(req transport.Request, resp transport.Response) error {
+		calls = append(calls, "handler")
+		return nil
+	})
+	_ = h(nil, nil)
+
+	if want := []string{"a", "b", "c", "handler"}; !slices.Equal(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	for _, adapter := range transporttest.Adapters() {
+		t.Run(adapter.Name, func(t *testing.T) {
+			var calls []string
+			record := func(name string) transport.Middleware {
+				return func(next transport.Handler) transport.Handler {
+					return func(req transport.Request, resp transport.Response) error {
+						calls = append(calls, name)
+						return next(req, resp)
+					}
+				}
+			}
+
+			srv := adapter.New()
+			srv.Transport.Use(record("global1"), record("global2"))
+
+			api := srv.Transport.Group("/api", record("group"))
+			api.Group("/v1", record("subgroup")).AddRoute(http.MethodGet, "/items", func(req transport.Request, resp transport.Response) error {
+				calls = append(calls, "handler")
+				resp.SetBody([]byte("ok"), http.StatusOK)
+				return nil
+			}, record("route1"), record("route2"))
+
+			// middleware, добавленные после регистрации маршрутов, тоже применяются
+			srv.Transport.Use(record("global3"))
+			api.Use(record("group2"))
+
+			resp := srv.Do(t, httptest.NewRequest(http.MethodGet, "/api/v1/items", nil))
+			resp.Body.Close()
+
+			// middleware маршрута применяются по очереди, поэтому последняя является внешней
+			want := []string{"global1", "global2", "global3", "group", "group2", "subgroup", "route2", "route1", "handler"}
+			if !slices.Equal(calls, want) {
+				t.Errorf("calls = %v, want %v", calls, want)
+			}
+		})
+	}
+}
+
+func TestGlobalMiddlewareRequest(t *testing.T) {
+	type payload struct {
+		Name string `json:"name"`
+	}
+
+	for _, adapter := range transporttest.Adapters() {
+		t.Run(adapter.Name, func(t *testing.T) {
+			srv := adapter.New()
+			srv.Transport.Use(func(next transport.Handler) transport.Handler {
+				return func(req transport.Request, resp transport.Response) error {
+					var p payload
+					if err := req.ReadData(&p); err != nil {
+						resp.WriteData(req, transport.NewProblem(http.StatusBadRequest, err.Error()))
+						return nil
+					}
+					if p.Name == "" {
+						resp.WriteData(req, transport.NewProblem(http.StatusUnprocessableEntity, "name is required"))
+						return nil
+					}
+
+					ctx := context.WithValue(req.Context(), ctxKey{}, p.Name)
+					return next(req.WithContext(ctx), resp)
+				}
+			})
+			srv.Transport.AddRoute(http.MethodPost, "/greet", func(req transport.Request, resp transport.Response) error {
+				name, _ := req.Context().Value(ctxKey{}).(string)
+				resp.SetBody([]byte("hello "+name), http.StatusOK)
+				return nil
+			})
+
+			tests := []struct {
+				name       string
+				body       string
+				wantStatus int
+				wantBody   string
+			}{
+				{name: "context propagated", body: `{"name":"bob"}`, wantStatus: http.StatusOK, wantBody: "hello bob"},
+				{name: "write data", body: `{}`, wantStatus: http.StatusUnprocessableEntity},
+			}
+			for _, tt := range tests {
+				r := httptest.NewRequest(http.MethodPost, "/greet", strings.NewReader(tt.body))
+				r.Header.Set("Content-Type", "application/json")
+				resp := srv.Do(t, r)
+
+				body, _ := io.ReadAll(resp.Body)
+				resp.Body.Close()
+
+				if resp.StatusCode != tt.wantStatus {
+					t.Errorf("%s: status = %d, want %d", tt.name, resp.StatusCode, tt.wantStatus)
+				}
+				if tt.wantBody != "" && string(body) != tt.wantBody {
+					t.Errorf("%s: body = %q, want %q", tt.name, body, tt.wantBody)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package transport
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// JoinPattern объединяет префикс группы и шаблон маршрута.
+// Корневой маршрут группы "/" совпадает с префиксом как с завершающим "/", так и без него.
+func JoinPattern(prefix, pattern string) string {
+	prefix = strings.TrimSuffix(prefix, "/")
+
+	switch {
+	case pattern == "" && prefix == "":
+		return "/"
+	case pattern == "":
+		return prefix
+	case prefix == "":
+		return pattern
+	case pattern == "/":
+		return prefix + "/?"
+	default:
+		return prefix + pattern
+	}
+}
+
+// StripPrefix возвращает http.Handler, удаляющий из пути запроса сегменты префикса prefix.
+// В отличие от http.StripPrefix префикс может содержать параметры, например "/tenants/{tenant}".
+func StripPrefix(prefix string, h http.Handler) http.Handler {
+	p, err := ParsePattern(prefix)
+	if err != nil {
+		panic(err)
+	}
+
+	n := len(p.Segments)
+	if n == 0 {
+		return h
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, ok := stripSegments(r.URL.Path, n)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		r2 := new(http.Request)
+		*r2 = *r
+		r2.URL = new(url.URL)
+		*r2.URL = *r.URL
+		r2.URL.Path = path
+		if r.URL.RawPath != "" {
+			r2.URL.RawPath, _ = stripSegments(r.URL.RawPath, n)
+		}
+
+		h.ServeHTTP(w, r2)
+	})
+}
+
+// stripSegments удаляет n первых сегментов пути, возвращая "/" для пустого остатка
+func stripSegments(path string, n int) (string, bool) {
+	rest := path
+	for range n {
+		if !strings.HasPrefix(rest, "/") {
+			return "", false
+		}
+
+		rest = rest[1:]
+		if i := strings.IndexByte(rest, '/'); i >= 0 {
+			rest = rest[i:]
+		} else {
+			rest = ""
+		}
+	}
+
+	if rest == "" {
+		rest = "/"
+	}
+
+	return rest, true
+}
+
+// HandlerOf возвращает подключаемый транспорт как http.Handler
+func HandlerOf(sub Transport) (http.Handler, error) {
+	h, ok := sub.(http.Handler)
+	if !ok {
+		return nil, fmt.Errorf("transport: cannot mount %T: it does not implement http.Handler", sub)
+	}
+
+	return h, nil
+}
+
+// ServeMounted передает запрос подключенному транспорту h.
+// w и r — исходные http.ResponseWriter и *http.Request адаптера; w равен nil, если ответ адаптера недоступен.
+// Если ответ обернут middleware, h пишет ответ через resp, чтобы обёртки видели статус и тело.
+func ServeMounted(h http.Handler, req Request, resp Response, w http.ResponseWriter, r *http.Request) {
+	r = r.WithContext(req.Context())
+	r.Body = req.Body()
+
+	if w == nil || UnwrapResponse(resp) != resp {
+		w = &mountedResponseWriter{resp: resp, native: w}
+	}
+
+	h.ServeHTTP(w, r)
+}
+
+// mountedResponseWriter http.ResponseWriter, записывающий ответ через Response
+type mountedResponseWriter struct {
+	resp        Response
+	native      http.ResponseWriter
+	header      http.Header
+	wroteHeader bool
+}
+
+func (w *mountedResponseWriter) Header() http.Header {
+	// заголовки обёрток над ответом адаптера записываются в заголовки исходного ответа
+	if w.native != nil {
+		return w.native.Header()
+	}
+	if w.header == nil {
+		w.header = make(http.Header)
+	}
+
+	return w.header
+}
+
+func (w *mountedResponseWriter) WriteHeader(statusCode int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	setHeaders(w.resp, w.header)
+	w.resp.WriteHeader(statusCode)
+}
+
+func (w *mountedResponseWriter) Write(body []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	return w.resp.Write(body)
+}
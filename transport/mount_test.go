@@ -0,0 +1,237 @@
+package transport_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/go-mosaic/runtime/transport"
+	transporthttp "github.com/go-mosaic/runtime/transport/http"
+	"github.com/go-mosaic/runtime/transport/internal/transporttest"
+)
+
+func TestJoinPattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		prefix  string
+		pattern string
+		want    string
+	}{
+		{name: "no prefix", prefix: "", pattern: "/users", want: "/users"},
+		{name: "prefix", prefix: "/api", pattern: "/users", want: "/api/users"},
+		{name: "prefix with slash", prefix: "/api/", pattern: "/users", want: "/api/users"},
+		{name: "group root", prefix: "/api", pattern: "/", want: "/api/?"},
+		{name: "empty pattern", prefix: "/api", pattern: "", want: "/api"},
+		{name: "root", prefix: "/", pattern: "", want: "/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := transport.JoinPattern(tt.prefix, tt.pattern); got != tt.want {
+				t.Errorf("JoinPattern() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func textHandler(text string, params ...string) transport.Handler {
+	return func(req transport.Request, resp transport.Response) error {
+		body := text
+		for _, p := range params {
+			body += " " + req.PathValue(p)
+		}
+		resp.SetBody([]byte(body), http.StatusOK)
+		return nil
+	}
+}
+
+type pathCase struct {
+	path       string
+	wantStatus int
+	wantBody   string
+	wantHeader string
+}
+
+func checkPaths(t *testing.T, srv transporttest.Server, cases []pathCase) {
+	t.Helper()
+
+	for _, c := range cases {
+		resp := srv.Do(t, httptest.NewRequest(http.MethodGet, c.path, nil))
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode != c.wantStatus {
+			t.Errorf("%s: status = %d, want %d", c.path, resp.StatusCode, c.wantStatus)
+			continue
+		}
+		if c.wantBody != "" && string(body) != c.wantBody {
+			t.Errorf("%s: body = %q, want %q", c.path, body, c.wantBody)
+		}
+		if got := resp.Header.Get("X-Group"); c.wantStatus == http.StatusOK && got != c.wantHeader {
+			t.Errorf("%s: X-Group = %q, want %q", c.path, got, c.wantHeader)
+		}
+	}
+}
+
+func TestGroup(t *testing.T) {
+	groupHeader := func(next transport.Handler) transport.Handler {
+		return func(req transport.Request, resp transport.Response) error {
+			resp.SetHeader("X-Group", "v1")
+			return next(req, resp)
+		}
+	}
+
+	for _, adapter := range transporttest.Adapters() {
+		t.Run(adapter.Name, func(t *testing.T) {
+			srv := adapter.New()
+			srv.Transport.AddRoute(http.MethodGet, "/health", textHandler("ok"))
+
+			api := srv.Transport.Group("/api/v1", groupHeader)
+			api.AddRoute(http.MethodGet, "/", textHandler("root"))
+			api.AddRouteWith(http.MethodGet, "/users/{id:int}", textHandler("user", "id"), transport.WithName("users.get"))
+			api.Group("/tenants/{tenant}").AddRoute(http.MethodGet, "/info", textHandler("tenant", "tenant"))
+
+			checkPaths(t, srv, []pathCase{
+				{path: "/health", wantStatus: http.StatusOK, wantBody: "ok"},
+				{path: "/api/v1", wantStatus: http.StatusOK, wantBody: "root", wantHeader: "v1"},
+				{path: "/api/v1/", wantStatus: http.StatusOK, wantBody: "root", wantHeader: "v1"},
+				{path: "/api/v1/users/7", wantStatus: http.StatusOK, wantBody: "user 7", wantHeader: "v1"},
+				{path: "/api/v1/users/x", wantStatus: http.StatusNotFound},
+				{path: "/api/v1/tenants/acme/info", wantStatus: http.StatusOK, wantBody: "tenant acme", wantHeader: "v1"},
+			})
+
+			if got, err := srv.Transport.Routes().URL("users.get", map[string]string{"id": "7"}); err != nil || got != "/api/v1/users/7" {
+				t.Errorf("URL() = %v, %v, want /api/v1/users/7", got, err)
+			}
+
+			var patterns []string
+			for _, r := range srv.Transport.Routes().Routes() {
+				patterns = append(patterns, r.Pattern)
+			}
+			want := []string{"/health", "/api/v1/?", "/api/v1/users/{id:int}", "/api/v1/tenants/{tenant}/info"}
+			if len(patterns) != len(want) {
+				t.Fatalf("patterns = %v, want %v", patterns, want)
+			}
+			for i := range want {
+				if patterns[i] != want[i] {
+					t.Errorf("patterns = %v, want %v", patterns, want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestMount(t *testing.T) {
+	for _, adapter := range transporttest.Adapters() {
+		subs := map[string]func() transport.Transport{
+			"native": func() transport.Transport { return adapter.New().Transport },
+			"http":   func() transport.Transport { return transporthttp.NewHTTPTransport() },
+		}
+		for subName, newSub := range subs {
+			t.Run(adapter.Name+"/"+subName, func(t *testing.T) {
+				srv := adapter.New()
+				sub := newSub()
+				sub.AddRoute(http.MethodGet, "/", textHandler("shop"))
+				sub.AddRouteWith(http.MethodGet, "/items/{id}", textHandler("item", "id"), transport.WithName("items.get"))
+				srv.Transport.Mount("/shop", sub)
+
+				checkPaths(t, srv, []pathCase{
+					{path: "/shop", wantStatus: http.StatusOK, wantBody: "shop"},
+					{path: "/shop/items/3", wantStatus: http.StatusOK, wantBody: "item 3"},
+				})
+
+				if got, err := srv.Transport.Routes().URL("items.get", map[string]string{"id": "3"}); err != nil || got != "/shop/items/3" {
+					t.Errorf("URL() = %v, %v, want /shop/items/3", got, err)
+				}
+			})
+		}
+	}
+}
+
+func TestMountGlobalMiddleware(t *testing.T) {
+	auth := func(next transport.Handler) transport.Handler {
+		return func(req transport.Request, resp transport.Response) error {
+			if req.Header("X-Token") != "secret" {
+				resp.SetBody([]byte("unauthorized"), http.StatusUnauthorized)
+				return nil
+			}
+			resp.SetHeader("X-Auth", "ok")
+			return next(req, resp)
+		}
+	}
+
+	for _, adapter := range transporttest.Adapters() {
+		subs := map[string]func() transport.Transport{
+			"native": func() transport.Transport { return adapter.New().Transport },
+			"http":   func() transport.Transport { return transporthttp.NewHTTPTransport() },
+		}
+		for subName, newSub := range subs {
+			t.Run(adapter.Name+"/"+subName, func(t *testing.T) {
+				srv := adapter.New()
+				srv.Transport.Use(auth)
+
+				sub := newSub()
+				sub.AddRoute(http.MethodGet, "/items/{id}", textHandler("item", "id"))
+				srv.Transport.Mount("/admin", sub)
+
+				tests := []struct {
+					token      string
+					wantStatus int
+					wantBody   string
+					wantAuth   string
+				}{
+					{token: "", wantStatus: http.StatusUnauthorized, wantBody: "unauthorized"},
+					{token: "secret", wantStatus: http.StatusOK, wantBody: "item 3", wantAuth: "ok"},
+				}
+				for _, tt := range tests {
+					r := httptest.NewRequest(http.MethodGet, "/admin/items/3", nil)
+					r.Header.Set("X-Token", tt.token)
+
+					resp := srv.Do(t, r)
+					body, _ := io.ReadAll(resp.Body)
+					resp.Body.Close()
+
+					if resp.StatusCode != tt.wantStatus || string(body) != tt.wantBody {
+						t.Errorf("token %q: got %d %q, want %d %q", tt.token, resp.StatusCode, body, tt.wantStatus, tt.wantBody)
+					}
+					if got := resp.Header.Get("X-Auth"); got != tt.wantAuth {
+						t.Errorf("token %q: X-Auth = %q, want %q", tt.token, got, tt.wantAuth)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestMountMultiValueHeaders(t *testing.T) {
+	record := func(next transport.Handler) transport.Handler {
+		return func(req transport.Request, resp transport.Response) error {
+			return next(req, transport.NewResponseRecorder(resp))
+		}
+	}
+
+	for _, adapter := range transporttest.Adapters() {
+		t.Run(adapter.Name, func(t *testing.T) {
+			srv := adapter.New()
+			srv.Transport.Use(record)
+
+			sub := transporthttp.NewHTTPTransport()
+			sub.AddRoute(http.MethodGet, "/login", func(req transport.Request, resp transport.Response) error {
+				transport.AddHeader(resp, "Set-Cookie", "session=1")
+				transport.AddHeader(resp, "Set-Cookie", "theme=dark")
+				resp.SetBody([]byte("ok"), http.StatusOK)
+				return nil
+			})
+			srv.Transport.Mount("/auth", sub)
+
+			resp := srv.Do(t, httptest.NewRequest(http.MethodGet, "/auth/login", nil))
+			resp.Body.Close()
+
+			if got := resp.Header.Values("Set-Cookie"); !slices.Equal(got, []string{"session=1", "theme=dark"}) {
+				t.Errorf("Set-Cookie = %q, want session=1 and theme=dark", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,401 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Канонический синтаксис шаблонов пути, общий для всех транспортов:
+//
+//	/users/{id}          именованный параметр, занимающий сегмент целиком
+//	/users/{id:uuid}     параметр с ограничением: int, uint, uuid, alpha, alnum, slug или регулярное выражение
+//	/files/{path...}     catch-all параметр, может быть только последним сегментом
+//	/users/?             необязательный завершающий "/": совпадает с /users и /users/
+//
+// Для обратной совместимости также принимаются ":id" и завершающий "*".
+// Каждый транспорт переводит шаблон в синтаксис своего роутера, а ограничения проверяются одинаково на всех.
+
+// Segment сегмент шаблона пути
+type Segment struct {
+	Literal    string
+	Param      string
+	Constraint string
+	CatchAll   bool
+}
+
+// TrailingSlash режим завершающего "/" шаблона
+type TrailingSlash int
+
+const (
+	TrailingSlashNone TrailingSlash = iota
+	TrailingSlashRequired
+	TrailingSlashOptional
+)
+
+// Pattern разобранный шаблон пути
+type Pattern struct {
+	Segments      []Segment
+	TrailingSlash TrailingSlash
+	constraints   map[string]func(string) bool
+}
+
+var (
+	constraintsMu sync.RWMutex
+	constraints   = map[string]func(string) bool{
+		"int":   regexp.MustCompile(`^-?[0-9]+$`).MatchString,
+		"uint":  regexp.MustCompile(`^[0-9]+$`).MatchString,
+		"uuid":  regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`).MatchString,
+		"alpha": regexp.MustCompile(`^[a-zA-Z]+$`).MatchString,
+		"alnum": regexp.MustCompile(`^[a-zA-Z0-9]+$`).MatchString,
+		"slug":  regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`).MatchString,
+	}
+)
+
+// AddPatternConstraint регистрирует именованное ограничение для параметров шаблонов
+func AddPatternConstraint(name string, match func(value string) bool) {
+	constraintsMu.Lock()
+	defer constraintsMu.Unlock()
+
+	constraints[name] = match
+}
+
+func lookupConstraint(name string) (func(string) bool, bool) {
+	constraintsMu.RLock()
+	defer constraintsMu.RUnlock()
+
+	match, ok := constraints[name]
+	return match, ok
+}
+
+// ParsePattern разбирает шаблон пути в каноническом синтаксисе
+func ParsePattern(pattern string) (Pattern, error) {
+	if !strings.HasPrefix(pattern, "/") {
+		return Pattern{}, fmt.Errorf("transport: pattern %q must start with /", pattern)
+	}
+
+	p := Pattern{constraints: make(map[string]func(string) bool)}
+
+	rest := NormalizePattern(pattern)[1:]
+	switch {
+	case strings.HasSuffix(rest, "/?"):
+		p.TrailingSlash = TrailingSlashOptional
+		rest = strings.TrimSuffix(rest, "/?")
+	case rest == "?":
+		return Pattern{}, fmt.Errorf("transport: pattern %q: optional trailing slash requires a path", pattern)
+	case rest != "" && strings.HasSuffix(rest, "/"):
+		p.TrailingSlash = TrailingSlashRequired
+		rest = strings.TrimSuffix(rest, "/")
+	}
+
+	if rest == "" {
+		return p, nil
+	}
+
+	names := make(map[string]bool)
+	parts := splitSegments(rest)
+	for i, part := range parts {
+		seg, err := parseSegment(part)
+		if err != nil {
+			return Pattern{}, fmt.Errorf("transport: pattern %q: %w", pattern, err)
+		}
+
+		if seg.Param != "" {
+			if names[seg.Param] {
+				return Pattern{}, fmt.Errorf("transport: pattern %q: duplicate parameter %q", pattern, seg.Param)
+			}
+			names[seg.Param] = true
+		}
+
+		if seg.CatchAll && (i != len(parts)-1 || p.TrailingSlash != TrailingSlashNone) {
+			return Pattern{}, fmt.Errorf("transport: pattern %q: catch-all parameter must be the last segment", pattern)
+		}
+
+		if seg.Constraint != "" {
+			match, err := compileConstraint(seg.Constraint)
+			if err != nil {
+				return Pattern{}, fmt.Errorf("transport: pattern %q: %w", pattern, err)
+			}
+			p.constraints[seg.Param] = match
+		}
+
+		p.Segments = append(p.Segments, seg)
+	}
+
+	return p, nil
+}
+
+// splitSegments разбивает путь на сегменты, не разрывая регулярные выражения в фигурных скобках
+func splitSegments(path string) []string {
+	var (
+		parts []string
+		depth int
+		start int
+	)
+
+	for i := 0; i < len(path); i++ {
+		switch path[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case '/':
+			if depth == 0 {
+				parts = append(parts, path[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(parts, path[start:])
+}
+
+func parseSegment(part string) (Segment, error) {
+	if part == "" {
+		return Segment{}, fmt.Errorf("empty segment")
+	}
+
+	if part == "*" {
+		return Segment{Param: "*", CatchAll: true}, nil
+	}
+
+	if part[0] != '{' {
+		if strings.ContainsAny(part, "{}") {
+			return Segment{}, fmt.Errorf("parameter must span a whole segment: %q", part)
+		}
+
+		return Segment{Literal: part}, nil
+	}
+
+	if closingBrace(part) != len(part)-1 {
+		return Segment{}, fmt.Errorf("parameter must span a whole segment: %q", part)
+	}
+
+	body := part[1 : len(part)-1]
+	name, constraint, hasConstraint := strings.Cut(body, ":")
+	seg := Segment{Param: name, Constraint: constraint}
+
+	if strings.HasSuffix(name, "...") {
+		if hasConstraint {
+			return Segment{}, fmt.Errorf("catch-all parameter %q cannot have a constraint", part)
+		}
+		seg.Param = strings.TrimSuffix(name, "...")
+		seg.CatchAll = true
+	}
+
+	if seg.Param == "" {
+		return Segment{}, fmt.Errorf("parameter name is empty: %q", part)
+	}
+	if hasConstraint && constraint == "" {
+		return Segment{}, fmt.Errorf("constraint is empty: %q", part)
+	}
+
+	return seg, nil
+}
+
+func compileConstraint(constraint string) (func(string) bool, error) {
+	if match, ok := lookupConstraint(constraint); ok {
+		return match, nil
+	}
+
+	re, err := regexp.Compile("^(?:" + constraint + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid constraint %q: %w", constraint, err)
+	}
+
+	return re.MatchString, nil
+}
+
+// String возвращает шаблон в каноническом синтаксисе
+func (p Pattern) String() string {
+	var sb strings.Builder
+
+	for _, seg := range p.Segments {
+		sb.WriteByte('/')
+		switch {
+		case seg.Param == "*":
+			sb.WriteByte('*')
+		case seg.CatchAll:
+			sb.WriteString("{" + seg.Param + "...}")
+		case seg.Param != "" && seg.Constraint != "":
+			sb.WriteString("{" + seg.Param + ":" + seg.Constraint + "}")
+		case seg.Param != "":
+			sb.WriteString("{" + seg.Param + "}")
+		default:
+			sb.WriteString(seg.Literal)
+		}
+	}
+
+	switch p.TrailingSlash {
+	case TrailingSlashRequired:
+		sb.WriteByte('/')
+	case TrailingSlashOptional:
+		sb.WriteString("/?")
+	case TrailingSlashNone:
+		if len(p.Segments) == 0 {
+			sb.WriteByte('/')
+		}
+	}
+
+	return sb.String()
+}
+
+// Params возвращает имена параметров шаблона
+func (p Pattern) Params() []string {
+	var params []string
+	for _, seg := range p.Segments {
+		if seg.Param != "" {
+			params = append(params, seg.Param)
+		}
+	}
+
+	return params
+}
+
+// PatternSyntax описывает синтаксис шаблонов конкретного роутера
+type PatternSyntax struct {
+	// Param форматирует именованный параметр
+	Param func(name string) string
+	// CatchAll форматирует catch-all параметр и возвращает имя, под которым роутер хранит его значение
+	CatchAll func(name string) (segment, nativeName string)
+	// ExactSuffix добавляется к шаблону с завершающим "/", если роутер иначе считает его префиксом
+	ExactSuffix string
+	// Validate проверяет, может ли роутер выразить шаблон
+	Validate func(p Pattern) error
+}
+
+// NativePattern шаблон, переведенный в синтаксис роутера
+type NativePattern struct {
+	// Paths шаблоны для регистрации в роутере, несколько при необязательном завершающем "/"
+	Paths []string
+	// Aliases соответствие канонических имен параметров именам роутера
+	Aliases map[string]string
+}
+
+// Native переводит шаблон в синтаксис роутера
+func (p Pattern) Native(syntax PatternSyntax) (NativePattern, error) {
+	if syntax.Validate != nil {
+		if err := syntax.Validate(p); err != nil {
+			return NativePattern{}, fmt.Errorf("transport: pattern %q: %w", p.String(), err)
+		}
+	}
+
+	var (
+		sb      strings.Builder
+		aliases map[string]string
+	)
+
+	for _, seg := range p.Segments {
+		sb.WriteByte('/')
+		switch {
+		case seg.CatchAll:
+			segment, nativeName := syntax.CatchAll(seg.Param)
+			sb.WriteString(segment)
+			if nativeName != seg.Param {
+				if aliases == nil {
+					aliases = make(map[string]string)
+				}
+				aliases[seg.Param] = nativeName
+			}
+		case seg.Param != "":
+			sb.WriteString(syntax.Param(seg.Param))
+		default:
+			sb.WriteString(seg.Literal)
+		}
+	}
+
+	base := sb.String()
+	withSlash := base + "/" + syntax.ExactSuffix
+	if base == "" {
+		base = "/" + syntax.ExactSuffix
+	}
+
+	native := NativePattern{Aliases: aliases}
+	switch p.TrailingSlash {
+	case TrailingSlashNone:
+		native.Paths = []string{base}
+	case TrailingSlashRequired:
+		native.Paths = []string{withSlash}
+	case TrailingSlashOptional:
+		native.Paths = []string{base, withSlash}
+	}
+
+	return native, nil
+}
+
+// Bind оборачивает handler проверкой ограничений параметров и переименованием параметров роутера.
+// Запрос с нарушенным ограничением обрабатывается notFound, а если он не задан — NotFound.
+func (p Pattern) Bind(handler Handler, aliases map[string]string, notFound Handler) Handler {
+	if len(p.constraints) == 0 && len(aliases) == 0 {
+		return handler
+	}
+	if notFound == nil {
+		notFound = NotFound
+	}
+
+	return func(req Request, resp Response) error {
+		if len(aliases) > 0 {
+			req = &paramRequest{Request: req, aliases: aliases}
+		}
+
+		for name, match := range p.constraints {
+			if !match(req.PathValue(name)) {
+				return notFound(req, resp)
+			}
+		}
+
+		return handler(req, resp)
+	}
+}
+
+// MustNativePattern разбирает шаблон и переводит его в синтаксис роутера, вызывая панику при ошибке.
+// Используется транспортами в AddRoute по аналогии с регистрацией некорректных маршрутов в роутерах.
+func MustNativePattern(path string, syntax PatternSyntax) (Pattern, NativePattern) {
+	p, err := ParsePattern(path)
+	if err != nil {
+		panic(err)
+	}
+
+	native, err := p.Native(syntax)
+	if err != nil {
+		panic(err)
+	}
+
+	return p, native
+}
+
+// paramRequest запрос с переименованными параметрами пути
+type paramRequest struct {
+	Request
+	aliases map[string]string
+}
+
+func (r *paramRequest) PathValue(name string) string {
+	if native, ok := r.aliases[name]; ok {
+		return r.Request.PathValue(native)
+	}
+
+	return r.Request.PathValue(name)
+}
+
+func (r *paramRequest) ReadData(data any) error {
+	return ReadDataOf(r.Request)(r, data)
+}
+
+func (r *paramRequest) ReadDataFunc() ReadData {
+	return ReadDataOf(r.Request)
+}
+
+func (r *paramRequest) WithContext(ctx context.Context) Request {
+	return &paramRequest{Request: r.Request.WithContext(ctx), aliases: r.aliases}
+}
+
+func (r *paramRequest) Detach() Request {
+	return &paramRequest{Request: Detach(r.Request), aliases: r.aliases}
+}
+
+func (r *paramRequest) Unwrap() Request {
+	return r.Request
+}
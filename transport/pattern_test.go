@@ -0,0 +1,132 @@
+package transport_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-mosaic/runtime/transport"
+	"github.com/go-mosaic/runtime/transport/internal/transporttest"
+)
+
+func TestParsePattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		want    string
+		wantErr bool
+	}{
+		{name: "root", pattern: "/", want: "/"},
+		{name: "params", pattern: "/users/{id}/posts/{post:int}", want: "/users/{id}/posts/{post:int}"},
+		{name: "colon params", pattern: "/users/:id", want: "/users/{id}"},
+		{name: "regex with braces", pattern: "/codes/{code:[A-Z]{3}}", want: "/codes/{code:[A-Z]{3}}"},
+		{name: "catch-all", pattern: "/files/{path...}", want: "/files/{path...}"},
+		{name: "wildcard", pattern: "/static/*", want: "/static/*"},
+		{name: "trailing slash", pattern: "/users/", want: "/users/"},
+		{name: "optional trailing slash", pattern: "/users/?", want: "/users/?"},
+		{name: "no leading slash", pattern: "users", wantErr: true},
+		{name: "partial segment", pattern: "/files/{name}.json", wantErr: true},
+		{name: "catch-all not last", pattern: "/files/{path...}/raw", wantErr: true},
+		{name: "duplicate param", pattern: "/a/{id}/b/{id}", wantErr: true},
+		{name: "empty segment", pattern: "/a//b", wantErr: true},
+		{name: "empty name", pattern: "/a/{}", wantErr: true},
+		{name: "invalid regex", pattern: "/a/{id:[}", wantErr: true},
+		{name: "constrained catch-all", pattern: "/a/{path...:int}", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := transport.ParsePattern(tt.pattern)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParsePattern() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got.String() != tt.want {
+				t.Errorf("ParsePattern() = %v, want %v", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestPatternAdapters(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "constraint match", path: "/users/42", wantStatus: http.StatusOK, wantBody: "user 42"},
+		{name: "constraint mismatch", path: "/users/abc", wantStatus: http.StatusNotFound},
+		{name: "uuid", path: "/orders/3f2c1d4e-5a6b-4c7d-8e9f-0a1b2c3d4e5f", wantStatus: http.StatusOK,
+			wantBody: "order 3f2c1d4e-5a6b-4c7d-8e9f-0a1b2c3d4e5f"},
+		{name: "uuid mismatch", path: "/orders/42", wantStatus: http.StatusNotFound},
+		{name: "catch-all", path: "/files/a/b/c.txt", wantStatus: http.StatusOK, wantBody: "file a/b/c.txt"},
+		{name: "optional slash without", path: "/items", wantStatus: http.StatusOK, wantBody: "items"},
+		{name: "optional slash with", path: "/items/", wantStatus: http.StatusOK, wantBody: "items"},
+	}
+
+	for _, adapter := range transporttest.Adapters() {
+		srv := adapter.New()
+		srv.Transport.AddRoute(http.MethodGet, "/users/{id:int}", func(req transport.Request, resp transport.Response) error {
+			resp.SetBody([]byte("user "+req.PathValue("id")), http.StatusOK)
+			return nil
+		})
+		srv.Transport.AddRoute(http.MethodGet, "/orders/{id:uuid}", func(req transport.Request, resp transport.Response) error {
+			resp.SetBody([]byte("order "+req.PathValue("id")), http.StatusOK)
+			return nil
+		})
+		srv.Transport.AddRoute(http.MethodGet, "/files/{path...}", func(req transport.Request, resp transport.Response) error {
+			resp.SetBody([]byte("file "+req.PathValue("path")), http.StatusOK)
+			return nil
+		})
+		srv.Transport.AddRoute(http.MethodGet, "/items/?", func(req transport.Request, resp transport.Response) error {
+			resp.SetBody([]byte("items"), http.StatusOK)
+			return nil
+		})
+
+		for _, tt := range tests {
+			t.Run(adapter.Name+"/"+tt.name, func(t *testing.T) {
+				resp := srv.Do(t, httptest.NewRequest(http.MethodGet, tt.path, nil))
+				defer resp.Body.Close()
+
+				if resp.StatusCode != tt.wantStatus {
+					t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+				}
+				if tt.wantBody != "" {
+					body, _ := io.ReadAll(resp.Body)
+					if string(body) != tt.wantBody {
+						t.Errorf("body = %q, want %q", body, tt.wantBody)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestPatternUnsupported(t *testing.T) {
+	unsupported := map[string]string{
+		"http":  "/users/{user-id}",
+		"fiber": "/users/{user.id}",
+	}
+	noop := func(req transport.Request, resp transport.Response) error { return nil }
+
+	for _, adapter := range transporttest.Adapters() {
+		pattern, ok := unsupported[adapter.Name]
+		if !ok {
+			continue
+		}
+		t.Run(adapter.Name, func(t *testing.T) {
+			defer func() {
+				r := recover()
+				if r == nil {
+					t.Fatalf("AddRoute(%q) did not panic", pattern)
+				}
+				if err, ok := r.(error); !ok || !strings.Contains(err.Error(), pattern) {
+					t.Errorf("panic = %v, want error mentioning %q", r, pattern)
+				}
+			}()
+			adapter.New().Transport.AddRoute(http.MethodGet, pattern, noop)
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package transport
+
+import (
+	"context"
+	"net"
+)
+
+type principalKey struct{}
+
+// ContextWithPrincipal сохраняет идентификатор аутентифицированного клиента в контексте
+func ContextWithPrincipal(ctx context.Context, principal string) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext возвращает идентификатор клиента, сохраненный ContextWithPrincipal
+func PrincipalFromContext(ctx context.Context) (string, bool) {
+	principal, ok := ctx.Value(principalKey{}).(string)
+	return principal, ok && principal != ""
+}
+
+// RemoteAddrProvider интерфейс для запросов, предоставляющих сетевой адрес клиента.
+// Реализуется запросами всех адаптеров пакета; запросы сторонних реализаций Request могут его не реализовывать.
+type RemoteAddrProvider interface {
+	RemoteAddr() string
+}
+
+// RemoteAddrOf возвращает сетевой адрес клиента, проверяя запрос и его обёртки,
+// или пустую строку, если ни один из них его не предоставляет
+func RemoteAddrOf(req Request) string {
+	for {
+		if p, ok := req.(RemoteAddrProvider); ok {
+			return p.RemoteAddr()
+		}
+
+		u, ok := req.(RequestUnwrapper)
+		if !ok {
+			return ""
+		}
+		req = u.Unwrap()
+	}
+}
+
+// RemoteIP возвращает IP адрес клиента без порта
+func RemoteIP(req Request) string {
+	addr := RemoteAddrOf(req)
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+
+	return addr
+}
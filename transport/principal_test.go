@@ -0,0 +1,41 @@
+package transport_test
+
+import (
+	"testing"
+
+	"github.com/go-mosaic/runtime/transport"
+)
+
+// minimalRequest сторонняя реализация Request без RemoteAddr
+type minimalRequest struct {
+	transport.Request
+}
+
+type addrRequest struct {
+	transport.Request
+	addr string
+}
+
+func (r *addrRequest) RemoteAddr() string {
+	return r.addr
+}
+
+func TestRemoteIP(t *testing.T) {
+	tests := []struct {
+		name string
+		req  transport.Request
+		want string
+	}{
+		{name: "host and port", req: &addrRequest{addr: "10.0.0.1:1234"}, want: "10.0.0.1"},
+		{name: "host only", req: &addrRequest{addr: "10.0.0.1"}, want: "10.0.0.1"},
+		{name: "wrapped", req: transport.WithBody(&addrRequest{addr: "[::1]:80"}, nil), want: "::1"},
+		{name: "not provided", req: &minimalRequest{}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := transport.RemoteIP(tt.req); got != tt.want {
+				t.Errorf("RemoteIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
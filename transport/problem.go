@@ -0,0 +1,52 @@
+package transport
+
+import (
+	"net/http"
+)
+
+// Problem описание ошибки в формате problem details (RFC 9457)
+type Problem struct {
+	Type     string `json:"type,omitempty" xml:"type,omitempty"`
+	Title    string `json:"title,omitempty" xml:"title,omitempty"`
+	Status   int    `json:"status,omitempty" xml:"status,omitempty"`
+	Detail   string `json:"detail,omitempty" xml:"detail,omitempty"`
+	Instance string `json:"instance,omitempty" xml:"instance,omitempty"`
+
+	headers http.Header
+}
+
+// NewProblem создает Problem с указанным статус кодом и описанием
+func NewProblem(status int, detail string) *Problem {
+	return &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+// WithHeader добавляет заголовок, который будет записан вместе с ответом
+func (p *Problem) WithHeader(key, value string) *Problem {
+	if p.headers == nil {
+		p.headers = make(http.Header)
+	}
+	p.headers.Set(key, value)
+
+	return p
+}
+
+func (p *Problem) Error() string {
+	if p.Detail != "" {
+		return p.Detail
+	}
+
+	return p.Title
+}
+
+func (p *Problem) StatusCode() int {
+	return p.Status
+}
+
+func (p *Problem) Headers() http.Header {
+	return p.headers
+}
@@ -0,0 +1,188 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Limit описывает квоту: Requests запросов за Period
+type Limit struct {
+	Requests int
+	Period   time.Duration
+	// Burst максимальный размер корзины для token bucket, по умолчанию равен Requests
+	Burst int
+}
+
+// validate проверяет, что квота задает положительное число запросов за положительный период
+func (l Limit) validate() error {
+	if l.Requests <= 0 {
+		return fmt.Errorf("ratelimit: Requests must be positive, got %d", l.Requests)
+	}
+	if l.Period <= 0 {
+		return fmt.Errorf("ratelimit: Period must be positive, got %s", l.Period)
+	}
+
+	return nil
+}
+
+// PerSecond создает лимит на n запросов в секунду
+func PerSecond(n int) Limit {
+	return Limit{Requests: n, Period: time.Second}
+}
+
+// PerMinute создает лимит на n запросов в минуту
+func PerMinute(n int) Limit {
+	return Limit{Requests: n, Period: time.Minute}
+}
+
+// Result результат проверки лимита
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+	Window     time.Duration
+}
+
+// Limiter алгоритм ограничения частоты запросов
+type Limiter interface {
+	Allow(ctx context.Context, key string) (Result, error)
+}
+
+// LimiterOption функциональная опция ограничителя
+type LimiterOption func(*limiterConfig)
+
+type limiterConfig struct {
+	now func() time.Time
+}
+
+// WithClock задает источник текущего времени
+func WithClock(now func() time.Time) LimiterOption {
+	return func(c *limiterConfig) {
+		c.now = now
+	}
+}
+
+func newLimiterConfig(opts []LimiterOption) limiterConfig {
+	config := limiterConfig{now: time.Now}
+	for _, applyOpt := range opts {
+		applyOpt(&config)
+	}
+
+	return config
+}
+
+// TokenBucket ограничитель на основе алгоритма token bucket
+type TokenBucket struct {
+	limit Limit
+	store Store
+	now   func() time.Time
+}
+
+// NewTokenBucket создает ограничитель token bucket поверх store.
+// Паникует, если Requests или Period не положительны.
+func NewTokenBucket(limit Limit, store Store, opts ...LimiterOption) *TokenBucket {
+	if err := limit.validate(); err != nil {
+		panic(err)
+	}
+	if limit.Burst <= 0 {
+		limit.Burst = limit.Requests
+	}
+
+	return &TokenBucket{limit: limit, store: store, now: newLimiterConfig(opts).now}
+}
+
+func (b *TokenBucket) Allow(ctx context.Context, key string) (Result, error) {
+	now := b.now()
+	capacity := float64(b.limit.Burst)
+	rate := float64(b.limit.Requests) / b.limit.Period.Seconds()
+	ttl := time.Duration(capacity / rate * float64(time.Second))
+
+	result := Result{Limit: b.limit.Burst, Window: b.limit.Period}
+
+	err := b.store.Update(ctx, key, ttl, func(state *State) {
+		if state.Timestamp.IsZero() {
+			state.Value = capacity
+		} else if elapsed := now.Sub(state.Timestamp).Seconds(); elapsed > 0 {
+			state.Value = math.Min(capacity, state.Value+elapsed*rate)
+		}
+		state.Timestamp = now
+
+		if state.Value >= 1 {
+			state.Value--
+			result.Allowed = true
+		} else {
+			result.RetryAfter = secondsToDuration((1 - state.Value) / rate)
+		}
+
+		result.Remaining = int(state.Value)
+		result.Reset = secondsToDuration((capacity - state.Value) / rate)
+	})
+
+	return result, err
+}
+
+// SlidingWindow ограничитель на основе скользящего окна со счетчиками
+type SlidingWindow struct {
+	limit Limit
+	store Store
+	now   func() time.Time
+}
+
+// NewSlidingWindow создает ограничитель sliding window поверх store.
+// Паникует, если Requests или Period не положительны.
+func NewSlidingWindow(limit Limit, store Store, opts ...LimiterOption) *SlidingWindow {
+	if err := limit.validate(); err != nil {
+		panic(err)
+	}
+
+	return &SlidingWindow{limit: limit, store: store, now: newLimiterConfig(opts).now}
+}
+
+func (w *SlidingWindow) Allow(ctx context.Context, key string) (Result, error) {
+	now := w.now()
+	period := w.limit.Period
+	windowStart := now.Truncate(period)
+	limit := float64(w.limit.Requests)
+
+	result := Result{Limit: w.limit.Requests, Window: period}
+
+	err := w.store.Update(ctx, key, 2*period, func(state *State) { //nolint:mnd
+		if !state.Timestamp.Equal(windowStart) {
+			if state.Timestamp.Equal(windowStart.Add(-period)) {
+				state.Previous = state.Value
+			} else {
+				state.Previous = 0
+			}
+			state.Value = 0
+			state.Timestamp = windowStart
+		}
+
+		elapsed := now.Sub(windowStart)
+		weight := 1 - float64(elapsed)/float64(period)
+		estimated := state.Previous*weight + state.Value
+		result.Reset = period - elapsed
+
+		if estimated+1 <= limit {
+			state.Value++
+			result.Allowed = true
+			result.Remaining = int(limit - estimated - 1)
+			return
+		}
+
+		result.RetryAfter = result.Reset
+		if state.Previous > 0 {
+			wait := time.Duration((estimated + 1 - limit) / state.Previous * float64(period))
+			result.RetryAfter = min(wait, result.Reset)
+		}
+	})
+
+	return result, err
+}
+
+func secondsToDuration(s float64) time.Duration {
+	return time.Duration(math.Ceil(s * float64(time.Second)))
+}
@@ -0,0 +1,171 @@
+package ratelimit
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-mosaic/runtime/log"
+	"github.com/go-mosaic/runtime/transport"
+)
+
+// KeyFunc возвращает ключ клиента, по которому считается лимит.
+// Пустой ключ означает, что запрос не ограничивается.
+type KeyFunc func(req transport.Request) string
+
+// KeyByIP ключ по IP адресу клиента. Если адрес не содержит хоста, например ":1234", ключом служит адрес целиком;
+// запросы без адреса не ограничиваются, чтобы такие клиенты не делили общий лимит.
+func KeyByIP() KeyFunc {
+	return func(req transport.Request) string {
+		if ip := transport.RemoteIP(req); ip != "" {
+			return "ip:" + ip
+		}
+		if addr := transport.RemoteAddrOf(req); addr != "" {
+			return "addr:" + addr
+		}
+
+		return ""
+	}
+}
+
+// KeyByHeader ключ по значению заголовка
+func KeyByHeader(name string) KeyFunc {
+	return func(req transport.Request) string {
+		if v := req.Header(name); v != "" {
+			return "header:" + v
+		}
+
+		return ""
+	}
+}
+
+// KeyByPrincipal ключ по идентификатору клиента, сохраненному transport.ContextWithPrincipal
+func KeyByPrincipal() KeyFunc {
+	return func(req transport.Request) string {
+		if principal, ok := transport.PrincipalFromContext(req.Context()); ok {
+			return "principal:" + principal
+		}
+
+		return ""
+	}
+}
+
+// Config конфигурация middleware
+type Config struct {
+	keyFunc   KeyFunc
+	collector log.MetricsCollector
+	operation string
+	failOpen  bool
+	headers   bool
+}
+
+// Option тип для функциональных опций
+type Option func(*Config)
+
+// WithKeyFunc задает функцию получения ключа клиента, по умолчанию KeyByIP
+func WithKeyFunc(keyFunc KeyFunc) Option {
+	return func(c *Config) {
+		c.keyFunc = keyFunc
+	}
+}
+
+// WithMetricsCollector задает сборщик метрик для учета проверок и отказов
+func WithMetricsCollector(collector log.MetricsCollector) Option {
+	return func(c *Config) {
+		c.collector = collector
+	}
+}
+
+// WithOperation задает имя операции в метриках
+func WithOperation(operation string) Option {
+	return func(c *Config) {
+		c.operation = operation
+	}
+}
+
+// WithFailOpen пропускает запросы, если хранилище лимитов недоступно
+func WithFailOpen(enabled bool) Option {
+	return func(c *Config) {
+		c.failOpen = enabled
+	}
+}
+
+// WithHeaders включает/выключает заголовки RateLimit-*
+func WithHeaders(enabled bool) Option {
+	return func(c *Config) {
+		c.headers = enabled
+	}
+}
+
+// Middleware создает transport.Middleware ограничивающий частоту запросов
+func Middleware(limiter Limiter, opts ...Option) transport.Middleware {
+	config := Config{
+		keyFunc:   KeyByIP(),
+		operation: "ratelimit",
+		headers:   true,
+	}
+
+	for _, applyOpt := range opts {
+		applyOpt(&config)
+	}
+
+	return func(next transport.Handler) transport.Handler {
+		return func(req transport.Request, resp transport.Response) error {
+			key := config.keyFunc(req)
+			if key == "" {
+				return next(req, resp)
+			}
+
+			if config.collector != nil {
+				config.collector.RecordCall(config.operation)
+			}
+
+			start := time.Now()
+			result, err := limiter.Allow(req.Context(), key)
+			if err != nil {
+				if config.collector != nil {
+					config.collector.RecordError(config.operation, time.Since(start))
+				}
+				if config.failOpen {
+					return next(req, resp)
+				}
+
+				return err
+			}
+
+			if config.headers {
+				setRateLimitHeaders(resp, result)
+			}
+
+			if !result.Allowed {
+				if config.collector != nil {
+					config.collector.RecordError(config.operation, time.Since(start))
+				}
+
+				problem := transport.NewProblem(http.StatusTooManyRequests, "rate limit exceeded").
+					WithHeader("Retry-After", formatSeconds(result.RetryAfter))
+				resp.WriteData(req, problem)
+
+				return nil
+			}
+
+			if config.collector != nil {
+				config.collector.RecordSuccess(config.operation, time.Since(start))
+			}
+
+			return next(req, resp)
+		}
+	}
+}
+
+func setRateLimitHeaders(resp transport.Response, result Result) {
+	resp.SetHeader("RateLimit-Limit", strconv.Itoa(result.Limit))
+	resp.SetHeader("RateLimit-Remaining", strconv.Itoa(max(result.Remaining, 0)))
+	resp.SetHeader("RateLimit-Reset", formatSeconds(result.Reset))
+	resp.SetHeader("RateLimit-Policy", strconv.Itoa(result.Limit)+";w="+formatSeconds(result.Window))
+}
+
+func formatSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,218 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/go-mosaic/runtime/transport"
+	transportchi "github.com/go-mosaic/runtime/transport/chi"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func TestTokenBucket(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	limiter := NewTokenBucket(Limit{Requests: 1, Period: time.Second, Burst: 2}, NewMemoryStore(), WithClock(clock.Now))
+
+	tests := []struct {
+		name        string
+		advance     time.Duration
+		wantAllowed bool
+	}{
+		{name: "first request", wantAllowed: true},
+		{name: "burst request", wantAllowed: true},
+		{name: "bucket empty", wantAllowed: false},
+		{name: "half refilled", advance: 500 * time.Millisecond, wantAllowed: false},
+		{name: "refilled", advance: 500 * time.Millisecond, wantAllowed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock.Advance(tt.advance)
+			got, err := limiter.Allow(context.Background(), "key")
+			if err != nil {
+				t.Fatalf("Allow() error = %v", err)
+			}
+			if got.Allowed != tt.wantAllowed {
+				t.Errorf("Allow() allowed = %v, want %v", got.Allowed, tt.wantAllowed)
+			}
+			if !got.Allowed && got.RetryAfter <= 0 {
+				t.Errorf("Allow() retryAfter = %v, want > 0", got.RetryAfter)
+			}
+		})
+	}
+}
+
+func TestSlidingWindow(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1699999980, 0)}
+	limiter := NewSlidingWindow(Limit{Requests: 2, Period: time.Minute}, NewMemoryStore(), WithClock(clock.Now))
+
+	tests := []struct {
+		name          string
+		advance       time.Duration
+		wantAllowed   bool
+		wantRemaining int
+	}{
+		{name: "first request", wantAllowed: true, wantRemaining: 1},
+		{name: "second request", wantAllowed: true, wantRemaining: 0},
+		{name: "limit exceeded", wantAllowed: false},
+		{name: "previous window still weighs", advance: time.Minute + 15*time.Second, wantAllowed: false},
+		{name: "previous window decayed", advance: 30 * time.Second, wantAllowed: true, wantRemaining: 0},
+		{name: "two windows later", advance: 2 * time.Minute, wantAllowed: true, wantRemaining: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock.Advance(tt.advance)
+			got, err := limiter.Allow(context.Background(), "key")
+			if err != nil {
+				t.Fatalf("Allow() error = %v", err)
+			}
+			if got.Allowed != tt.wantAllowed {
+				t.Errorf("Allow() allowed = %v, want %v", got.Allowed, tt.wantAllowed)
+			}
+			if got.Allowed && got.Remaining != tt.wantRemaining {
+				t.Errorf("Allow() remaining = %v, want %v", got.Remaining, tt.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestInvalidLimit(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit Limit
+	}{
+		{name: "zero period", limit: Limit{Requests: 1}},
+		{name: "negative period", limit: Limit{Requests: 1, Period: -time.Second}},
+		{name: "zero requests", limit: Limit{Period: time.Second}},
+	}
+	constructors := map[string]func(Limit){
+		"token bucket":   func(l Limit) { NewTokenBucket(l, NewMemoryStore()) },
+		"sliding window": func(l Limit) { NewSlidingWindow(l, NewMemoryStore()) },
+	}
+	for _, tt := range tests {
+		for name, newLimiter := range constructors {
+			t.Run(name+"/"+tt.name, func(t *testing.T) {
+				defer func() {
+					if recover() == nil {
+						t.Errorf("%s(%+v) did not panic", name, tt.limit)
+					}
+				}()
+				newLimiter(tt.limit)
+			})
+		}
+	}
+}
+
+func TestMemoryStoreExpires(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	store := NewMemoryStore(WithShards(1), WithStoreClock(clock.Now))
+
+	_ = store.Update(context.Background(), "a", time.Second, func(state *State) { state.Value = 5 })
+	clock.Advance(2 * time.Second)
+
+	var got float64
+	_ = store.Update(context.Background(), "a", time.Second, func(state *State) { got = state.Value })
+	if got != 0 {
+		t.Errorf("Update() expired state value = %v, want 0", got)
+	}
+}
+
+type countingCollector struct {
+	calls, successes, errors int
+}
+
+func (c *countingCollector) RecordSuccess(string, time.Duration) { c.successes++ }
+func (c *countingCollector) RecordError(string, time.Duration)   { c.errors++ }
+func (c *countingCollector) RecordCall(string)                   { c.calls++ }
+
+func TestMiddleware(t *testing.T) {
+	collector := &countingCollector{}
+	router := chi.NewRouter()
+	tr := transportchi.NewChiTransport(router)
+	tr.AddRoute(http.MethodGet, "/", func(req transport.Request, resp transport.Response) error {
+		resp.WriteHeader(http.StatusOK)
+		return nil
+	}, Middleware(
+		NewSlidingWindow(PerMinute(1), NewMemoryStore()),
+		WithKeyFunc(KeyByHeader("X-Api-Key")),
+		WithMetricsCollector(collector),
+	))
+
+	tests := []struct {
+		name       string
+		apiKey     string
+		wantStatus int
+	}{
+		{name: "first request", apiKey: "a", wantStatus: http.StatusOK},
+		{name: "limited", apiKey: "a", wantStatus: http.StatusTooManyRequests},
+		{name: "other client", apiKey: "b", wantStatus: http.StatusOK},
+		{name: "no key", wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.apiKey != "" {
+				r.Header.Set("X-Api-Key", tt.apiKey)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %v, want %v", w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusTooManyRequests && w.Header().Get("Retry-After") == "" {
+				t.Errorf("Retry-After header is missing")
+			}
+			if tt.apiKey != "" && w.Header().Get("RateLimit-Limit") != "1" {
+				t.Errorf("RateLimit-Limit = %q, want 1", w.Header().Get("RateLimit-Limit"))
+			}
+		})
+	}
+
+	if collector.calls != 3 || collector.errors != 1 || collector.successes != 2 {
+		t.Errorf("collector = %+v, want 3 calls, 2 successes, 1 error", *collector)
+	}
+}
+
+type addrRequest struct {
+	transport.Request
+	addr string
+}
+
+func (r *addrRequest) RemoteAddr() string {
+	return r.addr
+}
+
+func TestKeyByIP(t *testing.T) {
+	tests := []struct {
+		name string
+		addr string
+		want string
+	}{
+		{name: "host and port", addr: "10.0.0.1:1234", want: "ip:10.0.0.1"},
+		{name: "port only", addr: ":1234", want: "addr::1234"},
+		{name: "not provided"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := KeyByIP()(&addrRequest{addr: tt.addr}); got != tt.want {
+				t.Errorf("KeyByIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
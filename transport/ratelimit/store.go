@@ -0,0 +1,134 @@
+package ratelimit
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+// State состояние лимита для одного ключа.
+// Token bucket хранит в Value количество токенов, а в Timestamp время последнего пополнения.
+// Sliding window хранит в Value и Previous счетчики текущего и предыдущего окна, а в Timestamp начало текущего окна.
+type State struct {
+	Value     float64
+	Previous  float64
+	Timestamp time.Time
+}
+
+// Store хранилище состояний лимитов
+type Store interface {
+	// Update атомарно применяет fn к состоянию ключа и сохраняет результат на время ttl.
+	// Для отсутствующего ключа fn получает нулевое состояние.
+	Update(ctx context.Context, key string, ttl time.Duration, fn func(state *State)) error
+}
+
+const (
+	defaultShards = 64
+	sweepEvery    = 1024
+)
+
+type memoryEntry struct {
+	state   State
+	expires time.Time
+}
+
+type memoryShard struct {
+	mu      sync.Mutex
+	items   map[string]*memoryEntry
+	updates int
+}
+
+// MemoryStore потокобезопасное хранилище в памяти, разбитое на шарды
+type MemoryStore struct {
+	shards []*memoryShard
+	now    func() time.Time
+}
+
+// MemoryStoreOption функциональная опция MemoryStore
+type MemoryStoreOption func(*MemoryStore)
+
+// WithShards задает количество шардов
+func WithShards(n int) MemoryStoreOption {
+	return func(s *MemoryStore) {
+		if n > 0 {
+			s.shards = make([]*memoryShard, n)
+		}
+	}
+}
+
+// WithStoreClock задает источник текущего времени
+func WithStoreClock(now func() time.Time) MemoryStoreOption {
+	return func(s *MemoryStore) {
+		s.now = now
+	}
+}
+
+// NewMemoryStore создает новый экземпляр MemoryStore
+func NewMemoryStore(opts ...MemoryStoreOption) *MemoryStore {
+	s := &MemoryStore{
+		shards: make([]*memoryShard, defaultShards),
+		now:    time.Now,
+	}
+
+	for _, applyOpt := range opts {
+		applyOpt(s)
+	}
+
+	for i := range s.shards {
+		s.shards[i] = &memoryShard{items: make(map[string]*memoryEntry)}
+	}
+
+	return s
+}
+
+func (s *MemoryStore) Update(_ context.Context, key string, ttl time.Duration, fn func(state *State)) error {
+	shard := s.shard(key)
+	now := s.now()
+
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	shard.updates++
+	if shard.updates%sweepEvery == 0 {
+		shard.sweep(now)
+	}
+
+	entry, ok := shard.items[key]
+	if !ok || now.After(entry.expires) {
+		entry = &memoryEntry{}
+		shard.items[key] = entry
+	}
+
+	fn(&entry.state)
+	entry.expires = now.Add(ttl)
+
+	return nil
+}
+
+// Len возвращает количество ключей в хранилище
+func (s *MemoryStore) Len() int {
+	n := 0
+	for _, shard := range s.shards {
+		shard.mu.Lock()
+		n += len(shard.items)
+		shard.mu.Unlock()
+	}
+
+	return n
+}
+
+func (s *MemoryStore) shard(key string) *memoryShard {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+
+	return s.shards[h.Sum32()%uint32(len(s.shards))]
+}
+
+func (sh *memoryShard) sweep(now time.Time) {
+	for key, entry := range sh.items {
+		if now.After(entry.expires) {
+			delete(sh.items, key)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"io"
+)
+
+// ReadDataProvider интерфейс для запросов, предоставляющих функцию чтения данных
+type ReadDataProvider interface {
+	ReadDataFunc() ReadData
+}
+
+// ReadDataOf возвращает функцию чтения данных, используемую запросом,
+// или DefaultReadData, если запрос ее не предоставляет
+func ReadDataOf(req Request) ReadData {
+	if p, ok := req.(ReadDataProvider); ok {
+		if readData := p.ReadDataFunc(); readData != nil {
+			return readData
+		}
+	}
+
+	return DefaultReadData
+}
+
+// PatternProvider интерфейс для запросов, предоставляющих шаблон маршрута.
+// Реализуется запросами всех адаптеров пакета; запросы сторонних реализаций Request могут его не реализовывать.
+type PatternProvider interface {
+	// Pattern возвращает шаблон маршрута, которому соответствует запрос
+	Pattern() string
+}
+
+// PatternOf возвращает шаблон маршрута, проверяя запрос и его обёртки,
+// или пустую строку, если ни один из них его не предоставляет
+func PatternOf(req Request) string {
+	for {
+		if p, ok := req.(PatternProvider); ok {
+			return p.Pattern()
+		}
+
+		u, ok := req.(RequestUnwrapper)
+		if !ok {
+			return ""
+		}
+		req = u.Unwrap()
+	}
+}
+
+// RequestUnwrapper интерфейс для обёрток над запросом адаптера
+type RequestUnwrapper interface {
+	Unwrap() Request
+}
+
+// UnwrapRequest возвращает исходный запрос адаптера, снимая все обёртки
+func UnwrapRequest(req Request) Request {
+	for {
+		u, ok := req.(RequestUnwrapper)
+		if !ok {
+			return req
+		}
+		req = u.Unwrap()
+	}
+}
+
+// Detacher интерфейс для запросов адаптеров, переиспользующих объекты запроса после возврата обработчика
+type Detacher interface {
+	// Detach возвращает копию запроса, которую можно использовать после возврата обработчика адаптера
+	Detach() Request
+}
+
+// Detach возвращает запрос, который можно передать горутине, продолжающей работу после возврата обработчика.
+// Запрос, не реализующий Detacher, возвращается без изменений: его адаптер не переиспользует объекты запроса.
+func Detach(req Request) Request {
+	if d, ok := req.(Detacher); ok {
+		return d.Detach()
+	}
+
+	return req
+}
+
+// bodyRequest запрос с телом, прочитанным в память
+type bodyRequest struct {
+	Request
+	body []byte
+}
+
+// WithBody возвращает запрос, тело которого заменено на body.
+// Body и ReadData могут вызываться многократно; разбор форм по-прежнему использует исходный запрос.
+func WithBody(req Request, body []byte) Request {
+	return &bodyRequest{Request: req, body: body}
+}
+
+func (r *bodyRequest) Body() io.ReadCloser {
+	return io.NopCloser(bytes.NewReader(r.body))
+}
+
+func (r *bodyRequest) ReadData(data any) error {
+	return ReadDataOf(r.Request)(r, data)
+}
+
+func (r *bodyRequest) WithContext(ctx context.Context) Request {
+	return &bodyRequest{Request: r.Request.WithContext(ctx), body: r.body}
+}
+
+func (r *bodyRequest) ReadDataFunc() ReadData {
+	return ReadDataOf(r.Request)
+}
+
+func (r *bodyRequest) Detach() Request {
+	return &bodyRequest{Request: Detach(r.Request), body: r.body}
+}
+
+func (r *bodyRequest) Unwrap() Request {
+	return r.Request
+}
+
+// readDataRequest запрос с переопределенной функцией чтения данных
+type readDataRequest struct {
+	Request
+	readData ReadData
+}
+
+func (r *readDataRequest) ReadData(data any) error {
+	return r.readData(r, data)
+}
+
+func (r *readDataRequest) ReadDataFunc() ReadData {
+	return r.readData
+}
+
+func (r *readDataRequest) WithContext(ctx context.Context) Request {
+	return &readDataRequest{Request: r.Request.WithContext(ctx), readData: r.readData}
+}
+
+func (r *readDataRequest) Detach() Request {
+	return &readDataRequest{Request: Detach(r.Request), readData: r.readData}
+}
+
+func (r *readDataRequest) Unwrap() Request {
+	return r.Request
+}
@@ -0,0 +1,35 @@
+package transport_test
+
+import (
+	"testing"
+
+	"github.com/go-mosaic/runtime/transport"
+)
+
+type patternRequest struct {
+	transport.Request
+	pattern string
+}
+
+func (r *patternRequest) Pattern() string {
+	return r.pattern
+}
+
+func TestPatternOf(t *testing.T) {
+	tests := []struct {
+		name string
+		req  transport.Request
+		want string
+	}{
+		{name: "provided", req: &patternRequest{pattern: "/users/{id}"}, want: "/users/{id}"},
+		{name: "wrapped", req: transport.WithBody(&patternRequest{pattern: "/users/{id}"}, nil), want: "/users/{id}"},
+		{name: "not provided", req: &minimalRequest{}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := transport.PatternOf(tt.req); got != tt.want {
+				t.Errorf("PatternOf() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ResponseUnwrapper интерфейс для обёрток над ответом адаптера
+type ResponseUnwrapper interface {
+	Unwrap() Response
+}
+
+// UnwrapResponse возвращает исходный ответ адаптера, снимая все обёртки
+func UnwrapResponse(resp Response) Response {
+	for {
+		u, ok := resp.(ResponseUnwrapper)
+		if !ok {
+			return resp
+		}
+		resp = u.Unwrap()
+	}
+}
+
+// HeaderAdder интерфейс для ответов, добавляющих значение заголовка к уже установленным,
+// например несколько Set-Cookie
+type HeaderAdder interface {
+	AddHeader(key, value string)
+}
+
+// AddHeader добавляет значение заголовка key. Если ответ не реализует HeaderAdder, значение заменяет предыдущие.
+func AddHeader(resp Response, key, value string) {
+	if a, ok := resp.(HeaderAdder); ok {
+		a.AddHeader(key, value)
+		return
+	}
+
+	resp.SetHeader(key, value)
+}
+
+// Abandoner интерфейс для ответов адаптеров, переиспользующих объекты запроса после возврата обработчика
+type Abandoner interface {
+	// Abandon отправляет уже записанный ответ и запрещает адаптеру переиспользовать объекты запроса,
+	// на которые ссылается горутина, продолжающая работу после возврата обработчика.
+	// Изменения ответа после вызова Abandon игнорируются.
+	Abandon()
+}
+
+// Abandon вызывает Abandon исходного ответа адаптера, если он реализует Abandoner
+func Abandon(resp Response) {
+	if a, ok := UnwrapResponse(resp).(Abandoner); ok {
+		a.Abandon()
+	}
+}
+
+// writeDataResponse ответ с переопределенной функцией записи данных
+type writeDataResponse struct {
+	Response
+	writeResponse WriteResponse
+}
+
+func (r *writeDataResponse) WriteData(req Request, data any) {
+	r.writeResponse(req, r, data)
+}
+
+func (r *writeDataResponse) AddHeader(key, value string) {
+	AddHeader(r.Response, key, value)
+}
+
+func (r *writeDataResponse) WriteResponseFunc() WriteResponse {
+	return r.writeResponse
+}
+
+func (r *writeDataResponse) Unwrap() Response {
+	return r.Response
+}
+
+// ResponseRecorder обёртка над ответом, запоминающая статус код и размер тела
+type ResponseRecorder struct {
+	Response
+	statusCode int
+	size       int
+}
+
+// NewResponseRecorder создает ResponseRecorder поверх resp
+func NewResponseRecorder(resp Response) *ResponseRecorder {
+	return &ResponseRecorder{Response: resp}
+}
+
+func (r *ResponseRecorder) SetStatusCode(code int) {
+	r.WriteHeader(code)
+}
+
+func (r *ResponseRecorder) AddHeader(key, value string) {
+	AddHeader(r.Response, key, value)
+}
+
+func (r *ResponseRecorder) WriteHeader(statusCode int) {
+	if r.statusCode == 0 {
+		r.statusCode = statusCode
+	}
+	r.Response.WriteHeader(statusCode)
+}
+
+func (r *ResponseRecorder) Write(body []byte) (int, error) {
+	n, err := r.Response.Write(body)
+	r.size += n
+
+	return n, err
+}
+
+func (r *ResponseRecorder) SetBody(body []byte, statusCode int) int {
+	r.WriteHeader(statusCode)
+	n, _ := r.Write(body)
+
+	return n
+}
+
+func (r *ResponseRecorder) WriteData(req Request, data any) {
+	WriteResponseOf(r.Response)(req, r, data)
+}
+
+func (r *ResponseRecorder) WriteResponseFunc() WriteResponse {
+	return WriteResponseOf(r.Response)
+}
+
+func (r *ResponseRecorder) Unwrap() Response {
+	return r.Response
+}
+
+// StatusCode возвращает записанный статус код или 0, если он еще не записан
+func (r *ResponseRecorder) StatusCode() int {
+	return r.statusCode
+}
+
+// Size возвращает количество записанных байт тела
+func (r *ResponseRecorder) Size() int {
+	return r.size
+}
+
+// ResultStatusCode возвращает статус код, который получит клиент,
+// с учетом ошибки обработчика, записываемой адаптером
+func (r *ResponseRecorder) ResultStatusCode(err error) int {
+	if err != nil {
+		var sc StatusCoder
+		if errors.As(err, &sc) {
+			return sc.StatusCode()
+		}
+
+		return http.StatusInternalServerError
+	}
+
+	if r.statusCode != 0 {
+		return r.statusCode
+	}
+
+	return http.StatusOK
+}
@@ -0,0 +1,385 @@
+package transport
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ErrRouteNotFound возвращается, если маршрут с указанным именем не зарегистрирован
+var ErrRouteNotFound = errors.New("transport: route not found")
+
+// Route описание зарегистрированного маршрута
+type Route struct {
+	Method      string         `json:"method"`
+	Pattern     string         `json:"pattern"`
+	Name        string         `json:"name,omitempty"`
+	Tags        []string       `json:"tags,omitempty"`
+	Metadata    map[string]any `json:"metadata,omitempty"`
+	Middlewares []string       `json:"middlewares,omitempty"`
+
+	middlewares []Middleware
+	options     []Option
+}
+
+// RouteOption опция маршрута, передаваемая в Transport.AddRouteWith.
+// Middleware также является RouteOption и добавляется в цепочку маршрута.
+type RouteOption interface {
+	applyRoute(r *Route)
+}
+
+type routeOptionFunc func(r *Route)
+
+func (f routeOptionFunc) applyRoute(r *Route) {
+	f(r)
+}
+
+func (m Middleware) applyRoute(r *Route) {
+	r.middlewares = append(r.middlewares, m)
+	r.Middlewares = append(r.Middlewares, funcName(m))
+}
+
+// MiddlewareOptions преобразует middleware в опции маршрута для Transport.AddRouteWith
+func MiddlewareOptions(middlewares ...Middleware) []RouteOption {
+	opts := make([]RouteOption, len(middlewares))
+	for i, mw := range middlewares {
+		opts[i] = mw
+	}
+
+	return opts
+}
+
+// WithName задает имя маршрута, по которому можно построить URL
+func WithName(name string) RouteOption {
+	return routeOptionFunc(func(r *Route) {
+		r.Name = name
+	})
+}
+
+// WithTags добавляет теги маршрута
+func WithTags(tags ...string) RouteOption {
+	return routeOptionFunc(func(r *Route) {
+		r.Tags = append(r.Tags, tags...)
+	})
+}
+
+// WithMetadata добавляет произвольные метаданные маршрута
+func WithMetadata(key string, value any) RouteOption {
+	return routeOptionFunc(func(r *Route) {
+		if r.Metadata == nil {
+			r.Metadata = make(map[string]any)
+		}
+		r.Metadata[key] = value
+	})
+}
+
+// NewRoute создает описание маршрута, применяя опции
+func NewRoute(method, path string, opts ...RouteOption) Route {
+	route := Route{
+		Method:  method,
+		Pattern: NormalizePattern(path),
+	}
+	if p, err := ParsePattern(path); err == nil {
+		route.Pattern = p.String()
+	}
+
+	for _, opt := range opts {
+		opt.applyRoute(&route)
+	}
+
+	return route
+}
+
+// Handler возвращает handler, обернутый middleware маршрута; последняя middleware является внешней.
+// Опции транспорта, переданные в AddRouteWith, переопределяют запись ответа, чтение данных
+// и обработку ошибок для всей цепочки маршрута.
+func (r Route) Handler(handler Handler) Handler {
+	for _, mw := range r.middlewares {
+		handler = mw(handler)
+	}
+
+	return NewConfig(r.options...).wrap(handler)
+}
+
+// NotFound возвращает handler, заданный для маршрута опцией WithNotFound, или fallback
+func (r Route) NotFound(fallback Handler) Handler {
+	if notFound := NewConfig(r.options...).notFound; notFound != nil {
+		return notFound
+	}
+
+	return fallback
+}
+
+func (r Route) clone() Route {
+	r.Tags = slices.Clone(r.Tags)
+	r.Metadata = maps.Clone(r.Metadata)
+	r.Middlewares = slices.Clone(r.Middlewares)
+
+	return r
+}
+
+// RouteTable таблица зарегистрированных маршрутов транспорта
+type RouteTable struct {
+	mu      sync.RWMutex
+	routes  []Route
+	names   map[string]int
+	mounts  []mountedTable
+	parents []*RouteTable
+}
+
+type mountedTable struct {
+	prefix string
+	table  *RouteTable
+}
+
+// NewRouteTable создает пустую таблицу маршрутов
+func NewRouteTable() *RouteTable {
+	return &RouteTable{names: make(map[string]int)}
+}
+
+// Add добавляет маршрут в таблицу.
+// Имя маршрута должно быть уникальным среди всех таблиц, связанных через Mount.
+func (t *RouteTable) Add(route Route) error {
+	if route.Name != "" {
+		if err := t.checkNames(route.Name); err != nil {
+			return err
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if route.Name != "" {
+		if _, ok := t.names[route.Name]; ok {
+			return fmt.Errorf("transport: duplicate route name %q", route.Name)
+		}
+		t.names[route.Name] = len(t.routes)
+	}
+	t.routes = append(t.routes, route)
+
+	return nil
+}
+
+// Mount подключает таблицу маршрутов группы или вложенного транспорта под префиксом prefix.
+// Маршруты подключенной таблицы видны в Routes и Lookup с шаблонами, начинающимися с префикса.
+// Возвращает ошибку, если имена маршрутов sub уже заняты.
+func (t *RouteTable) Mount(prefix string, sub *RouteTable) error {
+	if err := t.checkNames(sub.allNames()...); err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	t.mounts = append(t.mounts, mountedTable{prefix: prefix, table: sub})
+	t.mu.Unlock()
+
+	sub.mu.Lock()
+	sub.parents = append(sub.parents, t)
+	sub.mu.Unlock()
+
+	return nil
+}
+
+// checkNames возвращает ошибку, если одно из имен уже занято в таблицах, связанных с t через Mount
+func (t *RouteTable) checkNames(names ...string) error {
+	for _, root := range t.roots() {
+		for _, name := range names {
+			if _, ok := root.Lookup(name); ok {
+				return fmt.Errorf("transport: duplicate route name %q", name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// roots возвращает корневые таблицы, в которые t подключена напрямую или через другие таблицы
+func (t *RouteTable) roots() []*RouteTable {
+	t.mu.RLock()
+	parents := slices.Clone(t.parents)
+	t.mu.RUnlock()
+
+	if len(parents) == 0 {
+		return []*RouteTable{t}
+	}
+
+	var roots []*RouteTable
+	for _, p := range parents {
+		roots = append(roots, p.roots()...)
+	}
+
+	return roots
+}
+
+// allNames возвращает имена маршрутов таблицы и подключенных таблиц
+func (t *RouteTable) allNames() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	names := slices.Collect(maps.Keys(t.names))
+	for _, m := range t.mounts {
+		names = append(names, m.table.allNames()...)
+	}
+
+	return names
+}
+
+// Routes возвращает копию списка маршрутов в порядке регистрации, затем маршруты подключенных таблиц
+func (t *RouteTable) Routes() []Route {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	routes := make([]Route, len(t.routes))
+	for i, r := range t.routes {
+		routes[i] = r.clone()
+	}
+
+	for _, m := range t.mounts {
+		for _, r := range m.table.Routes() {
+			r.Pattern = JoinPattern(m.prefix, r.Pattern)
+			routes = append(routes, r)
+		}
+	}
+
+	return routes
+}
+
+// Lookup возвращает маршрут по имени, в том числе из подключенных таблиц
+func (t *RouteTable) Lookup(name string) (Route, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if i, ok := t.names[name]; ok {
+		return t.routes[i].clone(), true
+	}
+
+	for _, m := range t.mounts {
+		if r, ok := m.table.Lookup(name); ok {
+			r.Pattern = JoinPattern(m.prefix, r.Pattern)
+			return r, true
+		}
+	}
+
+	return Route{}, false
+}
+
+// URL строит путь маршрута с именем name, подставляя и экранируя параметры
+func (t *RouteTable) URL(name string, params map[string]string) (string, error) {
+	route, ok := t.Lookup(name)
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrRouteNotFound, name)
+	}
+
+	return BuildPath(route.Pattern, params)
+}
+
+// RoutesHandler возвращает Handler, отдающий таблицу маршрутов
+func RoutesHandler(table *RouteTable) Handler {
+	return func(req Request, resp Response) error {
+		resp.WriteData(req, table.Routes())
+		return nil
+	}
+}
+
+// NormalizePattern приводит шаблон пути к виду с параметрами в фигурных скобках:
+// "/users/:id" и "/users/{id}" становятся "/users/{id}"
+func NormalizePattern(pattern string) string {
+	segments := strings.Split(pattern, "/")
+	for i, s := range segments {
+		if len(s) > 1 && s[0] == ':' {
+			segments[i] = "{" + s[1:] + "}"
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// BuildPath подставляет параметры в нормализованный шаблон пути.
+// Для шаблона с необязательным завершающим "/" строится путь без него.
+// Значения экранируются как сегменты пути; catch-all параметры ("{path...}" и "*") сохраняют символ "/".
+func BuildPath(pattern string, params map[string]string) (string, error) {
+	var sb strings.Builder
+
+	if p, ok := strings.CutSuffix(pattern, "/?"); ok {
+		pattern = p
+		if pattern == "" {
+			pattern = "/"
+		}
+	}
+
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '{':
+			end := closingBrace(pattern[i:])
+			if end < 0 {
+				return "", fmt.Errorf("transport: unterminated parameter in pattern %q", pattern)
+			}
+			name := pattern[i+1 : i+end]
+			i += end
+
+			if name == "$" {
+				continue
+			}
+
+			name, _, _ = strings.Cut(name, ":")
+			catchAll := strings.HasSuffix(name, "...")
+			name = strings.TrimSuffix(name, "...")
+
+			value, ok := params[name]
+			if !ok {
+				return "", fmt.Errorf("transport: missing parameter %q for pattern %q", name, pattern)
+			}
+			sb.WriteString(escapePathValue(value, catchAll))
+		case '*':
+			sb.WriteString(escapePathValue(params["*"], true))
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	return sb.String(), nil
+}
+
+// closingBrace возвращает индекс скобки, закрывающей параметр в начале s, с учетом вложенных скобок регулярных выражений
+func closingBrace(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+func escapePathValue(value string, catchAll bool) string {
+	if !catchAll {
+		return url.PathEscape(value)
+	}
+
+	segments := strings.Split(value, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+
+	return strings.Join(segments, "/")
+}
+
+func funcName(f any) string {
+	if fn := runtime.FuncForPC(reflect.ValueOf(f).Pointer()); fn != nil {
+		return fn.Name()
+	}
+
+	return ""
+}
@@ -0,0 +1,192 @@
+package transport_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-mosaic/runtime/transport"
+	"github.com/go-mosaic/runtime/transport/internal/transporttest"
+)
+
+func TestNormalizePattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		want    string
+	}{
+		{name: "braces kept", pattern: "/users/{id}", want: "/users/{id}"},
+		{name: "colon params", pattern: "/users/:id/posts/:post", want: "/users/{id}/posts/{post}"},
+		{name: "static", pattern: "/health", want: "/health"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := transport.NormalizePattern(tt.pattern); got != tt.want {
+				t.Errorf("NormalizePattern() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		params  map[string]string
+		want    string
+		wantErr bool
+	}{
+		{name: "simple", pattern: "/users/{id}", params: map[string]string{"id": "42"}, want: "/users/42"},
+		{name: "escaped", pattern: "/users/{id}", params: map[string]string{"id": "a b/c"}, want: "/users/a%20b%2Fc"},
+		{name: "regex constraint", pattern: "/users/{id:[0-9]{2}}", params: map[string]string{"id": "42"}, want: "/users/42"},
+		{name: "catch-all keeps slashes", pattern: "/files/{path...}", params: map[string]string{"path": "a/b c"}, want: "/files/a/b%20c"},
+		{name: "wildcard", pattern: "/static/*", params: map[string]string{"*": "css/app.css"}, want: "/static/css/app.css"},
+		{name: "end anchor", pattern: "/{$}", want: "/"},
+		{name: "optional trailing slash", pattern: "/users/{id}/?", params: map[string]string{"id": "1"}, want: "/users/1"},
+		{name: "missing param", pattern: "/users/{id}", wantErr: true},
+		{name: "unterminated", pattern: "/users/{id", params: map[string]string{"id": "1"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := transport.BuildPath(tt.pattern, tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("BuildPath() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("BuildPath() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRouteTable(t *testing.T) {
+	patterns := map[string]string{
+		"http":       "/users/{id}",
+		"chi":        "/users/{id}",
+		"echo":       "/users/:id",
+		"fiber":      "/users/:id",
+		"gin":        "/users/:id",
+		"fasthttp":   "/users/{id}",
+		"gorilla":    "/users/{id}",
+		"httprouter": "/users/:id",
+	}
+	noop := func(req transport.Request, resp transport.Response) error { return nil }
+	passthrough := transport.Middleware(func(next transport.Handler) transport.Handler { return next })
+
+	for _, adapter := range transporttest.Adapters() {
+		t.Run(adapter.Name, func(t *testing.T) {
+			srv := adapter.New()
+			srv.Transport.AddRouteWith(http.MethodGet, patterns[adapter.Name], noop,
+				transport.WithName("users.get"),
+				transport.WithTags("users"),
+				transport.WithMetadata("owner", "team-a"),
+				passthrough,
+			)
+			srv.Transport.AddRoute(http.MethodGet, "/debug/routes", transport.RoutesHandler(srv.Transport.Routes()))
+
+			got, err := srv.Transport.Routes().URL("users.get", map[string]string{"id": "a b"})
+			if err != nil || got != "/users/a%20b" {
+				t.Errorf("URL() = %v, %v, want /users/a%%20b", got, err)
+			}
+			if _, err := srv.Transport.Routes().URL("unknown", nil); !errors.Is(err, transport.ErrRouteNotFound) {
+				t.Errorf("URL() error = %v, want %v", err, transport.ErrRouteNotFound)
+			}
+
+			r := httptest.NewRequest(http.MethodGet, "/debug/routes", nil)
+			r.Header.Set("Accept", "application/json")
+			resp := srv.Do(t, r)
+			defer resp.Body.Close()
+
+			var routes []transport.Route
+			if err := json.NewDecoder(resp.Body).Decode(&routes); err != nil {
+				t.Fatalf("decode routes: %v", err)
+			}
+			if len(routes) != 2 {
+				t.Fatalf("routes = %v, want 2", routes)
+			}
+			route := routes[0]
+			if route.Pattern != "/users/{id}" || route.Name != "users.get" || route.Tags[0] != "users" ||
+				route.Metadata["owner"] != "team-a" || len(route.Middlewares) != 1 {
+				t.Errorf("route = %+v", route)
+			}
+		})
+	}
+}
+
+func TestRouteTableDuplicateName(t *testing.T) {
+	named := func(path, name string) transport.Route {
+		return transport.NewRoute(http.MethodGet, path, transport.WithName(name))
+	}
+
+	tests := []struct {
+		name  string
+		build func() error
+	}{
+		{
+			name: "same table",
+			build: func() error {
+				table := transport.NewRouteTable()
+				_ = table.Add(named("/a", "a"))
+				return table.Add(named("/b", "a"))
+			},
+		},
+		{
+			name: "mount of table with taken name",
+			build: func() error {
+				table, sub := transport.NewRouteTable(), transport.NewRouteTable()
+				_ = table.Add(named("/a", "a"))
+				_ = sub.Add(named("/b", "a"))
+				return table.Mount("/sub", sub)
+			},
+		},
+		{
+			name: "add to mounted table",
+			build: func() error {
+				table, sub := transport.NewRouteTable(), transport.NewRouteTable()
+				_ = table.Add(named("/a", "a"))
+				_ = table.Mount("/sub", sub)
+				return sub.Add(named("/b", "a"))
+			},
+		},
+		{
+			name: "sibling mounts",
+			build: func() error {
+				table, first, second := transport.NewRouteTable(), transport.NewRouteTable(), transport.NewRouteTable()
+				_ = table.Mount("/first", first)
+				_ = table.Mount("/second", second)
+				_ = first.Add(named("/a", "a"))
+				return second.Add(named("/a", "a"))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.build(); err == nil {
+				t.Errorf("duplicate name error = nil")
+			}
+		})
+	}
+}
+
+func TestMountDuplicateName(t *testing.T) {
+	noop := func(req transport.Request, resp transport.Response) error { return nil }
+
+	for _, adapter := range transporttest.Adapters() {
+		t.Run(adapter.Name, func(t *testing.T) {
+			srv := adapter.New()
+			srv.Transport.AddRouteWith(http.MethodGet, "/a", noop, transport.WithName("a"))
+			sub := adapter.New()
+			sub.Transport.AddRouteWith(http.MethodGet, "/a", noop, transport.WithName("a"))
+
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Mount() with duplicate route name did not panic")
+				}
+			}()
+			srv.Transport.Mount("/sub", sub.Transport)
+		})
+	}
+}
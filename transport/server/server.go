@@ -0,0 +1,317 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+
+	"github.com/go-mosaic/runtime/log"
+	"github.com/go-mosaic/runtime/transport"
+)
+
+// Timeouts таймауты соединений сервера
+type Timeouts struct {
+	Read       time.Duration
+	ReadHeader time.Duration
+	Write      time.Duration
+	Idle       time.Duration
+}
+
+// NativeServer транспорт со встроенным сервером, не реализующий http.Handler, например fiber или fasthttp
+type NativeServer interface {
+	// Serve обслуживает соединения listener до вызова Shutdown, применяя все таймауты timeouts, включая ReadHeader
+	Serve(ln net.Listener, timeouts Timeouts) error
+	// Shutdown завершает сервер, дожидаясь обработки текущих запросов до дедлайна ctx
+	Shutdown(ctx context.Context) error
+}
+
+// Config конфигурация сервера
+type Config struct {
+	network         string
+	addr            string
+	listener        net.Listener
+	certFile        string
+	keyFile         string
+	h2c             bool
+	timeouts        Timeouts
+	shutdownTimeout time.Duration
+	drainDelay      time.Duration
+	signals         []os.Signal
+	logger          log.Logger
+}
+
+// Option тип для функциональных опций
+type Option func(*Config)
+
+// WithAddr задает TCP адрес, по умолчанию ":8080"
+func WithAddr(addr string) Option {
+	return func(c *Config) {
+		c.network = "tcp"
+		c.addr = addr
+	}
+}
+
+// WithUnixSocket задает путь к unix сокету; оставшийся от прошлого запуска файл сокета удаляется
+func WithUnixSocket(path string) Option {
+	return func(c *Config) {
+		c.network = "unix"
+		c.addr = path
+	}
+}
+
+// WithListener задает готовый listener вместо адреса
+func WithListener(ln net.Listener) Option {
+	return func(c *Config) {
+		c.listener = ln
+	}
+}
+
+// WithTLS включает TLS с сертификатом и ключом из файлов
+func WithTLS(certFile, keyFile string) Option {
+	return func(c *Config) {
+		c.certFile = certFile
+		c.keyFile = keyFile
+	}
+}
+
+// WithH2C включает HTTP/2 без TLS (h2c); поддерживается только транспортами, реализующими http.Handler
+func WithH2C(enabled bool) Option {
+	return func(c *Config) {
+		c.h2c = enabled
+	}
+}
+
+// WithReadTimeout задает таймаут чтения запроса
+func WithReadTimeout(d time.Duration) Option {
+	return func(c *Config) {
+		c.timeouts.Read = d
+	}
+}
+
+// WithReadHeaderTimeout задает таймаут чтения заголовков запроса, по умолчанию 10 секунд
+func WithReadHeaderTimeout(d time.Duration) Option {
+	return func(c *Config) {
+		c.timeouts.ReadHeader = d
+	}
+}
+
+// WithWriteTimeout задает таймаут записи ответа
+func WithWriteTimeout(d time.Duration) Option {
+	return func(c *Config) {
+		c.timeouts.Write = d
+	}
+}
+
+// WithIdleTimeout задает время жизни неактивного keep-alive соединения
+func WithIdleTimeout(d time.Duration) Option {
+	return func(c *Config) {
+		c.timeouts.Idle = d
+	}
+}
+
+// WithShutdownTimeout задает время на завершение текущих запросов, по умолчанию 30 секунд.
+// По истечении времени соединения закрываются принудительно.
+func WithShutdownTimeout(d time.Duration) Option {
+	return func(c *Config) {
+		c.shutdownTimeout = d
+	}
+}
+
+// WithDrainDelay задает паузу между снятием готовности и остановкой приема запросов,
+// чтобы балансировщик успел исключить экземпляр
+func WithDrainDelay(d time.Duration) Option {
+	return func(c *Config) {
+		c.drainDelay = d
+	}
+}
+
+// WithSignals задает сигналы остановки, по умолчанию SIGINT и SIGTERM.
+// Без сигналов сервер останавливается только отменой контекста.
+func WithSignals(signals ...os.Signal) Option {
+	return func(c *Config) {
+		c.signals = signals
+	}
+}
+
+// WithLogger задает логгер запуска и остановки
+func WithLogger(logger log.Logger) Option {
+	return func(c *Config) {
+		c.logger = logger
+	}
+}
+
+// Server запускает транспорт и корректно останавливает его по сигналу
+type Server struct {
+	transport transport.Transport
+	config    Config
+	ready     atomic.Bool
+}
+
+// New создает сервер для транспорта t. Транспорт должен реализовывать http.Handler или NativeServer.
+func New(t transport.Transport, opts ...Option) *Server {
+	config := Config{
+		network:         "tcp",
+		addr:            ":8080",
+		timeouts:        Timeouts{ReadHeader: 10 * time.Second},
+		shutdownTimeout: 30 * time.Second,
+		signals:         []os.Signal{os.Interrupt, syscall.SIGTERM},
+	}
+
+	for _, applyOpt := range opts {
+		applyOpt(&config)
+	}
+
+	return &Server{transport: t, config: config}
+}
+
+// Ready сообщает, принимает ли сервер запросы; готовность снимается перед остановкой
+func (s *Server) Ready() bool {
+	return s.ready.Load()
+}
+
+// Run запускает сервер и блокируется до отмены ctx или сигнала остановки.
+// При остановке сервер снимает готовность, ждет DrainDelay и завершает текущие запросы до ShutdownTimeout.
+func (s *Server) Run(ctx context.Context) error {
+	if len(s.config.signals) > 0 {
+		var stop context.CancelFunc
+		ctx, stop = signal.NotifyContext(ctx, s.config.signals...)
+		defer stop()
+	}
+
+	serve, shutdown, err := s.prepare()
+	if err != nil {
+		return err
+	}
+
+	ln, err := s.listen()
+	if err != nil {
+		return err
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- serve(ln)
+	}()
+
+	s.ready.Store(true)
+	s.log("server started", ln.Addr())
+
+	select {
+	case err := <-serveErr:
+		s.ready.Store(false)
+		return err
+	case <-ctx.Done():
+	}
+
+	s.ready.Store(false)
+	s.log("server draining", ln.Addr())
+	time.Sleep(s.config.drainDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.config.shutdownTimeout)
+	defer cancel()
+
+	err = shutdown(shutdownCtx)
+	if serr := <-serveErr; err == nil && serr != nil && !errors.Is(serr, http.ErrServerClosed) {
+		err = serr
+	}
+	s.log("server stopped", ln.Addr())
+
+	return err
+}
+
+// prepare возвращает функции запуска и остановки сервера транспорта
+func (s *Server) prepare() (serve func(net.Listener) error, shutdown func(context.Context) error, err error) {
+	switch t := s.transport.(type) {
+	case http.Handler:
+		srv := s.httpServer(t)
+
+		serve = func(ln net.Listener) error {
+			if s.config.certFile != "" {
+				return srv.ServeTLS(ln, s.config.certFile, s.config.keyFile)
+			}
+			return srv.Serve(ln)
+		}
+		shutdown = func(ctx context.Context) error {
+			err := srv.Shutdown(ctx)
+			if err != nil {
+				// текущие запросы не завершились до дедлайна, соединения закрываются принудительно
+				_ = srv.Close()
+			}
+			return err
+		}
+
+		return serve, shutdown, nil
+	case NativeServer:
+		if s.config.h2c {
+			return nil, nil, fmt.Errorf("server: h2c is not supported by %T", t)
+		}
+
+		var tlsConfig *tls.Config
+		if s.config.certFile != "" {
+			cert, err := tls.LoadX509KeyPair(s.config.certFile, s.config.keyFile)
+			if err != nil {
+				return nil, nil, err
+			}
+			tlsConfig = &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+		}
+
+		serve = func(ln net.Listener) error {
+			if tlsConfig != nil {
+				ln = tls.NewListener(ln, tlsConfig)
+			}
+			return t.Serve(ln, s.config.timeouts)
+		}
+
+		return serve, t.Shutdown, nil
+	default:
+		return nil, nil, fmt.Errorf("server: %T implements neither http.Handler nor server.NativeServer", t)
+	}
+}
+
+func (s *Server) httpServer(h http.Handler) *http.Server {
+	if s.config.h2c {
+		h = h2c.NewHandler(h, &http2.Server{IdleTimeout: s.config.timeouts.Idle})
+	}
+
+	return &http.Server{
+		Handler:           h,
+		ReadTimeout:       s.config.timeouts.Read,
+		ReadHeaderTimeout: s.config.timeouts.ReadHeader,
+		WriteTimeout:      s.config.timeouts.Write,
+		IdleTimeout:       s.config.timeouts.Idle,
+	}
+}
+
+func (s *Server) listen() (net.Listener, error) {
+	if s.config.listener != nil {
+		return s.config.listener, nil
+	}
+
+	if s.config.network == "unix" {
+		if err := os.Remove(s.config.addr); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("server: remove stale socket: %w", err)
+		}
+	}
+
+	return net.Listen(s.config.network, s.config.addr)
+}
+
+func (s *Server) log(msg string, addr net.Addr) {
+	if s.config.logger != nil {
+		s.config.logger.Info(msg, map[string]any{
+			"network": addr.Network(),
+			"addr":    addr.String(),
+		})
+	}
+}
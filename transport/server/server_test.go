@@ -0,0 +1,347 @@
+package server_test
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/net/http2"
+
+	"github.com/go-mosaic/runtime/transport"
+	"github.com/go-mosaic/runtime/transport/internal/transporttest"
+	"github.com/go-mosaic/runtime/transport/server"
+)
+
+func pong(_ transport.Request, resp transport.Response) error {
+	resp.SetBody([]byte("pong"), http.StatusOK)
+	return nil
+}
+
+// start запускает сервер и ждет готовности; возвращаемая функция останавливает сервер и возвращает результат Run
+func start(t *testing.T, srv *server.Server) func() error {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Run(ctx)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !srv.Ready() {
+		select {
+		case err := <-done:
+			t.Fatalf("Run() error = %v", err)
+		default:
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("server is not ready")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	return func() error {
+		cancel()
+		return <-done
+	}
+}
+
+func listen(t *testing.T) net.Listener {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return ln
+}
+
+func get(t *testing.T, c *http.Client, url string) string {
+	t.Helper()
+
+	resp, err := c.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+
+	return string(body)
+}
+
+func TestRun(t *testing.T) {
+	for _, adapter := range transporttest.Adapters() {
+		t.Run(adapter.Name, func(t *testing.T) {
+			tr := adapter.New().Transport
+			tr.AddRoute(http.MethodGet, "/ping", pong)
+
+			ln := listen(t)
+			srv := server.New(tr, server.WithListener(ln), server.WithSignals())
+			stop := start(t, srv)
+
+			if got := get(t, http.DefaultClient, "http://"+ln.Addr().String()+"/ping"); got != "pong" {
+				t.Errorf("GET /ping = %q, want pong", got)
+			}
+
+			if err := stop(); err != nil {
+				t.Errorf("Run() error = %v", err)
+			}
+			if srv.Ready() {
+				t.Error("Ready() = true after shutdown")
+			}
+		})
+	}
+}
+
+func TestGracefulShutdown(t *testing.T) {
+	tests := []struct {
+		name            string
+		shutdownTimeout time.Duration
+		wantErr         error
+	}{
+		{name: "drained", shutdownTimeout: time.Second},
+		{name: "deadline exceeded", shutdownTimeout: 10 * time.Millisecond, wantErr: context.DeadlineExceeded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			started := make(chan struct{})
+			tr := transporttest.Adapters()[0].New().Transport
+			tr.AddRoute(http.MethodGet, "/slow", func(req transport.Request, resp transport.Response) error {
+				close(started)
+				time.Sleep(200 * time.Millisecond)
+				return pong(req, resp)
+			})
+
+			ln := listen(t)
+			srv := server.New(tr,
+				server.WithListener(ln),
+				server.WithSignals(),
+				server.WithDrainDelay(50*time.Millisecond),
+				server.WithShutdownTimeout(tt.shutdownTimeout),
+			)
+			stop := start(t, srv)
+
+			body := make(chan string, 1)
+			go func() {
+				resp, err := http.Get("http://" + ln.Addr().String() + "/slow")
+				if err != nil {
+					body <- err.Error()
+					return
+				}
+				defer resp.Body.Close()
+				b, _ := io.ReadAll(resp.Body)
+				body <- string(b)
+			}()
+			<-started
+
+			stopped := make(chan error, 1)
+			go func() {
+				stopped <- stop()
+			}()
+			time.Sleep(10 * time.Millisecond)
+			if srv.Ready() {
+				t.Error("Ready() = true while draining")
+			}
+
+			if err := <-stopped; !errors.Is(err, tt.wantErr) {
+				t.Errorf("Run() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := <-body; tt.wantErr == nil && got != "pong" {
+				t.Errorf("in-flight request = %q, want pong", got)
+			}
+		})
+	}
+}
+
+func TestUnixSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "server.sock")
+	if err := os.WriteFile(socket, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tr := transporttest.Adapters()[0].New().Transport
+	tr.AddRoute(http.MethodGet, "/ping", pong)
+	stop := start(t, server.New(tr, server.WithUnixSocket(socket), server.WithSignals()))
+	defer func() {
+		if err := stop(); err != nil {
+			t.Errorf("Run() error = %v", err)
+		}
+	}()
+
+	c := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		},
+	}}
+	if got := get(t, c, "http://unix/ping"); got != "pong" {
+		t.Errorf("GET /ping = %q, want pong", got)
+	}
+}
+
+func TestH2C(t *testing.T) {
+	tr := transporttest.Adapters()[0].New().Transport
+	tr.AddRoute(http.MethodGet, "/ping", pong)
+
+	ln := listen(t)
+	stop := start(t, server.New(tr, server.WithListener(ln), server.WithH2C(true), server.WithSignals()))
+	defer func() {
+		if err := stop(); err != nil {
+			t.Errorf("Run() error = %v", err)
+		}
+	}()
+
+	c := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, addr)
+		},
+	}}
+	resp, err := c.Get("http://" + ln.Addr().String() + "/ping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.ProtoMajor != 2 {
+		t.Errorf("protocol = HTTP/%d, want HTTP/2", resp.ProtoMajor)
+	}
+}
+
+func TestTLS(t *testing.T) {
+	certFile, keyFile, pool := writeCertificate(t)
+
+	for _, adapter := range transporttest.Adapters() {
+		if adapter.Name != "http" && adapter.Name != "fasthttp" {
+			continue
+		}
+
+		t.Run(adapter.Name, func(t *testing.T) {
+			tr := adapter.New().Transport
+			tr.AddRoute(http.MethodGet, "/ping", pong)
+
+			ln := listen(t)
+			stop := start(t, server.New(tr, server.WithListener(ln), server.WithTLS(certFile, keyFile), server.WithSignals()))
+			defer func() {
+				if err := stop(); err != nil {
+					t.Errorf("Run() error = %v", err)
+				}
+			}()
+
+			c := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+			if got := get(t, c, "https://"+ln.Addr().String()+"/ping"); got != "pong" {
+				t.Errorf("GET /ping = %q, want pong", got)
+			}
+		})
+	}
+}
+
+// writeCertificate создает самоподписанный сертификат для 127.0.0.1
+func writeCertificate(t *testing.T) (certFile, keyFile string, pool *x509.CertPool) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool = x509.NewCertPool()
+	pool.AddCert(cert)
+
+	return certFile, keyFile, pool
+}
+
+func TestReadHeaderTimeout(t *testing.T) {
+	const timeout = 100 * time.Millisecond
+
+	tests := []struct {
+		name   string
+		head   string
+		rest   string
+		wantOK bool
+	}{
+		{name: "slow header", head: "GET /ping HTTP/1.1\r\nHost: test\r\n", rest: "\r\n"},
+		{name: "slow body", head: "POST /ping HTTP/1.1\r\nHost: test\r\nContent-Length: 4\r\n\r\n", rest: "ping", wantOK: true},
+	}
+	for _, adapter := range transporttest.Adapters() {
+		for _, tt := range tests {
+			t.Run(adapter.Name+"/"+tt.name, func(t *testing.T) {
+				tr := adapter.New().Transport
+				tr.AddRoute(http.MethodGet, "/ping", pong)
+				tr.AddRoute(http.MethodPost, "/ping", pong)
+
+				ln := listen(t)
+				stop := start(t, server.New(tr, server.WithListener(ln), server.WithSignals(), server.WithReadHeaderTimeout(timeout)))
+				defer stop()
+
+				conn, err := net.Dial("tcp", ln.Addr().String())
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer conn.Close()
+
+				_, _ = io.WriteString(conn, tt.head)
+				time.Sleep(3 * timeout)
+				_, _ = io.WriteString(conn, tt.rest)
+
+				_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+				resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+				ok := err == nil && resp.StatusCode == http.StatusOK
+				if resp != nil {
+					resp.Body.Close()
+				}
+				if ok != tt.wantOK {
+					t.Errorf("response = %v, %v, want ok %v", resp, err, tt.wantOK)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package timeout
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fasthttp/router"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
+
+	"github.com/go-mosaic/runtime/transport"
+	transportfasthttp "github.com/go-mosaic/runtime/transport/fasthttp"
+)
+
+func TestFastHTTPDetachedRequest(t *testing.T) {
+	const requests = 20
+
+	finished := make(chan string, requests)
+	handler := func(req transport.Request, resp transport.Response) error {
+		<-req.Context().Done()
+		// обработчик продолжает читать запрос после того, как middleware вернула ответ
+		time.Sleep(5 * time.Millisecond)
+
+		body, _ := io.ReadAll(req.Body())
+		finished <- fmt.Sprintf("%s %s %s %s", req.Method(), req.PathValue("id"), req.Header("X-Request"), body)
+
+		return nil
+	}
+
+	tr := transportfasthttp.NewFastHTTPTransport(router.New())
+	tr.AddRoute(http.MethodPost, "/items/{id}", handler, Middleware(time.Millisecond))
+
+	ln := fasthttputil.NewInmemoryListener()
+	server := &fasthttp.Server{Handler: tr.Handler}
+	go func() { _ = server.Serve(ln) }()
+	defer func() { _ = server.Shutdown() }()
+
+	// одно соединение keep-alive: fasthttp.Server переиспользует RequestCtx между запросами
+	client := &fasthttp.Client{
+		MaxConnsPerHost: 1,
+		Dial:            func(string) (net.Conn, error) { return ln.Dial() },
+	}
+
+	want := make(map[string]bool, requests)
+	for i := range requests {
+		id := strconv.Itoa(i)
+		want[strings.Join([]string{http.MethodPost, id, "req-" + id, "body-" + id}, " ")] = true
+
+		req := fasthttp.AcquireRequest()
+		resp := fasthttp.AcquireResponse()
+		req.Header.SetMethod(http.MethodPost)
+		req.SetRequestURI("http://test/items/" + id)
+		req.Header.Set("X-Request", "req-"+id)
+		req.SetBodyString("body-" + id)
+
+		if err := client.Do(req, resp); err != nil {
+			t.Fatalf("Do() error = %v", err)
+		}
+		if resp.StatusCode() != http.StatusServiceUnavailable {
+			t.Errorf("status = %d, want %d", resp.StatusCode(), http.StatusServiceUnavailable)
+		}
+
+		fasthttp.ReleaseRequest(req)
+		fasthttp.ReleaseResponse(resp)
+	}
+
+	for range requests {
+		select {
+		case got := <-finished:
+			if !want[got] {
+				t.Errorf("handler read %q from a reused request", got)
+			}
+			delete(want, got)
+		case <-time.After(time.Second):
+			t.Fatal("handler did not finish")
+		}
+	}
+}
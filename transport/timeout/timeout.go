@@ -0,0 +1,186 @@
+package timeout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-mosaic/runtime/transport"
+)
+
+const (
+	// HeaderRequestTimeout заголовок с желаемым таймаутом запроса в секундах
+	HeaderRequestTimeout = "Request-Timeout"
+	// HeaderGRPCTimeout заголовок таймаута в формате gRPC, например "500m" или "2S"
+	HeaderGRPCTimeout = "Grpc-Timeout"
+
+	maxGRPCTimeoutDigits = 8
+)
+
+// Config конфигурация middleware
+type Config struct {
+	maxTimeout    time.Duration
+	honourHeaders bool
+	statusCode    int
+}
+
+// Option тип для функциональных опций
+type Option func(*Config)
+
+// WithMaxTimeout задает верхнюю границу для таймаута, запрошенного клиентом
+func WithMaxTimeout(d time.Duration) Option {
+	return func(c *Config) {
+		c.maxTimeout = d
+	}
+}
+
+// WithHeaders включает/выключает учет заголовков Request-Timeout и Grpc-Timeout
+func WithHeaders(enabled bool) Option {
+	return func(c *Config) {
+		c.honourHeaders = enabled
+	}
+}
+
+// WithStatusCode задает статус код ответа при истечении таймаута, по умолчанию 503
+func WithStatusCode(code int) Option {
+	return func(c *Config) {
+		c.statusCode = code
+	}
+}
+
+// Middleware ограничивает время выполнения обработчика.
+// Обработчик получает контекст с дедлайном и пишет ответ в буфер; если дедлайн истек раньше,
+// буфер отбрасывается и клиент получает problem ответ. Обработчик должен учитывать отмену контекста:
+// после истечения таймаута он продолжает выполняться в отдельной горутине.
+// Поэтому обработчик получает запрос, отделенный от объектов адаптера через transport.Detach,
+// а после истечения таймаута ответ отправляется через transport.Abandon, чтобы адаптеры,
+// переиспользующие объекты запроса, не передали их следующему запросу.
+func Middleware(timeout time.Duration, opts ...Option) transport.Middleware {
+	config := Config{
+		maxTimeout:    timeout,
+		honourHeaders: true,
+		statusCode:    http.StatusServiceUnavailable,
+	}
+
+	for _, applyOpt := range opts {
+		applyOpt(&config)
+	}
+
+	return func(next transport.Handler) transport.Handler {
+		return func(req transport.Request, resp transport.Response) error {
+			d := timeout
+			if config.honourHeaders {
+				if requested, ok := requestedTimeout(req, config.maxTimeout); ok {
+					d = requested
+				}
+			}
+
+			detached := transport.Detach(req)
+			ctx, cancel := context.WithTimeout(detached.Context(), d)
+			defer cancel()
+
+			buf := transport.NewResponseBuffer(transport.WriteResponseOf(resp))
+			done := make(chan error, 1)
+			panicked := make(chan any, 1)
+
+			go func() {
+				defer func() {
+					if p := recover(); p != nil {
+						panicked <- p
+					}
+				}()
+
+				done <- next(detached.WithContext(ctx), buf)
+			}()
+
+			select {
+			case err := <-done:
+				if buf.Written() {
+					buf.FlushTo(resp)
+				}
+
+				return err
+			case p := <-panicked:
+				panic(p)
+			case <-ctx.Done():
+				buf.Discard()
+
+				if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+					transport.Abandon(resp)
+					return ctx.Err()
+				}
+
+				problem := transport.NewProblem(config.statusCode, fmt.Sprintf("request timed out after %s", d))
+				resp.WriteData(req, problem)
+				transport.Abandon(resp)
+
+				return nil
+			}
+		}
+	}
+}
+
+// requestedTimeout возвращает таймаут, запрошенный клиентом через заголовки, не больше maxTimeout
+func requestedTimeout(req transport.Request, maxTimeout time.Duration) (time.Duration, bool) {
+	if v := req.Header(HeaderGRPCTimeout); v != "" {
+		if d, err := ParseGRPCTimeout(v); err == nil {
+			return min(d, maxTimeout), true
+		}
+	}
+
+	if v := req.Header(HeaderRequestTimeout); v != "" {
+		// ParseFloat принимает Inf и NaN, а секунды сверх maxTimeout переполнили бы time.Duration
+		if seconds, err := strconv.ParseFloat(v, 64); err == nil {
+			if seconds > 0 && !math.IsInf(seconds, 0) {
+				if seconds >= maxTimeout.Seconds() {
+					return maxTimeout, true
+				}
+
+				return time.Duration(seconds * float64(time.Second)), true
+			}
+
+			return 0, false
+		}
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			return min(d, maxTimeout), true
+		}
+	}
+
+	return 0, false
+}
+
+// ParseGRPCTimeout разбирает значение заголовка grpc-timeout
+func ParseGRPCTimeout(s string) (time.Duration, error) {
+	if len(s) < 2 || len(s) > maxGRPCTimeoutDigits+1 {
+		return 0, fmt.Errorf("invalid grpc-timeout %q", s)
+	}
+
+	value, err := strconv.ParseInt(s[:len(s)-1], 10, 64)
+	if err != nil || value <= 0 {
+		return 0, fmt.Errorf("invalid grpc-timeout %q", s)
+	}
+
+	var unit time.Duration
+	switch s[len(s)-1] {
+	case 'H':
+		unit = time.Hour
+	case 'M':
+		unit = time.Minute
+	case 'S':
+		unit = time.Second
+	case 'm':
+		unit = time.Millisecond
+	case 'u':
+		unit = time.Microsecond
+	case 'n':
+		unit = time.Nanosecond
+	default:
+		return 0, fmt.Errorf("invalid grpc-timeout unit %q", s)
+	}
+
+	return time.Duration(value) * unit, nil
+}
@@ -0,0 +1,124 @@
+package timeout
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-mosaic/runtime/transport"
+	"github.com/go-mosaic/runtime/transport/internal/transporttest"
+)
+
+func TestParseGRPCTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "milliseconds", s: "250m", want: 250 * time.Millisecond},
+		{name: "seconds", s: "2S", want: 2 * time.Second},
+		{name: "hours", s: "1H", want: time.Hour},
+		{name: "unknown unit", s: "10x", wantErr: true},
+		{name: "too many digits", s: "123456789m", wantErr: true},
+		{name: "no value", s: "m", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseGRPCTimeout(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseGRPCTimeout() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseGRPCTimeout() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	handler := func(req transport.Request, resp transport.Response) error {
+		select {
+		case <-time.After(200 * time.Millisecond):
+			resp.SetHeader("X-Late", "1")
+			resp.SetBody([]byte("late"), http.StatusOK)
+		case <-req.Context().Done():
+		}
+
+		return nil
+	}
+
+	tests := []struct {
+		name       string
+		opts       []Option
+		header     map[string]string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "deadline hits", wantStatus: http.StatusServiceUnavailable},
+		{name: "custom status", opts: []Option{WithStatusCode(http.StatusGatewayTimeout)}, wantStatus: http.StatusGatewayTimeout},
+		{name: "client asks for more, capped", header: map[string]string{HeaderRequestTimeout: "10"}, wantStatus: http.StatusServiceUnavailable},
+		{
+			name:       "client asks for less",
+			opts:       []Option{WithMaxTimeout(time.Second)},
+			header:     map[string]string{HeaderGRPCTimeout: "10m"},
+			wantStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:       "client extends up to max",
+			opts:       []Option{WithMaxTimeout(time.Second)},
+			header:     map[string]string{HeaderRequestTimeout: "0.5"},
+			wantStatus: http.StatusOK,
+			wantBody:   "late",
+		},
+		{
+			name:       "huge value capped",
+			opts:       []Option{WithMaxTimeout(time.Second)},
+			header:     map[string]string{HeaderRequestTimeout: "1e300"},
+			wantStatus: http.StatusOK,
+			wantBody:   "late",
+		},
+		{
+			name:       "infinity ignored",
+			opts:       []Option{WithMaxTimeout(time.Second)},
+			header:     map[string]string{HeaderRequestTimeout: "+Inf"},
+			wantStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:       "headers ignored",
+			opts:       []Option{WithMaxTimeout(time.Second), WithHeaders(false)},
+			header:     map[string]string{HeaderRequestTimeout: "0.5"},
+			wantStatus: http.StatusServiceUnavailable,
+		},
+	}
+	for _, adapter := range transporttest.Adapters() {
+		for _, tt := range tests {
+			t.Run(adapter.Name+"/"+tt.name, func(t *testing.T) {
+				srv := adapter.New()
+				srv.Transport.AddRoute(http.MethodGet, "/slow", handler, Middleware(50*time.Millisecond, tt.opts...))
+
+				r := httptest.NewRequest(http.MethodGet, "/slow", nil)
+				for k, v := range tt.header {
+					r.Header.Set(k, v)
+				}
+
+				resp := srv.Do(t, r)
+				defer resp.Body.Close()
+
+				if resp.StatusCode != tt.wantStatus {
+					t.Errorf("status = %v, want %v", resp.StatusCode, tt.wantStatus)
+				}
+				body, _ := io.ReadAll(resp.Body)
+				if tt.wantBody != "" && string(body) != tt.wantBody {
+					t.Errorf("body = %q, want %q", body, tt.wantBody)
+				}
+				if tt.wantStatus != http.StatusOK && resp.Header.Get("X-Late") != "" {
+					t.Errorf("late write leaked into response")
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package tracing
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/go-mosaic/runtime/transport"
+)
+
+// ScopeName имя инструментации, под которым создаются span
+const ScopeName = "github.com/go-mosaic/runtime/transport/tracing"
+
+// Config конфигурация middleware
+type Config struct {
+	tracerProvider trace.TracerProvider
+	propagators    propagation.TextMapPropagator
+	inject         bool
+}
+
+// Option тип для функциональных опций
+type Option func(*Config)
+
+// WithTracerProvider задает TracerProvider, по умолчанию otel.GetTracerProvider()
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *Config) {
+		c.tracerProvider = tp
+	}
+}
+
+// WithPropagators задает пропагаторы контекста, по умолчанию W3C Trace Context и Baggage
+func WithPropagators(propagators propagation.TextMapPropagator) Option {
+	return func(c *Config) {
+		c.propagators = propagators
+	}
+}
+
+// WithResponseInjection включает/выключает запись контекста трассировки в заголовки ответа
+func WithResponseInjection(enabled bool) Option {
+	return func(c *Config) {
+		c.inject = enabled
+	}
+}
+
+// Middleware создает серверный span для каждого запроса.
+// Контекст трассировки извлекается из заголовков запроса, span доступен обработчику через req.Context().
+func Middleware(opts ...Option) transport.Middleware {
+	config := Config{
+		tracerProvider: otel.GetTracerProvider(),
+		propagators:    propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}),
+		inject:         true,
+	}
+
+	for _, applyOpt := range opts {
+		applyOpt(&config)
+	}
+
+	tracer := config.tracerProvider.Tracer(ScopeName)
+
+	return func(next transport.Handler) transport.Handler {
+		return func(req transport.Request, resp transport.Response) error {
+			ctx := config.propagators.Extract(req.Context(), requestCarrier{req: req})
+
+			ctx, span := tracer.Start(ctx, spanName(req.Method(), transport.PatternOf(req)),
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					semconv.HTTPRequestMethodKey.String(req.Method()),
+					semconv.URLPath(req.Path()),
+					semconv.UserAgentOriginal(req.Header("User-Agent")),
+					semconv.ClientAddress(transport.RemoteIP(req)),
+				),
+			)
+			defer span.End()
+
+			if config.inject {
+				config.propagators.Inject(ctx, responseCarrier{resp: resp})
+			}
+
+			rec := transport.NewResponseRecorder(resp)
+			err := next(req.WithContext(ctx), rec)
+
+			// Шаблон маршрута может стать известен только после маршрутизации
+			if route := transport.PatternOf(req); route != "" {
+				span.SetName(spanName(req.Method(), route))
+				span.SetAttributes(semconv.HTTPRoute(route))
+			}
+
+			statusCode := rec.ResultStatusCode(err)
+			span.SetAttributes(
+				semconv.HTTPResponseStatusCode(statusCode),
+				semconv.HTTPResponseBodySize(rec.Size()),
+			)
+
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			} else if statusCode >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(statusCode))
+			}
+
+			return err
+		}
+	}
+}
+
+func spanName(method, route string) string {
+	if route == "" {
+		return method
+	}
+
+	return method + " " + route
+}
+
+// requestCarrier адаптер заголовков запроса для propagation.TextMapCarrier
+type requestCarrier struct {
+	req transport.Request
+}
+
+func (c requestCarrier) Get(key string) string {
+	return c.req.Header(key)
+}
+
+func (c requestCarrier) Set(string, string) {}
+
+func (c requestCarrier) Keys() []string {
+	return nil
+}
+
+// responseCarrier адаптер заголовков ответа для propagation.TextMapCarrier
+type responseCarrier struct {
+	resp transport.Response
+}
+
+func (c responseCarrier) Get(string) string {
+	return ""
+}
+
+func (c responseCarrier) Set(key, value string) {
+	c.resp.SetHeader(key, value)
+}
+
+func (c responseCarrier) Keys() []string {
+	return nil
+}
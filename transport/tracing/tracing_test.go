@@ -0,0 +1,124 @@
+package tracing
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/go-mosaic/runtime/transport"
+	"github.com/go-mosaic/runtime/transport/internal/transporttest"
+)
+
+const (
+	parentTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	parentSpanID  = "00f067aa0ba902b7"
+)
+
+func TestMiddleware(t *testing.T) {
+	patterns := map[string]string{
+		"http":       "/orders/{id}",
+		"chi":        "/orders/{id}",
+		"echo":       "/orders/:id",
+		"fiber":      "/orders/:id",
+		"gin":        "/orders/:id",
+		"fasthttp":   "/orders/{id}",
+		"gorilla":    "/orders/{id}",
+		"httprouter": "/orders/:id",
+	}
+
+	tests := []struct {
+		name       string
+		id         string
+		wantStatus int
+		wantCode   codes.Code
+	}{
+		{name: "success", id: "1", wantStatus: http.StatusOK, wantCode: codes.Unset},
+		{name: "client error", id: "missing", wantStatus: http.StatusNotFound, wantCode: codes.Unset},
+		{name: "returned error", id: "fail", wantStatus: http.StatusInternalServerError, wantCode: codes.Error},
+	}
+	for _, adapter := range transporttest.Adapters() {
+		for _, tt := range tests {
+			t.Run(adapter.Name+"/"+tt.name, func(t *testing.T) {
+				exporter := tracetest.NewInMemoryExporter()
+				tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+				var (
+					handlerSpan trace.SpanContext
+					member      string
+				)
+				srv := adapter.New()
+				srv.Transport.AddRoute(http.MethodGet, patterns[adapter.Name], func(req transport.Request, resp transport.Response) error {
+					handlerSpan = trace.SpanContextFromContext(req.Context())
+					member = baggage.FromContext(req.Context()).Member("tenant").Value()
+					switch req.PathValue("id") {
+					case "fail":
+						return errors.New("boom")
+					case "missing":
+						resp.SetBody(nil, http.StatusNotFound)
+						return nil
+					}
+					resp.SetBody([]byte("ok"), http.StatusOK)
+					return nil
+				}, Middleware(WithTracerProvider(tp)))
+
+				r := httptest.NewRequest(http.MethodGet, "/orders/"+tt.id, nil)
+				r.Header.Set("traceparent", "00-"+parentTraceID+"-"+parentSpanID+"-01")
+				r.Header.Set("baggage", "tenant=acme")
+				resp := srv.Do(t, r)
+				resp.Body.Close()
+
+				spans := exporter.GetSpans()
+				if len(spans) != 1 {
+					t.Fatalf("spans = %d, want 1", len(spans))
+				}
+				span := spans[0]
+
+				if got := span.SpanContext.TraceID().String(); got != parentTraceID {
+					t.Errorf("trace id = %v, want %v", got, parentTraceID)
+				}
+				if got := span.Parent.SpanID().String(); got != parentSpanID {
+					t.Errorf("parent span id = %v, want %v", got, parentSpanID)
+				}
+				if handlerSpan.SpanID() != span.SpanContext.SpanID() {
+					t.Errorf("handler context does not carry the server span")
+				}
+				if member != "acme" {
+					t.Errorf("baggage member = %q, want acme", member)
+				}
+				if wantName := "GET " + patterns[adapter.Name]; span.Name != wantName {
+					t.Errorf("span name = %q, want %q", span.Name, wantName)
+				}
+				if span.SpanKind != trace.SpanKindServer {
+					t.Errorf("span kind = %v, want server", span.SpanKind)
+				}
+				if span.Status.Code != tt.wantCode {
+					t.Errorf("status code = %v, want %v", span.Status.Code, tt.wantCode)
+				}
+
+				attrs := attribute.NewSet(span.Attributes...)
+				if v, _ := attrs.Value("http.response.status_code"); v.AsInt64() != int64(tt.wantStatus) {
+					t.Errorf("http.response.status_code = %v, want %v", v.AsInt64(), tt.wantStatus)
+				}
+				if v, _ := attrs.Value("http.route"); v.AsString() != patterns[adapter.Name] {
+					t.Errorf("http.route = %q, want %q", v.AsString(), patterns[adapter.Name])
+				}
+				if v, _ := attrs.Value("http.request.method"); v.AsString() != http.MethodGet {
+					t.Errorf("http.request.method = %q, want GET", v.AsString())
+				}
+
+				wantParent := "00-" + parentTraceID + "-" + span.SpanContext.SpanID().String() + "-01"
+				if got := resp.Header.Get("traceparent"); got != wantParent {
+					t.Errorf("response traceparent = %q, want %q", got, wantParent)
+				}
+			})
+		}
+	}
+}
@@ -14,6 +14,9 @@ import (
 	"github.com/aohorodnyk/mimeheader"
 )
 
+// HeaderIdempotencyKey заголовок с ключом идемпотентности запроса, общий для сервера и клиента
+const HeaderIdempotencyKey = "Idempotency-Key"
+
 type SameSite int
 
 const (
@@ -44,7 +47,14 @@ type Form interface {
 // Transport интерфейс для HTTP транспорта
 type Transport interface {
 	AddRoute(method, path string, handler Handler, middlewares ...Middleware)
+	// AddRouteWith регистрирует маршрут с опциями: именем, тегами, метаданными, middleware и опциями транспорта
+	AddRouteWith(method, path string, handler Handler, opts ...RouteOption)
 	Use(middlewares ...Middleware)
+	Routes() *RouteTable
+	// Group возвращает транспорт, регистрирующий маршруты под префиксом prefix с дополнительными middleware
+	Group(prefix string, middlewares ...Middleware) Transport
+	// Mount подключает маршруты другого транспорта под префиксом prefix
+	Mount(prefix string, sub Transport)
 }
 
 // Request универсальный интерфейс для HTTP-запроса
@@ -172,11 +182,15 @@ func determineStatusCode(data any) int {
 	return http.StatusOK
 }
 
-// setHeaders устанавливает заголовки ответа
+// setHeaders устанавливает заголовки ответа, сохраняя все значения каждого заголовка
 func setHeaders(resp Response, headers map[string][]string) {
 	for k, values := range headers {
-		for _, v := range values {
-			resp.SetHeader(k, v)
+		for i, v := range values {
+			if i == 0 {
+				resp.SetHeader(k, v)
+			} else {
+				AddHeader(resp, k, v)
+			}
 		}
 	}
 }